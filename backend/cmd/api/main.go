@@ -30,17 +30,23 @@ func main() {
 	}
 	log.Println("Database connected successfully")
 
-	// Run migrations
-	if err := database.Migrate(); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Run migrations, can be turned off when they are applied with cmd/migrate instead
+	if cfg.Database.MigrateOnStartup {
+		if err := database.Migrate(); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		log.Println("Database migrations completed")
 	}
-	log.Println("Database migrations completed")
 
 	// Run all seeders
-	database.SeedAll()
+	if cfg.Database.SeedOnStartup {
+		database.SeedAll()
+	}
 
 	// Seed dummy foods for varel@gmail.com
-	database.SeedDummyFoodsForVarel()
+	if cfg.Database.SeedDummyFoods {
+		database.SeedDummyFoodsForVarel()
+	}
 
 	// Set Gin mode based on environment
 	if cfg.Server.Env == "production" {
@@ -54,9 +60,14 @@ func main() {
 
 	// Setup routes with all dependencies
 	db := database.GetDB()
-	routes.SetupRoutes(router, db, cfg)
+	background := routes.SetupRoutes(router, db, cfg)
 	log.Println("Routes configured successfully")
 
+	// Start background jobs
+	if cfg.Scheduler.Enabled {
+		background.Scheduler.Start()
+	}
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -81,6 +92,14 @@ func main() {
 
 	log.Println("🛑 Shutting down server...")
 
+	// Stop background jobs before closing the server
+	if cfg.Scheduler.Enabled {
+		background.Scheduler.Stop()
+	}
+
+	// End open event streams, otherwise Shutdown waits for them until the timeout
+	background.EventHub.Close()
+
 	// Graceful shutdown with 5-second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
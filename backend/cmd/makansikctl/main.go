@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/database"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const usage = `Usage: makansikctl <command> [flags]
+
+Commands:
+  seed                      run seeders (--only=markets,supermarkets,vouchers)
+  seed-user                 load demo pantry data for a user (--email)
+  set-role                  change a user's role (--email, --role=admin|user)
+  recompute-points          rebuild point balances from transactions (--dry-run)
+  expire-redemptions        mark voucher redemptions past their expiry as expired
+  expire-points             expire earned points past their expiry date
+  prune-notification-reads  delete old notification read markers (--older-than=720h)
+  import-recipes            create or update recipes from a JSON file (--file)
+  jobs                      show background job run history (--name, --limit)
+
+Run "makansikctl <command> -h" for the flags of a command.
+`
+
+type command func(app *app, args []string) error
+
+// app connects to the database on first use, so "-h" works without one
+type app struct {
+	cfg *config.Config
+	db  *gorm.DB
+}
+
+func (a *app) DB() *gorm.DB {
+	if a.db == nil {
+		if err := database.Connect(&a.cfg.Database); err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		// Keep the output readable, GORM logs every query at info level
+		a.db = database.GetDB().Session(&gorm.Session{Logger: database.GetDB().Logger.LogMode(logger.Warn)})
+		database.DB = a.db
+	}
+	return a.db
+}
+
+var commands = map[string]command{
+	"seed":                     runSeed,
+	"seed-user":                runSeedUser,
+	"set-role":                 runSetRole,
+	"recompute-points":         runRecomputePoints,
+	"expire-redemptions":       runExpireRedemptions,
+	"expire-points":            runExpirePoints,
+	"prune-notification-reads": runPruneNotificationReads,
+	"import-recipes":           runImportRecipes,
+	"jobs":                     runJobs,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := run(&app{cfg: cfg}, os.Args[2:]); err != nil {
+		log.Fatalf("%s failed: %v", os.Args[1], err)
+	}
+}
+
+func runSeed(app *app, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	only := flags.String("only", strings.Join(database.SeederNames, ","), "comma-separated seeders to run")
+	flags.Parse(args)
+
+	var names []string
+	for _, name := range strings.Split(*only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	app.DB() // the seeders use database.DB
+	if err := database.SeedOnly(names); err != nil {
+		return err
+	}
+	fmt.Printf("Seeded %s\n", strings.Join(names, ", "))
+	return nil
+}
+
+func runSeedUser(app *app, args []string) error {
+	flags := flag.NewFlagSet("seed-user", flag.ExitOnError)
+	email := flags.String("email", "", "email of the user to seed")
+	flags.Parse(args)
+
+	if *email == "" {
+		return fmt.Errorf("--email is required")
+	}
+
+	user, err := repository.NewUserRepository(app.DB()).FindByEmail(*email)
+	if err != nil {
+		return fmt.Errorf("user %s not found", *email)
+	}
+
+	rewardService := service.NewRewardService(repository.NewRewardRepository(app.DB()), repository.NewPointRuleRepository(app.DB()), &app.cfg.Reward)
+	foodService := service.NewFoodService(repository.NewFoodRepository(app.DB()), rewardService, nil, nil, nil, nil)
+	if err := foodService.SeedDummyFoodsForUser(user.ID); err != nil {
+		return err
+	}
+	fmt.Printf("Seeded demo foods for %s (%s)\n", user.Email, user.ID)
+	return nil
+}
+
+func runSetRole(app *app, args []string) error {
+	flags := flag.NewFlagSet("set-role", flag.ExitOnError)
+	email := flags.String("email", "", "email of the user")
+	role := flags.String("role", models.RoleAdmin, "role to give the user (admin or user)")
+	flags.Parse(args)
+
+	if *email == "" {
+		return fmt.Errorf("--email is required")
+	}
+	if *role != models.RoleAdmin && *role != models.RoleUser {
+		return fmt.Errorf("invalid role %q", *role)
+	}
+
+	userRepo := repository.NewUserRepository(app.DB())
+	user, err := userRepo.FindByEmail(*email)
+	if err != nil {
+		return fmt.Errorf("user %s not found", *email)
+	}
+
+	user.Role = *role
+	if err := userRepo.Update(user); err != nil {
+		return err
+	}
+	fmt.Printf("%s is now %s\n", user.Email, user.Role)
+	return nil
+}
+
+func runRecomputePoints(app *app, args []string) error {
+	flags := flag.NewFlagSet("recompute-points", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only report balances that don't match")
+	flags.Parse(args)
+
+	rewardService := service.NewRewardService(repository.NewRewardRepository(app.DB()), repository.NewPointRuleRepository(app.DB()), &app.cfg.Reward)
+	corrections, err := rewardService.RecomputePoints(*dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range corrections {
+		fmt.Printf("%s  available %d -> %d  total %d -> %d  used %d -> %d\n",
+			c.UserID,
+			c.Before.AvailablePoints, c.After.AvailablePoints,
+			c.Before.TotalPoints, c.After.TotalPoints,
+			c.Before.UsedPoints, c.After.UsedPoints)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d balances need repair (dry run, nothing changed)\n", len(corrections))
+	} else {
+		fmt.Printf("Repaired %d balances\n", len(corrections))
+	}
+	return nil
+}
+
+func runExpireRedemptions(app *app, args []string) error {
+	flags := flag.NewFlagSet("expire-redemptions", flag.ExitOnError)
+	flags.Parse(args)
+
+	voucherService := service.NewVoucherService(repository.NewVoucherRepository(app.DB()), repository.NewRewardRepository(app.DB()))
+	expired, err := voucherService.ExpireStaleRedemptions(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Expired %d redemptions\n", expired)
+	return nil
+}
+
+func runExpirePoints(app *app, args []string) error {
+	flags := flag.NewFlagSet("expire-points", flag.ExitOnError)
+	flags.Parse(args)
+
+	rewardService := service.NewRewardService(repository.NewRewardRepository(app.DB()), repository.NewPointRuleRepository(app.DB()), &app.cfg.Reward)
+	lots, points, err := rewardService.ExpirePoints(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Expired %d points from %d lots\n", points, lots)
+	return nil
+}
+
+func runPruneNotificationReads(app *app, args []string) error {
+	flags := flag.NewFlagSet("prune-notification-reads", flag.ExitOnError)
+	olderThan := flags.Duration("older-than", 30*24*time.Hour, "delete read markers older than this")
+	flags.Parse(args)
+
+	deleted, err := repository.NewNotificationReadRepository(app.DB()).DeleteOldReads(context.Background(), time.Now().Add(-*olderThan))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d notification read markers\n", deleted)
+	return nil
+}
+
+func runImportRecipes(app *app, args []string) error {
+	flags := flag.NewFlagSet("import-recipes", flag.ExitOnError)
+	file := flags.String("file", "", "JSON file with an array of recipes")
+	flags.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	var recipes []service.ImportRecipeRequest
+	if err := json.Unmarshal(content, &recipes); err != nil {
+		return fmt.Errorf("invalid recipe file: %w", err)
+	}
+
+	recipeService := service.NewRecipeService(repository.NewRecipeRepository(app.DB()), repository.NewFoodRepository(app.DB()), repository.NewUserRepository(app.DB()), nil, nil, app.cfg)
+	result, err := recipeService.ImportRecipes(recipes)
+	if err != nil {
+		return err
+	}
+
+	for _, reason := range result.Skipped {
+		fmt.Println("Skipped", reason)
+	}
+	fmt.Printf("Created %d, updated %d, skipped %d recipes\n", result.Created, result.Updated, len(result.Skipped))
+	return nil
+}
+
+func runJobs(app *app, args []string) error {
+	flags := flag.NewFlagSet("jobs", flag.ExitOnError)
+	name := flags.String("name", "", "only show runs of this job")
+	limit := flags.Int("limit", 20, "number of runs to show")
+	flags.Parse(args)
+
+	runs, err := repository.NewJobRepository(app.DB()).FindRuns(*name, *limit)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		duration := "-"
+		if run.FinishedAt != nil {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+		}
+		summary := run.Result
+		if run.Error != "" {
+			summary = run.Error
+		}
+		fmt.Printf("%s  %-26s %-9s %8s  %s\n", run.StartedAt.Format("2006-01-02 15:04:05"), run.JobName, run.Status, duration, summary)
+	}
+	return nil
+}
@@ -4,16 +4,28 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	API      APIKeys
+	Server       ServerConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	API          APIKeys
+	Notification NotificationConfig
+	Push         PushConfig
+	Mail         MailConfig
+	RateLimit    RateLimitConfig
+	Lockout      LockoutConfig
+	Account      AccountConfig
+	Scheduler    SchedulerConfig
+	Reward       RewardConfig
+	Storage      StorageConfig
+	Expiry       ExpiryConfig
 }
 
 type ServerConfig struct {
@@ -28,15 +40,123 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	MigrateOnStartup bool // apply pending migrations when the API starts
+	SeedOnStartup    bool // seed donation markets, supermarkets and vouchers
+	SeedDummyFoods   bool // seed demo foods for the varel@gmail.com account
 }
 
 type JWTConfig struct {
-	Secret     string
-	Expiration time.Duration
+	Secret            string
+	Expiration        time.Duration
+	RefreshExpiration time.Duration
 }
 
 type APIKeys struct {
 	GeminiKey string
+
+	// Resilience of Gemini calls
+	GeminiTimeout          time.Duration // per attempt
+	GeminiMaxRetries       int           // retries after the first attempt, for 429 and 5xx responses
+	GeminiRetryBaseDelay   time.Duration // doubled on every retry
+	GeminiBreakerThreshold int           // consecutive failures that open the circuit
+	GeminiBreakerCooldown  time.Duration // how long the circuit stays open before a trial call
+
+	// In-memory cache of scan responses, 0 disables it
+	GeminiCacheTTL  time.Duration
+	GeminiCacheSize int // entries
+}
+
+type NotificationConfig struct {
+	GenerateInterval time.Duration
+}
+
+type PushConfig struct {
+	Provider    string // expo, log
+	Endpoint    string
+	AccessToken string
+	MaxRetries  int
+}
+
+type MailConfig struct {
+	Provider              string // smtp, log
+	SMTPHost              string
+	SMTPPort              int
+	SMTPUsername          string
+	SMTPPassword          string
+	From                  string
+	OutputDir             string // log mailer writes emails here, empty logs them instead
+	LinkBaseURL           string // prefix of the links sent in emails
+	VerificationTokenTTL  time.Duration
+	PasswordResetTokenTTL time.Duration
+}
+
+// RateLimitRule allows Requests per Period, refilled continuously (token bucket)
+type RateLimitRule struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitConfig holds the limit of each rate-limited route group
+type RateLimitConfig struct {
+	Login     RateLimitRule
+	Scan      RateLimitRule
+	Recommend RateLimitRule
+}
+
+// LockoutConfig controls progressive account lockout after failed logins
+type LockoutConfig struct {
+	Threshold    int           // failed attempts before the first lock
+	BaseDuration time.Duration // first lock, doubled for every further failure
+	MaxDuration  time.Duration
+}
+
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration // how long a deletion request can be cancelled
+	PurgeInterval       time.Duration
+}
+
+type RewardConfig struct {
+	PointsExpiryMonths int           // earned points expire after this many months, 0 keeps them forever
+	ExpiringSoonWindow time.Duration // points expiring within this window are shown as expiring soon
+}
+
+type StorageConfig struct {
+	Driver        string // local, s3
+	LocalDir      string
+	PublicBaseURL string // base of the signed URLs of the local driver
+	SigningSecret string
+	SignedURLTTL  time.Duration
+	MaxUploadSize int64 // bytes
+	ThumbnailSize int   // longest side of thumbnails in pixels
+
+	// S3-compatible storage (AWS S3, MinIO, R2, ...)
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool // bucket in the path instead of the host name, needed by MinIO
+}
+
+// ExpiryConfig holds the rules used to predict and adjust expiry dates
+type ExpiryConfig struct {
+	RulesFile  string // JSON file overriding the built-in shelf lives per category, empty keeps them
+	ThawedDays int    // thawed food keeps at most this many days, however long it was frozen
+}
+
+// SchedulerConfig holds the background job schedules, see utils.ParseSchedule for the syntax
+type SchedulerConfig struct {
+	Enabled                   bool
+	LeaseTTL                  time.Duration // how long a crashed instance blocks a job
+	ExpireRedemptions         string
+	DeactivateVouchers        string
+	PruneNotificationReads    string
+	PruneJobRuns              string
+	ExpirePoints              string
+	NoWasteStreaks            string
+	NotificationReadRetention time.Duration
+	JobRunRetention           time.Duration
 }
 
 func Load() (*Config, error) {
@@ -45,11 +165,43 @@ func Load() (*Config, error) {
 	}
 
 	jwtExpiration, _ := time.ParseDuration(getEnv("JWT_EXPIRATION", "24h"))
+	refreshExpiration, _ := time.ParseDuration(getEnv("JWT_REFRESH_EXPIRATION", "720h"))
+	notificationInterval, _ := time.ParseDuration(getEnv("NOTIFICATION_INTERVAL", "15m"))
+	pushMaxRetries, _ := strconv.Atoi(getEnv("PUSH_MAX_RETRIES", "3"))
+	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	verificationTokenTTL, _ := time.ParseDuration(getEnv("EMAIL_VERIFICATION_TTL", "48h"))
+	passwordResetTokenTTL, _ := time.ParseDuration(getEnv("PASSWORD_RESET_TTL", "1h"))
+	lockoutThreshold, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_THRESHOLD", "5"))
+	lockoutBase, _ := time.ParseDuration(getEnv("LOGIN_LOCKOUT_BASE", "1m"))
+	lockoutMax, _ := time.ParseDuration(getEnv("LOGIN_LOCKOUT_MAX", "1h"))
+	deletionGracePeriod, _ := time.ParseDuration(getEnv("ACCOUNT_DELETION_GRACE", "720h"))
+	accountPurgeInterval, _ := time.ParseDuration(getEnv("ACCOUNT_PURGE_INTERVAL", "1h"))
+	jobLeaseTTL, _ := time.ParseDuration(getEnv("JOB_LEASE_TTL", "10m"))
+	notificationReadRetention, _ := time.ParseDuration(getEnv("NOTIFICATION_READ_RETENTION", "720h"))
+	jobRunRetention, _ := time.ParseDuration(getEnv("JOB_RUN_RETENTION", "720h"))
+	pointsExpiryMonths, _ := strconv.Atoi(getEnv("POINTS_EXPIRY_MONTHS", "12"))
+	expiringSoonWindow, _ := time.ParseDuration(getEnv("POINTS_EXPIRING_SOON_WINDOW", "720h"))
+	signedURLTTL, _ := time.ParseDuration(getEnv("STORAGE_SIGNED_URL_TTL", "24h"))
+	maxUploadSize, _ := strconv.ParseInt(getEnv("UPLOAD_MAX_SIZE", "5242880"), 10, 64)
+	thumbnailSize, _ := strconv.Atoi(getEnv("UPLOAD_THUMBNAIL_SIZE", "256"))
+	jwtSecret := getEnv("JWT_SECRET", "change-this-secret")
+	geminiTimeout, _ := time.ParseDuration(getEnv("GEMINI_TIMEOUT", "30s"))
+	geminiMaxRetries, _ := strconv.Atoi(getEnv("GEMINI_MAX_RETRIES", "2"))
+	geminiRetryBaseDelay, _ := time.ParseDuration(getEnv("GEMINI_RETRY_BASE_DELAY", "500ms"))
+	geminiBreakerThreshold, _ := strconv.Atoi(getEnv("GEMINI_BREAKER_THRESHOLD", "5"))
+	geminiBreakerCooldown, _ := time.ParseDuration(getEnv("GEMINI_BREAKER_COOLDOWN", "30s"))
+	geminiCacheTTL, _ := time.ParseDuration(getEnv("GEMINI_CACHE_TTL", "24h"))
+	geminiCacheSize, _ := strconv.Atoi(getEnv("GEMINI_CACHE_SIZE", "1000"))
+	expiryThawedDays, _ := strconv.Atoi(getEnv("EXPIRY_THAWED_DAYS", "2"))
+
+	// Seeding stays on for local development and must be enabled explicitly in production
+	env := getEnv("ENV", "development")
+	seedDefault := strconv.FormatBool(env != "production")
 
 	config := &Config{
 		Server: ServerConfig{
 			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Env:  env,
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -58,13 +210,95 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", ""),
 			DBName:   getEnv("DB_NAME", "makansikscan_db"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			MigrateOnStartup: getBool("DB_MIGRATE_ON_STARTUP", "true"),
+			SeedOnStartup:    getBool("DB_SEED_ON_STARTUP", seedDefault),
+			SeedDummyFoods:   getBool("DB_SEED_DUMMY_FOODS", seedDefault),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "change-this-secret"),
-			Expiration: jwtExpiration,
+			Secret:            jwtSecret,
+			Expiration:        jwtExpiration,
+			RefreshExpiration: refreshExpiration,
 		},
 		API: APIKeys{
-			GeminiKey: getEnv("GEMINI_API_KEY", ""),
+			GeminiKey:              getEnv("GEMINI_API_KEY", ""),
+			GeminiTimeout:          geminiTimeout,
+			GeminiMaxRetries:       geminiMaxRetries,
+			GeminiRetryBaseDelay:   geminiRetryBaseDelay,
+			GeminiBreakerThreshold: geminiBreakerThreshold,
+			GeminiBreakerCooldown:  geminiBreakerCooldown,
+			GeminiCacheTTL:         geminiCacheTTL,
+			GeminiCacheSize:        geminiCacheSize,
+		},
+		Notification: NotificationConfig{
+			GenerateInterval: notificationInterval,
+		},
+		Push: PushConfig{
+			Provider:    getEnv("PUSH_PROVIDER", "log"),
+			Endpoint:    getEnv("PUSH_ENDPOINT", "https://exp.host/--/api/v2/push/send"),
+			AccessToken: getEnv("PUSH_ACCESS_TOKEN", ""),
+			MaxRetries:  pushMaxRetries,
+		},
+		Mail: MailConfig{
+			Provider:              getEnv("MAIL_PROVIDER", "log"),
+			SMTPHost:              getEnv("SMTP_HOST", ""),
+			SMTPPort:              smtpPort,
+			SMTPUsername:          getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:          getEnv("SMTP_PASSWORD", ""),
+			From:                  getEnv("MAIL_FROM", "MakanSikScan <no-reply@makansikscan.app>"),
+			OutputDir:             getEnv("MAIL_OUTPUT_DIR", ""),
+			LinkBaseURL:           getEnv("MAIL_LINK_BASE_URL", "makansikscan://auth"),
+			VerificationTokenTTL:  verificationTokenTTL,
+			PasswordResetTokenTTL: passwordResetTokenTTL,
+		},
+		RateLimit: RateLimitConfig{
+			Login:     getRateLimit("RATE_LIMIT_LOGIN", "10/1m"),
+			Scan:      getRateLimit("RATE_LIMIT_SCAN", "30/1h"),
+			Recommend: getRateLimit("RATE_LIMIT_RECOMMEND", "60/1h"),
+		},
+		Lockout: LockoutConfig{
+			Threshold:    lockoutThreshold,
+			BaseDuration: lockoutBase,
+			MaxDuration:  lockoutMax,
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: deletionGracePeriod,
+			PurgeInterval:       accountPurgeInterval,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:                   getBool("SCHEDULER_ENABLED", "true"),
+			LeaseTTL:                  jobLeaseTTL,
+			ExpireRedemptions:         getEnv("SCHEDULE_EXPIRE_REDEMPTIONS", "*/15 * * * *"),
+			DeactivateVouchers:        getEnv("SCHEDULE_DEACTIVATE_VOUCHERS", "5 * * * *"),
+			PruneNotificationReads:    getEnv("SCHEDULE_PRUNE_NOTIFICATION_READS", "30 3 * * *"),
+			PruneJobRuns:              getEnv("SCHEDULE_PRUNE_JOB_RUNS", "45 3 * * *"),
+			ExpirePoints:              getEnv("SCHEDULE_EXPIRE_POINTS", "15 0 * * *"),
+			NoWasteStreaks:            getEnv("SCHEDULE_NO_WASTE_STREAKS", "30 0 * * *"),
+			NotificationReadRetention: notificationReadRetention,
+			JobRunRetention:           jobRunRetention,
+		},
+		Reward: RewardConfig{
+			PointsExpiryMonths: pointsExpiryMonths,
+			ExpiringSoonWindow: expiringSoonWindow,
+		},
+		Storage: StorageConfig{
+			Driver:        getEnv("STORAGE_DRIVER", "local"),
+			LocalDir:      getEnv("STORAGE_LOCAL_DIR", "./storage"),
+			PublicBaseURL: getEnv("STORAGE_PUBLIC_BASE_URL", "http://localhost:8080"),
+			SigningSecret: getEnv("STORAGE_SIGNING_SECRET", jwtSecret),
+			SignedURLTTL:  signedURLTTL,
+			MaxUploadSize: maxUploadSize,
+			ThumbnailSize: thumbnailSize,
+			S3Endpoint:    getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			S3Region:      getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:      getEnv("S3_BUCKET", ""),
+			S3AccessKey:   getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:   getEnv("S3_SECRET_KEY", ""),
+			S3PathStyle:   getBool("S3_PATH_STYLE", "false"),
+		},
+		Expiry: ExpiryConfig{
+			RulesFile:  getEnv("EXPIRY_RULES_FILE", ""),
+			ThawedDays: expiryThawedDays,
 		},
 	}
 
@@ -84,3 +318,38 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getBool(key, fallback string) bool {
+	value, err := strconv.ParseBool(getEnv(key, fallback))
+	if err != nil {
+		log.Printf("⚠️  Invalid %s, using %s", key, fallback)
+		value, _ = strconv.ParseBool(fallback)
+	}
+	return value
+}
+
+// getRateLimit parses a "requests/period" value such as "10/1m"
+func getRateLimit(key, fallback string) RateLimitRule {
+	rule, err := parseRateLimit(getEnv(key, fallback))
+	if err != nil {
+		log.Printf("⚠️  Invalid %s, using %s: %v", key, fallback, err)
+		rule, _ = parseRateLimit(fallback)
+	}
+	return rule
+}
+
+func parseRateLimit(value string) (RateLimitRule, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return RateLimitRule{}, fmt.Errorf("expected requests/period, got %q", value)
+	}
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return RateLimitRule{}, fmt.Errorf("invalid request count %q", parts[0])
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return RateLimitRule{}, fmt.Errorf("invalid period %q", parts[1])
+	}
+	return RateLimitRule{Requests: requests, Period: period}, nil
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 
 	"github.com/varel183/MakanSikScan/backend/internal/config"
-	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -28,41 +27,26 @@ func Connect(cfg *config.DatabaseConfig) error {
 	return nil
 }
 
-// Migrate runs auto-migration for all models
+// Migrate applies pending SQL migrations from internal/database/migrations
 func Migrate() error {
 	log.Println("🔄 Running database migrations...")
 
-	// Enable UUID extension
-	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error; err != nil {
-		return fmt.Errorf("failed to create uuid extension: %w", err)
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
 	}
 
-	// Auto-migrate all models
-	err := DB.AutoMigrate(
-		&models.User{},
-		&models.Food{},
-		&models.DonationMarket{},
-		&models.Donation{},
-		&models.Recipe{},
-		&models.Cart{},
-		&models.UserPoints{},
-		&models.PointTransaction{},
-		&models.Voucher{},
-		&models.VoucherRedemption{},
-		&models.NotificationRead{},
-		&models.Supermarket{},
-		&models.SupermarketProduct{},
-		&models.Transaction{},
-		&models.TransactionItem{},
-		&models.Order{},
-		&models.OrderItem{},
-	)
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
 
+	applied, err := migrator.Up()
 	if err != nil {
 		return fmt.Errorf("failed to migrate: %w", err)
 	}
 
-	log.Println("Migrations completed successfully")
+	log.Printf("Migrations completed successfully (%d applied)", applied)
 	return nil
 }
 
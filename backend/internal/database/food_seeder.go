@@ -48,7 +48,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Fresh Milk",
-			Category:        "dairy",
+			Category:        models.CategoryDairy,
 			Quantity:        2,
 			InitialQuantity: 2,
 			Unit:            "liter",
@@ -66,7 +66,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Chicken Breast",
-			Category:        "meat",
+			Category:        models.CategoryMeat,
 			Quantity:        1,
 			InitialQuantity: 1,
 			Unit:            "kg",
@@ -84,7 +84,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Tomatoes",
-			Category:        "vegetables",
+			Category:        models.CategoryVegetable,
 			Quantity:        5,
 			InitialQuantity: 5,
 			Unit:            "pcs",
@@ -102,7 +102,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Rice",
-			Category:        "grains",
+			Category:        models.CategoryGrain,
 			Quantity:        5,
 			InitialQuantity: 5,
 			Unit:            "kg",
@@ -120,7 +120,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Yogurt",
-			Category:        "dairy",
+			Category:        models.CategoryDairy,
 			Quantity:        4,
 			InitialQuantity: 4,
 			Unit:            "cup",
@@ -138,7 +138,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Bread",
-			Category:        "bakery",
+			Category:        models.CategoryGrain,
 			Quantity:        1,
 			InitialQuantity: 1,
 			Unit:            "loaf",
@@ -156,7 +156,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Eggs",
-			Category:        "protein",
+			Category:        models.CategoryDairy,
 			Quantity:        12,
 			InitialQuantity: 12,
 			Unit:            "pcs",
@@ -174,7 +174,7 @@ func SeedDummyFoods(userUUID uuid.UUID) error {
 			ID:              uuid.New(),
 			UserID:          userUUID,
 			Name:            "Bananas",
-			Category:        "fruits",
+			Category:        models.CategoryFruit,
 			Quantity:        6,
 			InitialQuantity: 6,
 			Unit:            "pcs",
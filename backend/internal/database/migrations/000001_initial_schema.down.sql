@@ -0,0 +1,25 @@
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS transaction_items;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS supermarket_products;
+DROP TABLE IF EXISTS supermarkets;
+DROP TABLE IF EXISTS quiet_hours;
+DROP TABLE IF EXISTS push_deliveries;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS notification_reads;
+DROP TABLE IF EXISTS voucher_redemptions;
+DROP TABLE IF EXISTS vouchers;
+DROP TABLE IF EXISTS point_transactions;
+DROP TABLE IF EXISTS user_points;
+DROP TABLE IF EXISTS carts;
+DROP TABLE IF EXISTS recipes;
+DROP TABLE IF EXISTS donations;
+DROP TABLE IF EXISTS donation_markets;
+DROP TABLE IF EXISTS foods;
+DROP TABLE IF EXISTS auth_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
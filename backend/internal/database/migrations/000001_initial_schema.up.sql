@@ -0,0 +1,417 @@
+-- Baseline schema, equivalent to what AutoMigrate created before versioned migrations.
+-- IF NOT EXISTS lets databases that were created by AutoMigrate adopt it without changes
+-- and columns added since the first AutoMigrate deployments are added after their table.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY,
+    email text NOT NULL,
+    password text NOT NULL,
+    name text NOT NULL,
+    phone text,
+    avatar text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    email_verified boolean DEFAULT false,
+    email_verified_at timestamptz,
+    failed_login_attempts bigint DEFAULT 0,
+    locked_until timestamptz,
+    deletion_scheduled_at timestamptz,
+    anonymized_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified boolean DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts bigint DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS anonymized_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    user_agent varchar(255),
+    ip_address varchar(45),
+    last_used_at timestamptz,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid PRIMARY KEY,
+    session_id uuid NOT NULL REFERENCES sessions (id),
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS auth_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    purpose varchar(30) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_user_id ON auth_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_tokens_token_hash ON auth_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS foods (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    name text NOT NULL,
+    category text NOT NULL,
+    quantity decimal NOT NULL DEFAULT 1,
+    initial_quantity decimal NOT NULL DEFAULT 1,
+    unit text NOT NULL DEFAULT 'pcs',
+    image_url text,
+    purchase_date timestamptz,
+    expiry_date timestamptz,
+    location text,
+    is_halal boolean DEFAULT true,
+    barcode text,
+    calories decimal DEFAULT 0,
+    protein decimal DEFAULT 0,
+    carbs decimal DEFAULT 0,
+    fat decimal DEFAULT 0,
+    add_method text DEFAULT 'manual',
+    scanned_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_foods_user_id ON foods (user_id);
+
+CREATE TABLE IF NOT EXISTS donation_markets (
+    id bigserial PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    description text,
+    address text,
+    phone varchar(50),
+    image_url varchar(500),
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_donation_markets_deleted_at ON donation_markets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS donations (
+    id bigserial PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    food_id uuid NOT NULL REFERENCES foods (id),
+    market_id bigint NOT NULL REFERENCES donation_markets (id),
+    quantity bigint NOT NULL,
+    points_earned bigint DEFAULT 0,
+    status varchar(50) DEFAULT 'pending',
+    notes text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_donations_user_id ON donations (user_id);
+CREATE INDEX IF NOT EXISTS idx_donations_food_id ON donations (food_id);
+CREATE INDEX IF NOT EXISTS idx_donations_market_id ON donations (market_id);
+CREATE INDEX IF NOT EXISTS idx_donations_deleted_at ON donations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS recipes (
+    id uuid PRIMARY KEY,
+    title text NOT NULL,
+    description text,
+    image_url text,
+    prep_time bigint,
+    cook_time bigint,
+    servings bigint DEFAULT 1,
+    difficulty text,
+    category text,
+    cuisine text,
+    ingredients jsonb,
+    instructions text,
+    calories decimal DEFAULT 0,
+    protein decimal DEFAULT 0,
+    carbs decimal DEFAULT 0,
+    fat decimal DEFAULT 0,
+    external_id text,
+    source text,
+    source_url text,
+    is_halal boolean DEFAULT false,
+    is_vegetarian boolean DEFAULT false,
+    is_vegan boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipes_external_id ON recipes (external_id);
+
+CREATE TABLE IF NOT EXISTS carts (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    item_name text NOT NULL,
+    quantity decimal NOT NULL DEFAULT 1,
+    unit text NOT NULL DEFAULT 'pcs',
+    category text,
+    is_purchased boolean DEFAULT false,
+    notes text,
+    recommended_store text,
+    estimated_price decimal DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_carts_user_id ON carts (user_id);
+
+CREATE TABLE IF NOT EXISTS user_points (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    total_points bigint DEFAULT 0,
+    available_points bigint DEFAULT 0,
+    used_points bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_points_user_id ON user_points (user_id);
+
+CREATE TABLE IF NOT EXISTS point_transactions (
+    id uuid PRIMARY KEY,
+    user_points_id uuid NOT NULL REFERENCES user_points (id),
+    type varchar(50) NOT NULL,
+    amount bigint NOT NULL,
+    source varchar(100),
+    description text,
+    reference_id uuid,
+    reference_type varchar(50),
+    created_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS vouchers (
+    id uuid PRIMARY KEY,
+    code varchar(50) NOT NULL,
+    title varchar(200) NOT NULL,
+    description text,
+    discount_type varchar(20) NOT NULL,
+    discount_value decimal NOT NULL,
+    min_purchase decimal DEFAULT 0,
+    max_discount decimal,
+    points_required bigint NOT NULL,
+    store_name varchar(200),
+    store_category varchar(100),
+    total_stock bigint NOT NULL,
+    remaining_stock bigint NOT NULL,
+    valid_from timestamptz,
+    valid_until timestamptz,
+    is_active boolean DEFAULT true,
+    terms_conditions text,
+    image_url text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vouchers_code ON vouchers (code);
+
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    voucher_id uuid NOT NULL REFERENCES vouchers (id),
+    points_spent bigint NOT NULL,
+    redemption_code varchar(100) NOT NULL,
+    status varchar(20) DEFAULT 'active',
+    redeemed_at timestamptz,
+    used_at timestamptz,
+    expires_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_voucher_redemptions_redemption_code ON voucher_redemptions (redemption_code);
+
+CREATE TABLE IF NOT EXISTS notification_reads (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    notification_id text NOT NULL,
+    read_at timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notification_reads_user_id ON notification_reads (user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_reads_notification_id ON notification_reads (notification_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    type varchar(50) NOT NULL,
+    title text NOT NULL,
+    message text,
+    severity varchar(20) DEFAULT 'info',
+    reference_id uuid,
+    reference_type varchar(50),
+    dedup_key varchar(200) NOT NULL,
+    food_name text,
+    quantity decimal,
+    unit text,
+    expiry_date timestamptz,
+    days_until_exp bigint,
+    is_read boolean DEFAULT false,
+    read_at timestamptz,
+    dismissed_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_dedup ON notifications (user_id, dedup_key);
+CREATE INDEX IF NOT EXISTS idx_notifications_type ON notifications (type);
+CREATE INDEX IF NOT EXISTS idx_notifications_is_read ON notifications (is_read);
+CREATE INDEX IF NOT EXISTS idx_notifications_dismissed_at ON notifications (dismissed_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    expiring_soon_enabled boolean DEFAULT true,
+    expired_enabled boolean DEFAULT true,
+    low_stock_enabled boolean DEFAULT true,
+    order_ready_enabled boolean DEFAULT true,
+    voucher_expiring_enabled boolean DEFAULT true,
+    default_threshold_days bigint DEFAULT 30,
+    critical_days bigint DEFAULT 3,
+    warning_days bigint DEFAULT 7,
+    category_thresholds jsonb DEFAULT '{}',
+    low_stock_percentage decimal DEFAULT 20,
+    digest_enabled boolean DEFAULT false,
+    digest_time varchar(5) DEFAULT '08:00',
+    timezone varchar(50) DEFAULT 'Asia/Jakarta',
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_preferences_user_id ON notification_preferences (user_id);
+
+CREATE TABLE IF NOT EXISTS devices (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    platform varchar(20) NOT NULL,
+    token varchar(255) NOT NULL,
+    last_seen_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_devices_user_id ON devices (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_token ON devices (token);
+
+CREATE TABLE IF NOT EXISTS push_deliveries (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    dedup_key varchar(200) NOT NULL,
+    title text NOT NULL,
+    body text,
+    data jsonb,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    attempts bigint DEFAULT 0,
+    last_error text,
+    sent_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_deliveries_user_dedup ON push_deliveries (user_id, dedup_key);
+CREATE INDEX IF NOT EXISTS idx_push_deliveries_status ON push_deliveries (status);
+
+CREATE TABLE IF NOT EXISTS quiet_hours (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    enabled boolean DEFAULT false,
+    start_time varchar(5) DEFAULT '22:00',
+    end_time varchar(5) DEFAULT '07:00',
+    timezone varchar(50) DEFAULT 'Asia/Jakarta',
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quiet_hours_user_id ON quiet_hours (user_id);
+
+CREATE TABLE IF NOT EXISTS supermarkets (
+    id uuid PRIMARY KEY,
+    name text NOT NULL,
+    location text NOT NULL,
+    address text,
+    phone_number text,
+    open_time text,
+    close_time text,
+    rating decimal,
+    image_url text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS supermarket_products (
+    id uuid PRIMARY KEY,
+    supermarket_id uuid NOT NULL REFERENCES supermarkets (id),
+    name text NOT NULL,
+    category text NOT NULL,
+    price decimal NOT NULL,
+    unit text NOT NULL,
+    stock bigint NOT NULL,
+    image_url text,
+    description text,
+    expiry_days bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_supermarket_products_supermarket_id ON supermarket_products (supermarket_id);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    supermarket_id uuid NOT NULL REFERENCES supermarkets (id),
+    total_amount decimal NOT NULL,
+    status text NOT NULL DEFAULT 'completed',
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_supermarket_id ON transactions (supermarket_id);
+
+CREATE TABLE IF NOT EXISTS transaction_items (
+    id uuid PRIMARY KEY,
+    transaction_id uuid NOT NULL REFERENCES transactions (id),
+    product_id uuid NOT NULL REFERENCES supermarket_products (id),
+    product_name text NOT NULL,
+    quantity decimal NOT NULL,
+    unit text NOT NULL,
+    price decimal NOT NULL,
+    subtotal decimal NOT NULL,
+    category text,
+    expiry_days bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_items_transaction_id ON transaction_items (transaction_id);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL REFERENCES users (id),
+    supermarket_id uuid NOT NULL REFERENCES supermarkets (id),
+    supermarket_name varchar(255) NOT NULL,
+    order_number varchar(100) NOT NULL CONSTRAINT uni_orders_order_number UNIQUE,
+    status varchar(50) NOT NULL DEFAULT 'pending_pickup',
+    total_amount decimal NOT NULL,
+    discount_amount decimal DEFAULT 0,
+    final_amount decimal NOT NULL,
+    voucher_code varchar(50),
+    voucher_title varchar(255),
+    redemption_id uuid,
+    picked_up_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id uuid NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id uuid NOT NULL,
+    product_name varchar(255) NOT NULL,
+    quantity bigint NOT NULL,
+    unit varchar(50) NOT NULL,
+    price decimal NOT NULL,
+    subtotal decimal NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
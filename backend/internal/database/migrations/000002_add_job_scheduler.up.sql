@@ -0,0 +1,21 @@
+CREATE TABLE job_leases (
+    name varchar(100) PRIMARY KEY,
+    owner varchar(255) NOT NULL,
+    locked_until timestamptz NOT NULL,
+    last_scheduled_for timestamptz NOT NULL,
+    updated_at timestamptz
+);
+
+CREATE TABLE job_runs (
+    id uuid PRIMARY KEY,
+    job_name varchar(100) NOT NULL,
+    instance varchar(255) NOT NULL,
+    scheduled_for timestamptz NOT NULL,
+    started_at timestamptz NOT NULL,
+    finished_at timestamptz,
+    status varchar(20) NOT NULL DEFAULT 'running',
+    result text,
+    error text
+);
+CREATE INDEX idx_job_runs_job_name ON job_runs (job_name);
+CREATE INDEX idx_job_runs_started_at ON job_runs (started_at);
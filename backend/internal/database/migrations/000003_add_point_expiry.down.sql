@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_point_transactions_user_points_id;
+DROP INDEX IF EXISTS idx_point_transactions_expires_at;
+ALTER TABLE point_transactions
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS remaining;
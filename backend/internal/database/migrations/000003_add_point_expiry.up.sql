@@ -0,0 +1,31 @@
+ALTER TABLE point_transactions
+    ADD COLUMN remaining bigint DEFAULT 0,
+    ADD COLUMN expires_at timestamptz;
+CREATE INDEX idx_point_transactions_expires_at ON point_transactions (expires_at);
+CREATE INDEX idx_point_transactions_user_points_id ON point_transactions (user_points_id);
+
+-- Existing earn transactions become lots. Spends and expiries so far use up the
+-- oldest lots first. Lots get the default 12 months, but at least 30 days from
+-- now so nobody loses points without notice.
+--
+-- The backfill always uses 12 months, whatever POINTS_EXPIRY_MONTHS is set to;
+-- it only affects lots earned before this migration. Deployments that keep
+-- points forever (POINTS_EXPIRY_MONTHS=0) should clear the backfilled dates:
+--   UPDATE point_transactions SET expires_at = NULL WHERE type = 'earn';
+WITH used AS (
+    SELECT user_points_id, SUM(amount) AS total
+    FROM point_transactions
+    WHERE type IN ('spend', 'expired')
+    GROUP BY user_points_id
+), lots AS (
+    SELECT id, user_points_id, amount,
+        SUM(amount) OVER (PARTITION BY user_points_id ORDER BY created_at, id) AS cumulative
+    FROM point_transactions
+    WHERE type = 'earn'
+)
+UPDATE point_transactions pt SET
+    remaining = GREATEST(0, LEAST(lots.amount, lots.cumulative - COALESCE(used.total, 0))),
+    expires_at = GREATEST(pt.created_at + INTERVAL '12 months', now() + INTERVAL '30 days')
+FROM lots
+LEFT JOIN used ON used.user_points_id = lots.user_points_id
+WHERE pt.id = lots.id;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_point_transactions_type_created_at;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
+DROP TABLE IF EXISTS user_achievements;
+DROP TABLE IF EXISTS user_streaks;
+DROP TABLE IF EXISTS user_activities;
+ALTER TABLE users DROP COLUMN IF EXISTS leaderboard_opt_out;
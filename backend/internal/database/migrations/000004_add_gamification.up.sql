@@ -0,0 +1,52 @@
+ALTER TABLE users ADD COLUMN leaderboard_opt_out boolean DEFAULT false;
+
+CREATE TABLE user_activities (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    type varchar(50) NOT NULL,
+    reference_id uuid,
+    created_at timestamptz
+);
+CREATE INDEX idx_user_activities_user_type ON user_activities (user_id, type);
+
+CREATE TABLE user_streaks (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    kind varchar(20) NOT NULL,
+    current bigint DEFAULT 0,
+    longest bigint DEFAULT 0,
+    last_date date,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX idx_user_streaks_user_kind ON user_streaks (user_id, kind);
+
+CREATE TABLE user_achievements (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    code varchar(50) NOT NULL,
+    bonus_points bigint DEFAULT 0,
+    awarded_at timestamptz
+);
+CREATE UNIQUE INDEX idx_user_achievements_user_code ON user_achievements (user_id, code);
+
+CREATE TABLE households (
+    id uuid PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    invite_code varchar(20) NOT NULL,
+    owner_id uuid NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX idx_households_invite_code ON households (invite_code);
+
+CREATE TABLE household_members (
+    id uuid PRIMARY KEY,
+    household_id uuid NOT NULL REFERENCES households (id),
+    user_id uuid NOT NULL REFERENCES users (id),
+    joined_at timestamptz
+);
+CREATE INDEX idx_household_members_household_id ON household_members (household_id);
+CREATE UNIQUE INDEX idx_household_members_user_id ON household_members (user_id);
+
+-- Leaderboards sum earned points per period
+CREATE INDEX idx_point_transactions_type_created_at ON point_transactions (type, created_at);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_point_transactions_point_rule_id;
+ALTER TABLE point_transactions
+    DROP COLUMN IF EXISTS rule_audit,
+    DROP COLUMN IF EXISTS point_rule_id;
+DROP TABLE IF EXISTS point_rules;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
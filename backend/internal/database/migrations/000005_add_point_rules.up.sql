@@ -0,0 +1,32 @@
+ALTER TABLE users ADD COLUMN role varchar(20) DEFAULT 'user';
+
+CREATE TABLE point_rules (
+    id uuid PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    action varchar(50) NOT NULL,
+    type varchar(20) NOT NULL DEFAULT 'base',
+    points bigint DEFAULT 0,
+    per_unit boolean DEFAULT false,
+    daily_cap bigint DEFAULT 0,
+    multiplier decimal DEFAULT 1,
+    category varchar(100),
+    near_expiry_days bigint,
+    starts_at timestamptz,
+    ends_at timestamptz,
+    priority bigint DEFAULT 0,
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX idx_point_rules_action ON point_rules (action);
+
+ALTER TABLE point_transactions
+    ADD COLUMN point_rule_id uuid,
+    ADD COLUMN rule_audit text;
+CREATE INDEX idx_point_transactions_point_rule_id ON point_transactions (point_rule_id);
+
+-- Defaults matching the amounts that used to be hard-coded, with daily caps against farming
+INSERT INTO point_rules (id, name, action, type, points, per_unit, daily_cap, created_at, updated_at) VALUES
+    (uuid_generate_v4(), 'Save food to storage', 'food_save', 'base', 10, false, 100, now(), now()),
+    (uuid_generate_v4(), 'Donate food', 'donation', 'base', 10, true, 500, now(), now()),
+    (uuid_generate_v4(), 'Log a meal', 'journal_entry', 'base', 5, false, 25, now(), now());
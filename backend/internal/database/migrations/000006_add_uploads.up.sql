@@ -0,0 +1,17 @@
+CREATE TABLE uploads (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    purpose varchar(20) NOT NULL,
+    key varchar(255) NOT NULL,
+    thumbnail_key varchar(255),
+    content_type varchar(50) NOT NULL,
+    size bigint NOT NULL,
+    width bigint,
+    height bigint,
+    created_at timestamptz
+);
+CREATE INDEX idx_uploads_user_id ON uploads (user_id);
+CREATE UNIQUE INDEX idx_uploads_key ON uploads (key);
+
+-- The scanner used to store a truncated base64 stub, which never was a usable image
+UPDATE foods SET image_url = '' WHERE image_url LIKE 'data:image/%';
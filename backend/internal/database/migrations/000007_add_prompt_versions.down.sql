@@ -0,0 +1,2 @@
+ALTER TABLE recipes DROP COLUMN IF EXISTS prompt_version;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN locale varchar(5) DEFAULT 'id';
+ALTER TABLE recipes ADD COLUMN prompt_version text;
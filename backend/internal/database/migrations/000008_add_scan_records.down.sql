@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scan_aliases;
+DROP TABLE IF EXISTS scan_records;
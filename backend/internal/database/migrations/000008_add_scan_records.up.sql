@@ -0,0 +1,32 @@
+CREATE TABLE scan_records (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    image_ref text,
+    prompt_version text,
+    raw_result jsonb,
+    recognized_name text NOT NULL,
+    recognized_category text,
+    confidence decimal,
+    suggested_name text,
+    suggested_category text,
+    alias_applied boolean DEFAULT false,
+    food_id uuid REFERENCES foods (id) ON DELETE SET NULL,
+    saved_name text,
+    saved_category text,
+    saved_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX idx_scan_records_user_id ON scan_records (user_id);
+CREATE INDEX idx_scan_records_created_at ON scan_records (created_at);
+
+CREATE TABLE scan_aliases (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    recognized_name text NOT NULL,
+    name text NOT NULL,
+    category text NOT NULL,
+    times_applied bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX idx_scan_aliases_user_recognized ON scan_aliases (user_id, recognized_name);
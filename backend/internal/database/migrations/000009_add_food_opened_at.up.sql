@@ -0,0 +1 @@
+ALTER TABLE foods ADD COLUMN opened_at timestamptz;
@@ -0,0 +1,2 @@
+ALTER TABLE notifications DROP COLUMN IF EXISTS expiry_reason;
+DROP TABLE IF EXISTS food_events;
@@ -0,0 +1,18 @@
+CREATE TABLE food_events (
+    id uuid PRIMARY KEY,
+    food_id uuid NOT NULL REFERENCES foods (id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users (id),
+    type varchar(20) NOT NULL,
+    from_location text,
+    to_location text,
+    from_zone varchar(20),
+    to_zone varchar(20),
+    previous_expiry timestamptz,
+    new_expiry timestamptz,
+    reason text,
+    created_at timestamptz
+);
+CREATE INDEX idx_food_events_food_id ON food_events (food_id);
+CREATE INDEX idx_food_events_user_id ON food_events (user_id);
+
+ALTER TABLE notifications ADD COLUMN expiry_reason text;
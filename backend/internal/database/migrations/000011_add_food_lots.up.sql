@@ -0,0 +1,22 @@
+CREATE TABLE food_lots (
+    id uuid PRIMARY KEY,
+    food_id uuid NOT NULL REFERENCES foods (id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users (id),
+    quantity decimal NOT NULL DEFAULT 0,
+    initial_quantity decimal NOT NULL DEFAULT 0,
+    purchase_date timestamptz,
+    expiry_date timestamptz,
+    source varchar(20) DEFAULT 'manual',
+    source_id uuid,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX idx_food_lots_food_id ON food_lots (food_id);
+CREATE INDEX idx_food_lots_user_id ON food_lots (user_id);
+
+-- Existing stock becomes one lot per food
+INSERT INTO food_lots (id, food_id, user_id, quantity, initial_quantity, purchase_date, expiry_date, source, created_at, updated_at)
+SELECT uuid_generate_v4(), id, user_id, quantity, quantity, purchase_date, expiry_date,
+       COALESCE(NULLIF(add_method, ''), 'manual'), created_at, updated_at
+FROM foods
+WHERE quantity > 0;
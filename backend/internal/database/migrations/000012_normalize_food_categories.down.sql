@@ -0,0 +1 @@
+-- The original free-text categories are not kept, there is nothing to restore
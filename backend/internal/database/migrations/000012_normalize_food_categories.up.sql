@@ -0,0 +1,88 @@
+-- Rewrite free-text food categories to the category taxonomy (models.FoodCategories),
+-- so manual entries like "vegetables" and scanned "Vegetable" are one category.
+-- Names that match no category or alias become 'Other', as NormalizeFoodCategory does.
+CREATE TEMPORARY TABLE category_aliases (alias text PRIMARY KEY, category text NOT NULL) ON COMMIT DROP;
+INSERT INTO category_aliases (alias, category) VALUES
+    ('vegetable', 'Vegetable'),
+    ('fruit', 'Fruit'),
+    ('meat', 'Meat'),
+    ('fish', 'Fish'),
+    ('dairy', 'Dairy'),
+    ('grain', 'Grain'),
+    ('frozen', 'Frozen'),
+    ('canned', 'Canned'),
+    ('beverage', 'Beverage'),
+    ('snack', 'Snack'),
+    ('other', 'Other'),
+    ('vegetables', 'Vegetable'),
+    ('veggies', 'Vegetable'),
+    ('sayur', 'Vegetable'),
+    ('sayuran', 'Vegetable'),
+    ('fruits', 'Fruit'),
+    ('buah', 'Fruit'),
+    ('buah-buahan', 'Fruit'),
+    ('meats', 'Meat'),
+    ('poultry', 'Meat'),
+    ('chicken', 'Meat'),
+    ('beef', 'Meat'),
+    ('daging', 'Meat'),
+    ('ayam', 'Meat'),
+    ('seafood', 'Fish'),
+    ('fish & seafood', 'Fish'),
+    ('ikan', 'Fish'),
+    ('makanan laut', 'Fish'),
+    ('milk', 'Dairy'),
+    ('dairy products', 'Dairy'),
+    ('eggs', 'Dairy'),
+    ('susu', 'Dairy'),
+    ('telur', 'Dairy'),
+    ('grains', 'Grain'),
+    ('cereal', 'Grain'),
+    ('cereals', 'Grain'),
+    ('bakery', 'Grain'),
+    ('bread', 'Grain'),
+    ('rice', 'Grain'),
+    ('pasta', 'Grain'),
+    ('noodles', 'Grain'),
+    ('beras', 'Grain'),
+    ('nasi', 'Grain'),
+    ('roti', 'Grain'),
+    ('mie', 'Grain'),
+    ('frozen food', 'Frozen'),
+    ('frozen foods', 'Frozen'),
+    ('makanan beku', 'Frozen'),
+    ('canned food', 'Canned'),
+    ('canned goods', 'Canned'),
+    ('kalengan', 'Canned'),
+    ('makanan kaleng', 'Canned'),
+    ('beverages', 'Beverage'),
+    ('drink', 'Beverage'),
+    ('drinks', 'Beverage'),
+    ('minuman', 'Beverage'),
+    ('snacks', 'Snack'),
+    ('camilan', 'Snack'),
+    ('cemilan', 'Snack'),
+    ('makanan ringan', 'Snack'),
+    ('others', 'Other'),
+    ('lainnya', 'Other');
+
+UPDATE foods SET category = COALESCE(
+    (SELECT category FROM category_aliases WHERE alias = lower(trim(foods.category))), 'Other')
+WHERE category NOT IN ('Vegetable', 'Fruit', 'Meat', 'Fish', 'Dairy', 'Grain', 'Frozen', 'Canned', 'Beverage', 'Snack', 'Other');
+
+UPDATE supermarket_products SET category = COALESCE(
+    (SELECT category FROM category_aliases WHERE alias = lower(trim(supermarket_products.category))), 'Other')
+WHERE category NOT IN ('Vegetable', 'Fruit', 'Meat', 'Fish', 'Dairy', 'Grain', 'Frozen', 'Canned', 'Beverage', 'Snack', 'Other');
+
+UPDATE carts SET category = COALESCE(
+    (SELECT category FROM category_aliases WHERE alias = lower(trim(carts.category))), 'Other')
+WHERE category IS NOT NULL AND category <> '' AND category NOT IN ('Vegetable', 'Fruit', 'Meat', 'Fish', 'Dairy', 'Grain', 'Frozen', 'Canned', 'Beverage', 'Snack', 'Other');
+
+UPDATE scan_aliases SET category = COALESCE(
+    (SELECT category FROM category_aliases WHERE alias = lower(trim(scan_aliases.category))), 'Other')
+WHERE category NOT IN ('Vegetable', 'Fruit', 'Meat', 'Fish', 'Dairy', 'Grain', 'Frozen', 'Canned', 'Beverage', 'Snack', 'Other');
+
+-- An empty rule category matches every food, unknown ones matched nothing and keep doing so
+UPDATE point_rules SET category = a.category
+FROM category_aliases a
+WHERE a.alias = lower(trim(point_rules.category)) AND point_rules.category <> a.category;
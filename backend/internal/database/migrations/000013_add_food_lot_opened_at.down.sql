@@ -0,0 +1 @@
+ALTER TABLE food_lots DROP COLUMN IF EXISTS opened_at;
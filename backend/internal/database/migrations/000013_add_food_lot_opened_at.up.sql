@@ -0,0 +1,13 @@
+ALTER TABLE food_lots ADD COLUMN opened_at timestamptz;
+
+-- Opening used to mark the whole food, it now marks the lot expiring first.
+-- Other lots keep the shortened expiry dates they were given back then.
+UPDATE food_lots SET opened_at = f.opened_at
+FROM foods f
+WHERE f.opened_at IS NOT NULL
+  AND food_lots.id = (
+      SELECT l.id FROM food_lots l
+      WHERE l.food_id = f.id AND l.quantity > 0
+      ORDER BY l.expiry_date ASC NULLS LAST, l.created_at ASC
+      LIMIT 1
+  );
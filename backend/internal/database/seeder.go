@@ -1,7 +1,9 @@
 package database
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 )
@@ -88,3 +90,42 @@ func SeedAll() {
 
 	log.Println("🎉 All seeding completed!")
 }
+
+// SeederNames lists the seeders that can be run on their own, in the order SeedAll runs them
+var SeederNames = []string{"markets", "supermarkets", "vouchers"}
+
+// SeedOnly runs the named seeders, see SeederNames
+func SeedOnly(names []string) error {
+	seeders := map[string]func() error{
+		"markets": func() error {
+			SeedDonationMarkets()
+			return nil
+		},
+		"supermarkets": func() error {
+			SeedSupermarkets()
+			return nil
+		},
+		"vouchers": func() error {
+			return SeedVouchers(DB)
+		},
+	}
+
+	for _, name := range names {
+		if _, ok := seeders[name]; !ok {
+			return fmt.Errorf("unknown seeder %q, expected one of %s", name, strings.Join(SeederNames, ", "))
+		}
+	}
+
+	for _, name := range SeederNames {
+		for _, selected := range names {
+			if selected != name {
+				continue
+			}
+			log.Printf("🌱 Running %s seeder...", name)
+			if err := seeders[name](); err != nil {
+				return fmt.Errorf("%s seeder failed: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -74,44 +74,44 @@ func SeedSupermarketProducts(supermarketID uuid.UUID) {
 
 	products := []models.SupermarketProduct{
 		// Vegetables
-		{SupermarketID: supermarketID, Name: "Tomato", Category: "vegetables", Price: 15000, Unit: "kg", Stock: 50, ExpiryDays: 7, Description: "Fresh red tomatoes"},
-		{SupermarketID: supermarketID, Name: "Carrot", Category: "vegetables", Price: 12000, Unit: "kg", Stock: 40, ExpiryDays: 14, Description: "Orange carrots"},
-		{SupermarketID: supermarketID, Name: "Potato", Category: "vegetables", Price: 10000, Unit: "kg", Stock: 60, ExpiryDays: 30, Description: "Fresh potatoes"},
-		{SupermarketID: supermarketID, Name: "Onion", Category: "vegetables", Price: 18000, Unit: "kg", Stock: 45, ExpiryDays: 21, Description: "Red onions"},
-		{SupermarketID: supermarketID, Name: "Cabbage", Category: "vegetables", Price: 8000, Unit: "kg", Stock: 35, ExpiryDays: 10, Description: "Green cabbage"},
+		{SupermarketID: supermarketID, Name: "Tomato", Category: models.CategoryVegetable, Price: 15000, Unit: "kg", Stock: 50, ExpiryDays: 7, Description: "Fresh red tomatoes"},
+		{SupermarketID: supermarketID, Name: "Carrot", Category: models.CategoryVegetable, Price: 12000, Unit: "kg", Stock: 40, ExpiryDays: 14, Description: "Orange carrots"},
+		{SupermarketID: supermarketID, Name: "Potato", Category: models.CategoryVegetable, Price: 10000, Unit: "kg", Stock: 60, ExpiryDays: 30, Description: "Fresh potatoes"},
+		{SupermarketID: supermarketID, Name: "Onion", Category: models.CategoryVegetable, Price: 18000, Unit: "kg", Stock: 45, ExpiryDays: 21, Description: "Red onions"},
+		{SupermarketID: supermarketID, Name: "Cabbage", Category: models.CategoryVegetable, Price: 8000, Unit: "kg", Stock: 35, ExpiryDays: 10, Description: "Green cabbage"},
 
 		// Fruits
-		{SupermarketID: supermarketID, Name: "Apple", Category: "fruits", Price: 35000, Unit: "kg", Stock: 50, ExpiryDays: 14, Description: "Fresh apples"},
-		{SupermarketID: supermarketID, Name: "Banana", Category: "fruits", Price: 20000, Unit: "kg", Stock: 60, ExpiryDays: 7, Description: "Ripe bananas"},
-		{SupermarketID: supermarketID, Name: "Orange", Category: "fruits", Price: 25000, Unit: "kg", Stock: 45, ExpiryDays: 10, Description: "Sweet oranges"},
-		{SupermarketID: supermarketID, Name: "Mango", Category: "fruits", Price: 30000, Unit: "kg", Stock: 40, ExpiryDays: 7, Description: "Sweet mangoes"},
+		{SupermarketID: supermarketID, Name: "Apple", Category: models.CategoryFruit, Price: 35000, Unit: "kg", Stock: 50, ExpiryDays: 14, Description: "Fresh apples"},
+		{SupermarketID: supermarketID, Name: "Banana", Category: models.CategoryFruit, Price: 20000, Unit: "kg", Stock: 60, ExpiryDays: 7, Description: "Ripe bananas"},
+		{SupermarketID: supermarketID, Name: "Orange", Category: models.CategoryFruit, Price: 25000, Unit: "kg", Stock: 45, ExpiryDays: 10, Description: "Sweet oranges"},
+		{SupermarketID: supermarketID, Name: "Mango", Category: models.CategoryFruit, Price: 30000, Unit: "kg", Stock: 40, ExpiryDays: 7, Description: "Sweet mangoes"},
 
 		// Meat
-		{SupermarketID: supermarketID, Name: "Chicken Breast", Category: "meat", Price: 45000, Unit: "kg", Stock: 30, ExpiryDays: 3, Description: "Fresh chicken breast"},
-		{SupermarketID: supermarketID, Name: "Beef", Category: "meat", Price: 120000, Unit: "kg", Stock: 25, ExpiryDays: 5, Description: "Premium beef"},
-		{SupermarketID: supermarketID, Name: "Ground Beef", Category: "meat", Price: 80000, Unit: "kg", Stock: 20, ExpiryDays: 3, Description: "Fresh ground beef"},
+		{SupermarketID: supermarketID, Name: "Chicken Breast", Category: models.CategoryMeat, Price: 45000, Unit: "kg", Stock: 30, ExpiryDays: 3, Description: "Fresh chicken breast"},
+		{SupermarketID: supermarketID, Name: "Beef", Category: models.CategoryMeat, Price: 120000, Unit: "kg", Stock: 25, ExpiryDays: 5, Description: "Premium beef"},
+		{SupermarketID: supermarketID, Name: "Ground Beef", Category: models.CategoryMeat, Price: 80000, Unit: "kg", Stock: 20, ExpiryDays: 3, Description: "Fresh ground beef"},
 
 		// Seafood
-		{SupermarketID: supermarketID, Name: "Salmon", Category: "seafood", Price: 150000, Unit: "kg", Stock: 15, ExpiryDays: 2, Description: "Fresh salmon fillet"},
-		{SupermarketID: supermarketID, Name: "Shrimp", Category: "seafood", Price: 90000, Unit: "kg", Stock: 20, ExpiryDays: 2, Description: "Fresh shrimp"},
-		{SupermarketID: supermarketID, Name: "Tuna", Category: "seafood", Price: 85000, Unit: "kg", Stock: 18, ExpiryDays: 2, Description: "Fresh tuna"},
+		{SupermarketID: supermarketID, Name: "Salmon", Category: models.CategoryFish, Price: 150000, Unit: "kg", Stock: 15, ExpiryDays: 2, Description: "Fresh salmon fillet"},
+		{SupermarketID: supermarketID, Name: "Shrimp", Category: models.CategoryFish, Price: 90000, Unit: "kg", Stock: 20, ExpiryDays: 2, Description: "Fresh shrimp"},
+		{SupermarketID: supermarketID, Name: "Tuna", Category: models.CategoryFish, Price: 85000, Unit: "kg", Stock: 18, ExpiryDays: 2, Description: "Fresh tuna"},
 
 		// Dairy
-		{SupermarketID: supermarketID, Name: "Milk", Category: "dairy", Price: 18000, Unit: "liter", Stock: 50, ExpiryDays: 7, Description: "Fresh milk"},
-		{SupermarketID: supermarketID, Name: "Cheese", Category: "dairy", Price: 45000, Unit: "kg", Stock: 30, ExpiryDays: 30, Description: "Cheddar cheese"},
-		{SupermarketID: supermarketID, Name: "Yogurt", Category: "dairy", Price: 12000, Unit: "pcs", Stock: 40, ExpiryDays: 14, Description: "Greek yogurt"},
-		{SupermarketID: supermarketID, Name: "Butter", Category: "dairy", Price: 35000, Unit: "kg", Stock: 25, ExpiryDays: 60, Description: "Unsalted butter"},
+		{SupermarketID: supermarketID, Name: "Milk", Category: models.CategoryDairy, Price: 18000, Unit: "liter", Stock: 50, ExpiryDays: 7, Description: "Fresh milk"},
+		{SupermarketID: supermarketID, Name: "Cheese", Category: models.CategoryDairy, Price: 45000, Unit: "kg", Stock: 30, ExpiryDays: 30, Description: "Cheddar cheese"},
+		{SupermarketID: supermarketID, Name: "Yogurt", Category: models.CategoryDairy, Price: 12000, Unit: "pcs", Stock: 40, ExpiryDays: 14, Description: "Greek yogurt"},
+		{SupermarketID: supermarketID, Name: "Butter", Category: models.CategoryDairy, Price: 35000, Unit: "kg", Stock: 25, ExpiryDays: 60, Description: "Unsalted butter"},
 
 		// Grains
-		{SupermarketID: supermarketID, Name: "Rice", Category: "grains", Price: 15000, Unit: "kg", Stock: 100, ExpiryDays: 365, Description: "Premium white rice"},
-		{SupermarketID: supermarketID, Name: "Bread", Category: "grains", Price: 12000, Unit: "pcs", Stock: 50, ExpiryDays: 5, Description: "Whole wheat bread"},
-		{SupermarketID: supermarketID, Name: "Pasta", Category: "grains", Price: 18000, Unit: "kg", Stock: 60, ExpiryDays: 180, Description: "Spaghetti pasta"},
-		{SupermarketID: supermarketID, Name: "Flour", Category: "grains", Price: 12000, Unit: "kg", Stock: 70, ExpiryDays: 180, Description: "All-purpose flour"},
+		{SupermarketID: supermarketID, Name: "Rice", Category: models.CategoryGrain, Price: 15000, Unit: "kg", Stock: 100, ExpiryDays: 365, Description: "Premium white rice"},
+		{SupermarketID: supermarketID, Name: "Bread", Category: models.CategoryGrain, Price: 12000, Unit: "pcs", Stock: 50, ExpiryDays: 5, Description: "Whole wheat bread"},
+		{SupermarketID: supermarketID, Name: "Pasta", Category: models.CategoryGrain, Price: 18000, Unit: "kg", Stock: 60, ExpiryDays: 180, Description: "Spaghetti pasta"},
+		{SupermarketID: supermarketID, Name: "Flour", Category: models.CategoryGrain, Price: 12000, Unit: "kg", Stock: 70, ExpiryDays: 180, Description: "All-purpose flour"},
 
 		// Beverages
-		{SupermarketID: supermarketID, Name: "Orange Juice", Category: "beverages", Price: 25000, Unit: "liter", Stock: 40, ExpiryDays: 7, Description: "Fresh orange juice"},
-		{SupermarketID: supermarketID, Name: "Mineral Water", Category: "beverages", Price: 5000, Unit: "liter", Stock: 100, ExpiryDays: 365, Description: "Purified water"},
-		{SupermarketID: supermarketID, Name: "Coffee", Category: "beverages", Price: 45000, Unit: "kg", Stock: 30, ExpiryDays: 180, Description: "Ground coffee"},
+		{SupermarketID: supermarketID, Name: "Orange Juice", Category: models.CategoryBeverage, Price: 25000, Unit: "liter", Stock: 40, ExpiryDays: 7, Description: "Fresh orange juice"},
+		{SupermarketID: supermarketID, Name: "Mineral Water", Category: models.CategoryBeverage, Price: 5000, Unit: "liter", Stock: 100, ExpiryDays: 365, Description: "Purified water"},
+		{SupermarketID: supermarketID, Name: "Coffee", Category: models.CategoryBeverage, Price: 45000, Unit: "kg", Stock: 30, ExpiryDays: 180, Description: "Ground coffee"},
 	}
 
 	for _, product := range products {
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// ExportData downloads all of the user's data
+// @Summary Export personal data
+// @Tags auth
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file "Zip archive of JSON files"
+// @Router /api/v1/auth/me/export [get]
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	archive, err := h.accountService.ExportData(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	filename := fmt.Sprintf("makansikscan-export-%s.zip", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// DeleteAccount schedules the account for deletion after a grace period
+// @Summary Delete account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.DeleteAccountRequest true "Password confirmation"
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/auth/me [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	scheduledAt, err := h.accountService.RequestDeletion(userID, req.Password)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "password is incorrect" {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, utils.SuccessResponse("Account scheduled for deletion", gin.H{
+		"deletion_scheduled_at": scheduledAt,
+	}))
+}
+
+// CancelDeletion cancels a pending account deletion
+// @Summary Cancel account deletion
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/auth/me/cancel-deletion [post]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(userID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "account deletion not requested" {
+			statusCode = http.StatusConflict
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Account deletion cancelled", nil))
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type AICacheHandler struct {
+	geminiService *service.GeminiService
+}
+
+func NewAICacheHandler(geminiService *service.GeminiService) *AICacheHandler {
+	return &AICacheHandler{
+		geminiService: geminiService,
+	}
+}
+
+// GetStats returns the size and hit/miss counters of the AI response cache
+// @Summary Get AI cache stats
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/ai-cache [get]
+func (h *AICacheHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.SuccessResponse("AI cache stats retrieved successfully", h.geminiService.CacheStats()))
+}
+
+// Purge drops every cached AI response
+// @Summary Purge AI cache
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/ai-cache [delete]
+func (h *AICacheHandler) Purge(c *gin.Context) {
+	removed := h.geminiService.PurgeCache()
+	c.JSON(http.StatusOK, utils.SuccessResponse("AI cache purged successfully", gin.H{"removed": removed}))
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *service.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetFoodsAdded retrieves items added per bucket by add method
+// @Summary Get foods added over time
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param bucket query string false "Bucket size" Enums(day, week, month, year) default(week)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/analytics/foods-added [get]
+func (h *AnalyticsHandler) GetFoodsAdded(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	query, err := service.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("bucket"), "week")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	buckets, err := h.analyticsService.GetFoodsAdded(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Foods added retrieved successfully", gin.H{
+		"query":   query,
+		"buckets": buckets,
+	}))
+}
+
+// GetConsumptionTime retrieves average days from purchase to consumption per category
+// @Summary Get average consumption time
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/analytics/consumption-time [get]
+func (h *AnalyticsHandler) GetConsumptionTime(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	query, err := service.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("bucket"), "month")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	stats, err := h.analyticsService.GetConsumptionTime(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Consumption time retrieved successfully", gin.H{
+		"query":       query,
+		"by_category": stats,
+	}))
+}
+
+// GetExpiryRate retrieves the share of items that expired before being used
+// @Summary Get expiry rate
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param bucket query string false "Bucket size" Enums(day, week, month, year) default(month)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/analytics/expiry-rate [get]
+func (h *AnalyticsHandler) GetExpiryRate(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	query, err := service.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("bucket"), "month")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	rate, err := h.analyticsService.GetExpiryRate(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+	rate["query"] = query
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Expiry rate retrieved successfully", rate))
+}
+
+// GetSpending retrieves money spent on transactions and orders
+// @Summary Get spending over time
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param bucket query string false "Bucket size" Enums(day, week, month, year) default(month)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/analytics/spending [get]
+func (h *AnalyticsHandler) GetSpending(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	query, err := service.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("bucket"), "month")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	spending, err := h.analyticsService.GetSpending(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+	spending["query"] = query
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Spending retrieved successfully", spending))
+}
+
+// GetPointsBySource retrieves points earned per source
+// @Summary Get points earned by source
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param bucket query string false "Bucket size" Enums(day, week, month, year) default(month)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/analytics/points [get]
+func (h *AnalyticsHandler) GetPointsBySource(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	query, err := service.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("bucket"), "month")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	points, err := h.analyticsService.GetPointsBySource(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+	points["query"] = query
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Points by source retrieved successfully", points))
+}
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 	"github.com/varel183/MakanSikScan/backend/internal/service"
 	"github.com/varel183/MakanSikScan/backend/internal/utils"
@@ -43,7 +45,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Register user
-	authResponse, err := h.authService.Register(&req)
+	authResponse, err := h.authService.Register(&req, clientInfo(c))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "email already registered" {
@@ -80,7 +82,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Login user
-	authResponse, err := h.authService.Login(&req)
+	authResponse, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, utils.ErrorResponse(err.Error()))
 		return
@@ -136,6 +138,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		Name   string `json:"name"`
 		Phone  string `json:"phone"`
 		Avatar string `json:"avatar"`
+		Locale string `json:"locale"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -143,11 +146,297 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	profile, err := h.authService.UpdateProfile(userID, req.Name, req.Phone, req.Avatar)
+	profile, err := h.authService.UpdateProfile(userID, req.Name, req.Phone, req.Avatar, req.Locale)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		status := http.StatusInternalServerError
+		if err.Error() == "upload not found" || err.Error() == "image must be an uploaded image or an http(s) URL" ||
+			strings.HasPrefix(err.Error(), "unsupported locale") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ErrorResponse(err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.SuccessResponse("Profile updated successfully", profile))
 }
+
+// Refresh exchanges a refresh token for a new token pair
+// @Summary Refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.RefreshRequest true "Refresh token"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req service.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	authResponse, err := h.authService.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "invalid refresh token", "refresh token expired", "refresh token reuse detected":
+			statusCode = http.StatusUnauthorized
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Token refreshed successfully", authResponse))
+}
+
+// Logout revokes the current session
+// @Summary Logout
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	if err := h.authService.Logout(userID, middleware.GetSessionID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Logged out successfully", nil))
+}
+
+// GetSessions lists the user's signed-in devices
+// @Summary Get sessions
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID, middleware.GetSessionID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Sessions retrieved successfully", sessions))
+}
+
+// RevokeOtherSessions signs out every device except the current one
+// @Summary Sign out other devices
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/auth/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	count, err := h.authService.RevokeOtherSessions(userID, middleware.GetSessionID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Other sessions revoked successfully", gin.H{"count": count}))
+}
+
+// RevokeSession signs out one device
+// @Summary Revoke session
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid session ID"))
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "session not found" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Session revoked successfully", nil))
+}
+
+// VerifyEmail confirms the user's email address
+// @Summary Verify email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req service.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "invalid or expired token" {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Email verified successfully", nil))
+}
+
+// ResendVerificationEmail sends a new verification email
+// @Summary Resend verification email
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(userID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "email already verified" {
+			statusCode = http.StatusConflict
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Verification email sent", nil))
+}
+
+// ForgotPassword emails a password reset link
+// @Summary Forgot password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.authService.ForgotPassword(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("If the email is registered, a reset link has been sent", nil))
+}
+
+// ResetPassword sets a new password with a reset token
+// @Summary Reset password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "invalid or expired token" {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Password reset successfully", nil))
+}
+
+// ChangePassword changes the password of the authenticated user
+// @Summary Change password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.ChangePasswordRequest true "Old and new password"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.authService.ChangePassword(userID, middleware.GetSessionID(c), &req); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "old password is incorrect", "new password must be different from the old password":
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Password changed successfully", nil))
+}
+
+// clientInfo captures the device details stored with a session
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/units"
 	"github.com/varel183/MakanSikScan/backend/internal/utils"
 )
 
@@ -20,7 +22,18 @@ func NewCartHandler(cartService *service.CartService) *CartHandler {
 	}
 }
 
-// CreateCartItem creates a new cart item
+// cartErrorStatus maps cart errors to status codes
+func cartErrorStatus(err error) int {
+	if errors.Is(err, units.ErrUnknownUnit) {
+		return http.StatusBadRequest
+	}
+	if err.Error() == "cart item not found" {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateCartItem creates a new cart item, or adds to a pending item with the same name
 // @Summary Create cart item
 // @Tags cart
 // @Accept json
@@ -49,7 +62,7 @@ func (h *CartHandler) CreateCartItem(c *gin.Context) {
 
 	cart, err := h.cartService.CreateCartItem(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(cartErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
@@ -176,7 +189,7 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 
 	cart, err := h.cartService.UpdateCartItem(id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(cartErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
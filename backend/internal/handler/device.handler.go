@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type DeviceHandler struct {
+	pushService *service.PushService
+}
+
+func NewDeviceHandler(pushService *service.PushService) *DeviceHandler {
+	return &DeviceHandler{
+		pushService: pushService,
+	}
+}
+
+// RegisterDevice registers a device token for push notifications
+// @Summary Register device
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body object true "Platform and push token"
+// @Success 201 {object} utils.Response
+// @Router /api/v1/devices [post]
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req struct {
+		Platform string `json:"platform" binding:"required,oneof=ios android web"`
+		Token    string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(userID, req.Platform, req.Token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse("Device registered successfully", device))
+}
+
+// GetDevices lists the user's registered devices
+// @Summary Get devices
+// @Tags devices
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/devices [get]
+func (h *DeviceHandler) GetDevices(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	devices, err := h.pushService.GetDevices(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Devices retrieved successfully", devices))
+}
+
+// UnregisterDevice removes a device
+// @Summary Unregister device
+// @Tags devices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Device ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/devices/{id} [delete]
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid device ID"))
+		return
+	}
+
+	if err := h.pushService.UnregisterDevice(userID, deviceID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "device not found" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Device unregistered successfully", nil))
+}
+
+// GetQuietHours retrieves the user's push quiet hours
+// @Summary Get quiet hours
+// @Tags devices
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/devices/quiet-hours [get]
+func (h *DeviceHandler) GetQuietHours(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	quietHours, err := h.pushService.GetQuietHours(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Quiet hours retrieved successfully", quietHours))
+}
+
+// SetQuietHours updates the user's push quiet hours
+// @Summary Set quiet hours
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.SetQuietHoursRequest true "Quiet hours"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/devices/quiet-hours [put]
+func (h *DeviceHandler) SetQuietHours(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.SetQuietHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	quietHours, err := h.pushService.SetQuietHours(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Quiet hours updated successfully", quietHours))
+}
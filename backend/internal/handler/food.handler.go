@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 
@@ -9,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/units"
 	"github.com/varel183/MakanSikScan/backend/internal/utils"
 )
 
@@ -24,6 +28,23 @@ func NewFoodHandler(foodService *service.FoodService, scannerService *service.Sc
 	}
 }
 
+// foodErrorStatus maps food errors to status codes
+func foodErrorStatus(err error) int {
+	if errors.Is(err, units.ErrUnknownUnit) || errors.Is(err, units.ErrIncompatible) {
+		return http.StatusBadRequest
+	}
+	switch err.Error() {
+	case "upload not found", "image must be an uploaded image or an http(s) URL", "opened_at cannot be in the future":
+		return http.StatusBadRequest
+	case "alias not found", "food not found":
+		return http.StatusNotFound
+	case "food is already opened":
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // CreateFood handles creating a new food item
 // @Summary Create food item
 // @Tags food
@@ -53,7 +74,7 @@ func (h *FoodHandler) CreateFood(c *gin.Context) {
 
 	food, err := h.foodService.CreateFood(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
@@ -292,7 +313,7 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 
 	food, err := h.foodService.UpdateFood(id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
@@ -391,9 +412,11 @@ func (h *FoodHandler) SearchFood(c *gin.Context) {
 // @Security BearerAuth
 // @Param request body service.ScanFoodRequest true "Scan request"
 // @Success 200 {object} utils.Response
+// @Failure 502 {object} utils.Response
+// @Failure 503 {object} utils.Response
 // @Router /api/v1/foods/scan [post]
 func (h *FoodHandler) ScanFood(c *gin.Context) {
-	_, err := middleware.GetUserID(c)
+	userID, err := middleware.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
 		return
@@ -411,8 +434,16 @@ func (h *FoodHandler) ScanFood(c *gin.Context) {
 	}
 
 	// Scan the food (just analyze, don't save yet)
-	scanResult, err := h.scannerService.ScanFood(&req)
-	if err != nil {
+	scanResult, err := h.scannerService.ScanFood(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrAIUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, utils.ErrorResponse(service.ErrAIUnavailable.Error()))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidAIResponse) {
+			c.JSON(http.StatusBadGateway, utils.ErrorResponse(service.ErrInvalidAIResponse.Error()+", please try again"))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
 		return
 	}
@@ -473,13 +504,124 @@ func (h *FoodHandler) AddScannedFood(c *gin.Context) {
 
 	food, err := h.foodService.CreateFood(userID, createReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
+	// The food is saved either way, a missing scan only means nothing is learned
+	if req.ScanID != nil {
+		if err := h.scannerService.RecordSavedFood(userID, *req.ScanID, food); err != nil {
+			log.Printf("⚠️  Failed to record saved scan %s: %v", *req.ScanID, err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, utils.SuccessResponse("Food added to storage successfully", food))
 }
 
+// GetScanAliases lists the corrections the scanner learned from the user
+// @Summary Get learned scan aliases
+// @Tags food
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/foods/scan/aliases [get]
+func (h *FoodHandler) GetScanAliases(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	aliases, err := h.scannerService.GetAliases(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Scan aliases retrieved successfully", aliases))
+}
+
+// DeleteScanAlias stops applying a learned alias to scans
+// @Summary Delete learned scan alias
+// @Tags food
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alias ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/foods/scan/aliases/{id} [delete]
+func (h *FoodHandler) DeleteScanAlias(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	aliasID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid alias ID"))
+		return
+	}
+
+	if err := h.scannerService.DeleteAlias(userID, aliasID); err != nil {
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Scan alias deleted successfully", nil))
+}
+
+// GetScanAccuracy reports how often the user's scans were saved without corrections
+// @Summary Get scan accuracy
+// @Tags food
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param bucket query string false "Bucket size" Enums(day, week, month, year) default(week)
+// @Success 200 {object} utils.Response
+// @Router /api/v1/foods/scan/accuracy [get]
+func (h *FoodHandler) GetScanAccuracy(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	h.scanAccuracy(c, &userID)
+}
+
+// GetAllScanAccuracy reports scan accuracy across all users
+// @Summary Get scan accuracy of all users
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param bucket query string false "Bucket size" Enums(day, week, month, year) default(week)
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/scan-accuracy [get]
+func (h *FoodHandler) GetAllScanAccuracy(c *gin.Context) {
+	h.scanAccuracy(c, nil)
+}
+
+func (h *FoodHandler) scanAccuracy(c *gin.Context, userID *uuid.UUID) {
+	query, err := service.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("bucket"), "week")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	report, err := h.scannerService.GetAccuracyReport(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Scan accuracy retrieved successfully", report))
+}
+
 // CheckDuplicate checks if food with same name exists
 // @Summary Check duplicate food
 // @Tags food
@@ -517,14 +659,14 @@ func (h *FoodHandler) CheckDuplicate(c *gin.Context) {
 	}))
 }
 
-// UpdateStock updates food stock (quantity only)
+// UpdateStock adds a new purchase of a food as a lot with its own expiry date
 // @Summary Update food stock
 // @Tags food
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Food ID"
-// @Param quantity body object true "Additional quantity"
+// @Param request body service.AddStockRequest true "Additional quantity"
 // @Success 200 {object} utils.Response
 // @Router /api/v1/foods/{id}/stock [patch]
 func (h *FoodHandler) UpdateStock(c *gin.Context) {
@@ -535,17 +677,15 @@ func (h *FoodHandler) UpdateStock(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Quantity float64 `json:"quantity" binding:"required,gt=0"`
-	}
+	var req service.AddStockRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
 		return
 	}
 
-	food, err := h.foodService.UpdateFoodStock(id, req.Quantity)
+	food, err := h.foodService.UpdateFoodStock(id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
@@ -554,6 +694,119 @@ func (h *FoodHandler) UpdateStock(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.SuccessResponse("Stock updated successfully", food))
 }
 
+// OpenFood marks a sealed food as opened
+// @Summary Open food
+// @Description Records that the package was opened and shortens the expiry date to the opened shelf life
+// @Tags food
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Param request body service.OpenFoodRequest false "When the food was opened"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/foods/{id}/open [post]
+func (h *FoodHandler) OpenFood(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid food ID"))
+		return
+	}
+
+	// The body is optional, opening now needs none
+	var req service.OpenFoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	food, err := h.foodService.OpenFood(userID, id, &req)
+	if err != nil {
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Food opened successfully", food))
+}
+
+// MoveFood moves a food to another storage location
+// @Summary Move food
+// @Description Moving food into or out of the freezer, or between pantry and fridge, recalculates its expiry date
+// @Tags food
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Param request body service.MoveFoodRequest true "New location"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/foods/{id}/move [post]
+func (h *FoodHandler) MoveFood(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid food ID"))
+		return
+	}
+
+	var req service.MoveFoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	food, err := h.foodService.MoveFood(userID, id, &req)
+	if err != nil {
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Food moved successfully", food))
+}
+
+// GetFoodEvents lists the state changes of a food and how they changed its expiry date
+// @Summary Get food history
+// @Tags food
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/foods/{id}/events [get]
+func (h *FoodHandler) GetFoodEvents(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid food ID"))
+		return
+	}
+
+	events, err := h.foodService.GetFoodEvents(userID, id)
+	if err != nil {
+		c.JSON(foodErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Food history retrieved successfully", events))
+}
+
 // SeedDummyFoods creates dummy food data for the authenticated user
 // @Summary Seed dummy foods (Development only)
 // @Tags food
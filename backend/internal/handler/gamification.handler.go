@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type GamificationHandler struct {
+	gamificationService *service.GamificationService
+}
+
+func NewGamificationHandler(gamificationService *service.GamificationService) *GamificationHandler {
+	return &GamificationHandler{
+		gamificationService: gamificationService,
+	}
+}
+
+// GetAchievements lists all achievements with the user's progress
+// @Summary Get achievements
+// @Tags rewards
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/rewards/achievements [get]
+func (h *GamificationHandler) GetAchievements(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	achievements, err := h.gamificationService.GetAchievements(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Achievements retrieved successfully", achievements))
+}
+
+// GetStreaks gets the user's daily and no-waste streaks
+// @Summary Get streaks
+// @Tags rewards
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/rewards/streaks [get]
+func (h *GamificationHandler) GetStreaks(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	streaks, err := h.gamificationService.GetStreaks(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Streaks retrieved successfully", streaks))
+}
+
+// GetLeaderboard ranks users by points earned in the current week or month
+// @Summary Get leaderboard
+// @Tags rewards
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "weekly or monthly" default(weekly)
+// @Param scope query string false "global or household" default(global)
+// @Param limit query int false "Number of entries" default(20)
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/rewards/leaderboard [get]
+func (h *GamificationHandler) GetLeaderboard(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	period := c.DefaultQuery("period", service.LeaderboardWeekly)
+	scope := c.DefaultQuery("scope", service.LeaderboardGlobal)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	leaderboard, err := h.gamificationService.GetLeaderboard(userID, period, scope, limit)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "invalid period, use weekly or monthly", "invalid scope, use global or household":
+			statusCode = http.StatusBadRequest
+		case "you are not in a household":
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Leaderboard retrieved successfully", leaderboard))
+}
+
+// UpdateLeaderboardPrivacy hides or shows the user on leaderboards
+// @Summary Update leaderboard privacy
+// @Tags rewards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.LeaderboardPrivacyRequest true "Opt out of leaderboards"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/rewards/leaderboard/privacy [put]
+func (h *GamificationHandler) UpdateLeaderboardPrivacy(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.LeaderboardPrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.gamificationService.SetLeaderboardOptOut(userID, *req.OptOut); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Leaderboard privacy updated successfully", gin.H{
+		"opt_out": *req.OptOut,
+	}))
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type HouseholdHandler struct {
+	householdService *service.HouseholdService
+}
+
+func NewHouseholdHandler(householdService *service.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{
+		householdService: householdService,
+	}
+}
+
+// householdErrorStatus maps household errors to status codes
+func householdErrorStatus(err error) int {
+	switch err.Error() {
+	case "you are already in a household":
+		return http.StatusConflict
+	case "you are not in a household", "household not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetMyHousehold gets the user's household and its members
+// @Summary Get my household
+// @Tags households
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/households/me [get]
+func (h *HouseholdHandler) GetMyHousehold(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	household, err := h.householdService.GetMyHousehold(userID)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Household retrieved successfully", household))
+}
+
+// CreateHousehold creates a household with the user as owner
+// @Summary Create household
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateHouseholdRequest true "Household name"
+// @Success 201 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/households [post]
+func (h *HouseholdHandler) CreateHousehold(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.CreateHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	household, err := h.householdService.CreateHousehold(userID, &req)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse("Household created successfully", household))
+}
+
+// JoinHousehold joins a household with an invite code
+// @Summary Join household
+// @Tags households
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.JoinHouseholdRequest true "Invite code"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/households/join [post]
+func (h *HouseholdHandler) JoinHousehold(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.JoinHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	household, err := h.householdService.JoinHousehold(userID, &req)
+	if err != nil {
+		c.JSON(householdErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Joined household successfully", household))
+}
+
+// LeaveHousehold leaves the user's household
+// @Summary Leave household
+// @Tags households
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/households/leave [post]
+func (h *HouseholdHandler) LeaveHousehold(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	if err := h.householdService.LeaveHousehold(userID); err != nil {
+		c.JSON(householdErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Left household successfully", nil))
+}
@@ -3,8 +3,10 @@ package handler
 import (
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 	"github.com/varel183/MakanSikScan/backend/internal/service"
 	"github.com/varel183/MakanSikScan/backend/internal/utils"
@@ -20,11 +22,14 @@ func NewNotificationHandler(notificationService *service.NotificationService) *N
 	}
 }
 
-// GetNotifications retrieves all notifications for a user
+// GetNotifications retrieves the notification inbox for a user
 // @Summary Get all notifications
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
+// @Param status query string false "Filter by status" Enums(all, read, unread) default(all)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} utils.Response
 // @Router /api/v1/notifications [get]
 func (h *NotificationHandler) GetNotifications(c *gin.Context) {
@@ -34,23 +39,33 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 		return
 	}
 
-	notifications, err := h.notificationService.GetUserNotifications(userID)
+	status := c.DefaultQuery("status", "all")
+	if status != "all" && status != "read" && status != "unread" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid status, must be one of: all, read, unread"))
+		return
+	}
+
+	page, limit := notificationPagination(c)
+
+	notifications, total, err := h.notificationService.GetUserNotifications(userID, status, page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.SuccessResponse("Notifications retrieved successfully", gin.H{
-		"count": len(notifications),
+	c.JSON(http.StatusOK, utils.PaginatedSuccessResponse("Notifications retrieved successfully", gin.H{
+		"count":         len(notifications),
 		"notifications": notifications,
-	}))
+	}, page, limit, total))
 }
 
-// GetExpiringNotifications retrieves only expiring and expired notifications
+// GetExpiringNotifications retrieves only unread expiring and expired notifications
 // @Summary Get expiring notifications
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} utils.Response
 // @Router /api/v1/notifications/expiring [get]
 func (h *NotificationHandler) GetExpiringNotifications(c *gin.Context) {
@@ -62,18 +77,45 @@ func (h *NotificationHandler) GetExpiringNotifications(c *gin.Context) {
 
 	log.Printf("🔔 Getting expiring notifications for user: %s", userID)
 
-	notifications, err := h.notificationService.GetExpiringNotifications(userID)
+	page, limit := notificationPagination(c)
+
+	notifications, total, err := h.notificationService.GetExpiringNotifications(userID, page, limit)
 	if err != nil {
 		log.Printf("❌ Failed to get notifications: %v", err)
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
 		return
 	}
 
-	log.Printf("✅ Found %d unread notifications", len(notifications))
+	log.Printf("✅ Found %d unread notifications", total)
 
-	c.JSON(http.StatusOK, utils.SuccessResponse("Expiring notifications retrieved successfully", gin.H{
-		"count": len(notifications),
+	c.JSON(http.StatusOK, utils.PaginatedSuccessResponse("Expiring notifications retrieved successfully", gin.H{
+		"count":         len(notifications),
 		"notifications": notifications,
+	}, page, limit, total))
+}
+
+// GetUnreadCount retrieves the number of unread notifications
+// @Summary Get unread notification count
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	count, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Unread count retrieved successfully", gin.H{
+		"unread_count": count,
 	}))
 }
 
@@ -104,10 +146,167 @@ func (h *NotificationHandler) MarkNotificationAsRead(c *gin.Context) {
 	err = h.notificationService.MarkNotificationAsRead(userID, notificationID)
 	if err != nil {
 		log.Printf("❌ Failed to mark notification as read: %v", err)
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		c.JSON(notificationErrorStatus(err), utils.ErrorResponse(err.Error()))
 		return
 	}
 
 	log.Printf("✅ Notification marked as read successfully")
 	c.JSON(http.StatusOK, utils.SuccessResponse("Notification marked as read", nil))
 }
+
+// MarkNotificationAsUnread marks a notification as unread
+// @Summary Mark notification as unread
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/{id}/unread [post]
+func (h *NotificationHandler) MarkNotificationAsUnread(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid notification ID"))
+		return
+	}
+
+	if err := h.notificationService.MarkNotificationAsUnread(userID, notificationID); err != nil {
+		c.JSON(notificationErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification marked as unread", nil))
+}
+
+// MarkAllAsRead marks all notifications as read
+// @Summary Mark all notifications as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllAsRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("All notifications marked as read", gin.H{
+		"updated": updated,
+	}))
+}
+
+// DismissNotification removes a notification from the inbox
+// @Summary Dismiss notification
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/{id} [delete]
+func (h *NotificationHandler) DismissNotification(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid notification ID"))
+		return
+	}
+
+	if err := h.notificationService.DismissNotification(userID, notificationID); err != nil {
+		c.JSON(notificationErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification dismissed", nil))
+}
+
+// GetPreferences retrieves the user's notification preferences
+// @Summary Get notification preferences
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification preferences retrieved successfully", prefs))
+}
+
+// UpdatePreferences updates the user's notification preferences
+// @Summary Update notification preferences
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.UpdateNotificationPreferencesRequest true "Preferences to change"
+// @Success 200 {object} utils.Response
+// @Router /api/v1/notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	var req service.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification preferences updated successfully", prefs))
+}
+
+func notificationPagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func notificationErrorStatus(err error) int {
+	if err.Error() == "notification not found" {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type PointRuleHandler struct {
+	rewardService *service.RewardService
+}
+
+func NewPointRuleHandler(rewardService *service.RewardService) *PointRuleHandler {
+	return &PointRuleHandler{
+		rewardService: rewardService,
+	}
+}
+
+// pointRuleErrorStatus maps point rule errors to status codes
+func pointRuleErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "point rule not found":
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "invalid action"), msg == "ends_at must be after starts_at",
+		msg == "multiplier rules need a multiplier above 0":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetPointRules lists point earning rules
+// @Summary List point rules
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param action query string false "Filter by action (food_save, donation, journal_entry)"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/point-rules [get]
+func (h *PointRuleHandler) GetPointRules(c *gin.Context) {
+	rules, err := h.rewardService.GetPointRules(c.Query("action"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Point rules retrieved successfully", rules))
+}
+
+// CreatePointRule creates a point earning rule
+// @Summary Create point rule
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.PointRuleRequest true "Point rule"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/point-rules [post]
+func (h *PointRuleHandler) CreatePointRule(c *gin.Context) {
+	var req service.PointRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	rule, err := h.rewardService.CreatePointRule(&req)
+	if err != nil {
+		c.JSON(pointRuleErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse("Point rule created successfully", rule))
+}
+
+// UpdatePointRule replaces a point earning rule
+// @Summary Update point rule
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Point rule ID"
+// @Param request body service.PointRuleRequest true "Point rule"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/point-rules/{id} [put]
+func (h *PointRuleHandler) UpdatePointRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid point rule ID"))
+		return
+	}
+
+	var req service.PointRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	rule, err := h.rewardService.UpdatePointRule(id, &req)
+	if err != nil {
+		c.JSON(pointRuleErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Point rule updated successfully", rule))
+}
+
+// DeletePointRule deletes a point earning rule
+// @Summary Delete point rule
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Point rule ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/point-rules/{id} [delete]
+func (h *PointRuleHandler) DeletePointRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid point rule ID"))
+		return
+	}
+
+	if err := h.rewardService.DeletePointRule(id); err != nil {
+		c.JSON(pointRuleErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Point rule deleted successfully", nil))
+}
@@ -96,21 +96,21 @@ func (h *RecipeHandler) GetYummyRecipes(c *gin.Context) {
 			return
 		}
 
-		// Extract food names
-		foodNames := make([]string, 0)
+		// Foods in stock with their quantities
+		pantry := make([]service.PantryItem, 0)
 		for _, food := range foods {
 			if food.Quantity > 0 { // Only include foods in stock
-				foodNames = append(foodNames, food.Name)
+				pantry = append(pantry, service.PantryItem{Name: food.Name, Quantity: food.Quantity, Unit: food.Unit})
 			}
 		}
 
-		if len(foodNames) == 0 {
+		if len(pantry) == 0 {
 			c.JSON(http.StatusOK, utils.SuccessResponse("No foods in storage to match", []map[string]interface{}{}))
 			return
 		}
 
 		// Fetch recipes with ingredient matching
-		recipes, err = h.yummyService.FetchRecipesWithIngredientMatch(limit, foodNames)
+		recipes, err = h.yummyService.FetchRecipesWithIngredientMatch(limit, pantry)
 	} else {
 		// Regular fetch without matching
 		recipes, err = h.yummyService.FetchRecipes(limit)
@@ -377,7 +377,7 @@ func (h *RecipeHandler) ImportFromYummy(c *gin.Context) {
 		return
 	}
 
-	recipe, err := h.yummyService.ImportRecipeFromYummy(slug)
+	recipe, err := h.yummyService.ImportRecipeFromYummy(c.Request.Context(), slug)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
 		return
@@ -401,7 +401,7 @@ func (h *RecipeHandler) ImportMultipleFromYummy(c *gin.Context) {
 		limit = 50 // Maximum 50 recipes at once
 	}
 
-	recipes, err := h.yummyService.ImportMultipleRecipes(limit)
+	recipes, err := h.yummyService.ImportMultipleRecipes(c.Request.Context(), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
 		return
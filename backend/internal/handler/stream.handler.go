@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+// Comment lines sent on idle streams so proxies keep the connection open
+const streamHeartbeatInterval = 25 * time.Second
+
+type StreamHandler struct {
+	eventHub *service.EventHub
+}
+
+func NewStreamHandler(eventHub *service.EventHub) *StreamHandler {
+	return &StreamHandler{
+		eventHub: eventHub,
+	}
+}
+
+// Stream pushes the user's food, cart, order and notification events as Server-Sent Events
+// @Summary Real-time event stream
+// @Tags stream
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "Resume after this event ID"
+// @Success 200 {string} string "text/event-stream"
+// @Router /api/v1/stream [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	// EventSource sends the header on reconnect, the query param helps clients that can't
+	lastEventIDStr := c.GetHeader("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = c.Query("last_event_id")
+	}
+	var lastEventID uint64
+	if lastEventIDStr != "" {
+		lastEventID, err = strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid Last-Event-ID"))
+			return
+		}
+	}
+
+	sub, missed := h.eventHub.Subscribe(userID, lastEventID)
+	defer h.eventHub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	for _, event := range missed {
+		if err := writeSSEEvent(c.Writer, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Hub closed the stream, the client reconnects and replays from its last ID
+				return
+			}
+			if err := writeSSEEvent(c.Writer, event); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeSSEEvent(w io.Writer, event service.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
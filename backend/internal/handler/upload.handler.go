@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type UploadHandler struct {
+	uploadService *service.UploadService
+	localStore    *service.LocalBlobStore // nil unless files are stored locally
+	maxSize       int64
+}
+
+func NewUploadHandler(uploadService *service.UploadService, localStore *service.LocalBlobStore, maxSize int64) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		localStore:    localStore,
+		maxSize:       maxSize,
+	}
+}
+
+// uploadErrorStatus maps upload errors to status codes
+func uploadErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "file is too large"), msg == "image dimensions are too large":
+		return http.StatusRequestEntityTooLarge
+	case strings.HasPrefix(msg, "unsupported image type"):
+		return http.StatusUnsupportedMediaType
+	case strings.HasPrefix(msg, "invalid purpose"), msg == "file is empty", msg == "invalid image":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Upload stores an image
+// @Summary Upload image
+// @Description Stores a JPEG, PNG or GIF image with a thumbnail. Send the returned ref as image_url or avatar.
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Image"
+// @Param purpose formData string false "food, avatar or scan (default food)"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 413 {object} utils.Response
+// @Failure 415 {object} utils.Response
+// @Router /api/v1/uploads [post]
+func (h *UploadHandler) Upload(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+64*1024)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, utils.ErrorResponse("File is too large"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	upload, err := h.uploadService.Upload(c.Request.Context(), userID, c.PostForm("purpose"), data)
+	if err != nil {
+		c.JSON(uploadErrorStatus(err), utils.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse("Image uploaded successfully", upload))
+}
+
+// ServeFile serves a file of the local blob store through a signed URL
+// @Summary Download uploaded file
+// @Tags uploads
+// @Produce image/jpeg,image/png,image/gif
+// @Param key path string true "File key"
+// @Param expires query string true "Expiry (unix seconds)"
+// @Param signature query string true "Signature"
+// @Success 200 {file} file
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/uploads/files/{key} [get]
+func (h *UploadHandler) ServeFile(c *gin.Context) {
+	if h.localStore == nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("File not found"))
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !h.localStore.Verify(key, c.Query("expires"), c.Query("signature"), time.Now()) {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse("Invalid or expired link"))
+		return
+	}
+
+	file, info, err := h.localStore.Open(key)
+	if err != nil {
+		if errors.Is(err, service.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, utils.ErrorResponse("File not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	// Stored files never change, so they can be cached as long as the link is valid
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(key), info.ModTime(), file)
+}
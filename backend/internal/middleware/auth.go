@@ -30,17 +30,19 @@ func AuthMiddleware(jwtConfig *config.JWTConfig) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		// Parse and validate token
-		userID, err := utils.ParseJWT(tokenString, jwtConfig.Secret)
+		// Parse and validate token, including its session
+		claims, err := utils.ParseJWTClaims(tokenString, jwtConfig.Secret)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Invalid or expired token"))
 			c.Abort()
 			return
 		}
+		userID := claims.UserID
 
 		// Set userID in context (both UUID and string format)
 		c.Set("userID", userID)
 		c.Set("user_id", userID.String()) // Add string format for donation handlers
+		c.Set("sessionID", claims.SessionID)
 		c.Next()
 	}
 }
@@ -54,6 +56,15 @@ func GetUserID(c *gin.Context) (uuid.UUID, error) {
 	return value.(uuid.UUID), nil
 }
 
+// GetSessionID retrieves the session ID of the access token, uuid.Nil for legacy tokens
+func GetSessionID(c *gin.Context) uuid.UUID {
+	value, exists := c.Get("sessionID")
+	if !exists {
+		return uuid.Nil
+	}
+	return value.(uuid.UUID)
+}
+
 // GetUserIDString retrieves user ID from context as string
 func GetUserIDString(c *gin.Context) (string, error) {
 	value, exists := c.Get("user_id")
@@ -62,3 +73,27 @@ func GetUserIDString(c *gin.Context) (string, error) {
 	}
 	return value.(string), nil
 }
+
+// AdminChecker reports whether a user has the admin role
+type AdminChecker func(userID uuid.UUID) (bool, error)
+
+// RequireAdmin rejects users without the admin role, use after AuthMiddleware.
+// The role is looked up on every request so revoking it takes effect immediately.
+func RequireAdmin(isAdmin AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := GetUserID(c)
+		if err != nil || userID == uuid.Nil {
+			c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		admin, err := isAdmin(userID)
+		if err != nil || !admin {
+			c.JSON(http.StatusForbidden, utils.ErrorResponse("Admin access required"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
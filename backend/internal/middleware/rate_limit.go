@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+// RateLimitMiddleware limits requests with a token bucket per client.
+// Authenticated requests are keyed by user ID, anonymous ones by client IP,
+// so it must run after AuthMiddleware on protected routes.
+func RateLimitMiddleware(rule config.RateLimitRule) gin.HandlerFunc {
+	limiter := newRateLimiter(rule)
+
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if value, exists := c.Get("userID"); exists {
+			if userID, ok := value.(uuid.UUID); ok && userID != uuid.Nil {
+				key = "user:" + userID.String()
+			}
+		}
+
+		allowed, remaining, retryAfter := limiter.allow(key)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rule.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, utils.ErrorResponse("Too many requests, please try again later"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+type rateLimiter struct {
+	mu        sync.Mutex
+	capacity  float64
+	rate      float64 // tokens per second
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(rule config.RateLimitRule) *rateLimiter {
+	return &rateLimiter{
+		capacity:  float64(rule.Requests),
+		rate:      float64(rule.Requests) / rule.Period.Seconds(),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token for key, reporting the tokens left or how long to wait for the next one
+func (l *rateLimiter) allow(key string) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.capacity, lastSeen: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(l.capacity, bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*l.rate)
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, 0, wait
+	}
+
+	bucket.tokens--
+	return true, int(bucket.tokens), 0
+}
+
+// sweep drops buckets that have refilled completely, they behave like new ones
+func (l *rateLimiter) sweep(now time.Time) {
+	fullAfter := time.Duration(l.capacity / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < fullAfter {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= fullAfter {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Device represents a mobile device registered for push notifications
+type Device struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Platform   string    `gorm:"type:varchar(20);not null" json:"platform"` // ios, android, web
+	Token      string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"token"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// PushDelivery records a push sent to a user, used for retries and deduplication
+type PushDelivery struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_push_deliveries_user_dedup" json:"user_id"`
+	DedupKey  string     `gorm:"type:varchar(200);not null;uniqueIndex:idx_push_deliveries_user_dedup" json:"dedup_key"`
+	Title     string     `gorm:"not null" json:"title"`
+	Body      string     `gorm:"type:text" json:"body"`
+	Data      string     `gorm:"type:jsonb" json:"data"`
+	Status    string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"` // pending, deferred, sent, failed, rejected
+	Attempts  int        `gorm:"default:0" json:"attempts"`
+	LastError string     `gorm:"type:text" json:"last_error"`
+	SentAt    *time.Time `json:"sent_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// QuietHours defines when a user must not receive push notifications
+type QuietHours struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Enabled   bool      `gorm:"default:false" json:"enabled"`
+	StartTime string    `gorm:"type:varchar(5);default:'22:00'" json:"start_time"` // HH:MM
+	EndTime   string    `gorm:"type:varchar(5);default:'07:00'" json:"end_time"`   // HH:MM
+	Timezone  string    `gorm:"type:varchar(50);default:'Asia/Jakarta'" json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (d *Device) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.LastSeenAt.IsZero() {
+		d.LastSeenAt = time.Now()
+	}
+	return nil
+}
+
+func (pd *PushDelivery) BeforeCreate(tx *gorm.DB) error {
+	if pd.ID == uuid.Nil {
+		pd.ID = uuid.New()
+	}
+	if pd.Data == "" {
+		pd.Data = "{}"
+	}
+	return nil
+}
+
+func (q *QuietHours) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Device) TableName() string {
+	return "devices"
+}
+
+func (PushDelivery) TableName() string {
+	return "push_deliveries"
+}
+
+func (QuietHours) TableName() string {
+	return "quiet_hours"
+}
+
+// IsActiveAt reports whether the quiet window covers the given moment
+func (q *QuietHours) IsActiveAt(t time.Time) bool {
+	if q == nil || !q.Enabled {
+		return false
+	}
+
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	start, err1 := time.Parse("15:04", q.StartTime)
+	end, err2 := time.Parse("15:04", q.EndTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	switch {
+	case from == to:
+		return false
+	case from < to:
+		return now >= from && now < to
+	default:
+		// Window wraps around midnight, e.g. 22:00 - 07:00
+		return now >= from || now < to
+	}
+}
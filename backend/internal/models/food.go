@@ -18,8 +18,9 @@ type Food struct {
 	Unit            string     `gorm:"not null;default:'pcs'" json:"unit"`
 	ImageURL        string     `json:"image_url"`
 	PurchaseDate    *time.Time `json:"purchase_date"`
-	ExpiryDate      *time.Time `json:"expiry_date"`
-	Location        string     `json:"location"` // upper, middle, lower, freezer
+	ExpiryDate      *time.Time `json:"expiry_date"` // nearest expiry of the lots in stock
+	OpenedAt        *time.Time `json:"opened_at"`   // when the opened lot in stock was opened, nil while all are sealed
+	Location        string     `json:"location"`    // free text, e.g. "Refrigerator - Top shelf", mapped to pantry, fridge or freezer
 	IsHalal         bool       `gorm:"default:true" json:"is_halal"`
 	Barcode         string     `json:"barcode"`
 
@@ -37,7 +38,8 @@ type Food struct {
 	UpdatedAt time.Time `json:"updated_at"`
 
 	// Relations
-	User User `gorm:"foreignKey:UserID" json:"-"`
+	User User      `gorm:"foreignKey:UserID" json:"-"`
+	Lots []FoodLot `gorm:"foreignKey:FoodID" json:"lots,omitempty"` // loaded on demand
 }
 
 func (f *Food) BeforeCreate(tx *gorm.DB) error {
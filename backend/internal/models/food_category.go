@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// Canonical food categories
+const (
+	CategoryVegetable = "Vegetable"
+	CategoryFruit     = "Fruit"
+	CategoryMeat      = "Meat"
+	CategoryFish      = "Fish"
+	CategoryDairy     = "Dairy"
+	CategoryGrain     = "Grain"
+	CategoryFrozen    = "Frozen"
+	CategoryCanned    = "Canned"
+	CategoryBeverage  = "Beverage"
+	CategorySnack     = "Snack"
+	CategoryOther     = "Other"
+)
+
+// FoodCategories is the category taxonomy, in the order shown to users
+var FoodCategories = []string{
+	CategoryVegetable, CategoryFruit, CategoryMeat, CategoryFish, CategoryDairy, CategoryGrain,
+	CategoryFrozen, CategoryCanned, CategoryBeverage, CategorySnack, CategoryOther,
+}
+
+// foodCategoryAliases maps plurals, related names and Indonesian names to a category
+var foodCategoryAliases = map[string]string{
+	"vegetables": CategoryVegetable, "veggies": CategoryVegetable, "sayur": CategoryVegetable, "sayuran": CategoryVegetable,
+	"fruits": CategoryFruit, "buah": CategoryFruit, "buah-buahan": CategoryFruit,
+	"meats": CategoryMeat, "poultry": CategoryMeat, "chicken": CategoryMeat, "beef": CategoryMeat, "daging": CategoryMeat, "ayam": CategoryMeat,
+	"seafood": CategoryFish, "fish & seafood": CategoryFish, "ikan": CategoryFish, "makanan laut": CategoryFish,
+	"milk": CategoryDairy, "dairy products": CategoryDairy, "eggs": CategoryDairy, "susu": CategoryDairy, "telur": CategoryDairy,
+	"grains": CategoryGrain, "cereal": CategoryGrain, "cereals": CategoryGrain, "bakery": CategoryGrain, "bread": CategoryGrain,
+	"rice": CategoryGrain, "pasta": CategoryGrain, "noodles": CategoryGrain, "beras": CategoryGrain, "nasi": CategoryGrain, "roti": CategoryGrain, "mie": CategoryGrain,
+	"frozen food": CategoryFrozen, "frozen foods": CategoryFrozen, "makanan beku": CategoryFrozen,
+	"canned food": CategoryCanned, "canned goods": CategoryCanned, "kalengan": CategoryCanned, "makanan kaleng": CategoryCanned,
+	"beverages": CategoryBeverage, "drink": CategoryBeverage, "drinks": CategoryBeverage, "minuman": CategoryBeverage,
+	"snacks": CategorySnack, "camilan": CategorySnack, "cemilan": CategorySnack, "makanan ringan": CategorySnack,
+	"others": CategoryOther, "lainnya": CategoryOther,
+}
+
+// NormalizeFoodCategory returns the canonical category of a name, case-insensitively.
+// Unknown names return CategoryOther and false.
+func NormalizeFoodCategory(category string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(category))
+	for _, canonical := range FoodCategories {
+		if strings.ToLower(canonical) == key {
+			return canonical, true
+		}
+	}
+	if canonical, ok := foodCategoryAliases[key]; ok {
+		return canonical, true
+	}
+	return CategoryOther, false
+}
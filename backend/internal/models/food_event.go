@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Food state transitions that change the expiry date
+const (
+	FoodEventOpened = "opened" // sealed -> opened
+	FoodEventFrozen = "frozen" // moved into the freezer
+	FoodEventThawed = "thawed" // moved out of the freezer
+	FoodEventMoved  = "moved"  // moved between pantry and fridge
+)
+
+// FoodEvent records a state transition of a food and how it changed the expiry date,
+// so notifications and waste reports can explain why the food expired when it did
+type FoodEvent struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	FoodID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"food_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type           string     `gorm:"type:varchar(20);not null" json:"type"` // opened, frozen, thawed, moved
+	FromLocation   string     `json:"from_location"`
+	ToLocation     string     `json:"to_location"`
+	FromZone       string     `gorm:"type:varchar(20)" json:"from_zone"` // pantry, fridge, freezer
+	ToZone         string     `gorm:"type:varchar(20)" json:"to_zone"`
+	PreviousExpiry *time.Time `json:"previous_expiry"`
+	NewExpiry      *time.Time `json:"new_expiry"`
+	Reason         string     `gorm:"type:text" json:"reason"` // e.g. "Opened, keeps 4 days in the fridge"
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (e *FoodEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FoodLot is one purchase of a food with its own expiry date.
+// Stock is used up from the lot expiring first.
+type FoodLot struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	FoodID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"food_id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Quantity        float64    `gorm:"not null;default:0" json:"quantity"` // remaining
+	InitialQuantity float64    `gorm:"not null;default:0" json:"initial_quantity"`
+	PurchaseDate    *time.Time `json:"purchase_date"`
+	ExpiryDate      *time.Time `json:"expiry_date"`
+	OpenedAt        *time.Time `json:"opened_at"`                                       // nil while sealed
+	Source          string     `gorm:"type:varchar(20);default:'manual'" json:"source"` // manual, scan, barcode, restock, adjustment, transaction, order
+	SourceID        *uuid.UUID `gorm:"type:uuid" json:"source_id"`                      // transaction or order the lot was bought with
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (l *FoodLot) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// AddLot adds stock to a food as a new lot
+func (f *Food) AddLot(lot FoodLot) {
+	lot.FoodID = f.ID
+	lot.UserID = f.UserID
+	if lot.InitialQuantity == 0 {
+		lot.InitialQuantity = lot.Quantity
+	}
+	f.Lots = append(f.Lots, lot)
+	f.Quantity += lot.Quantity
+	f.RefreshExpiry()
+}
+
+// Consume takes a quantity from the lots expiring first (first-expiring-first-out).
+// The food's quantity is authoritative, foods from before lots existed may have none.
+func (f *Food) Consume(quantity float64) {
+	f.Quantity -= quantity
+	if f.Quantity < 0 {
+		f.Quantity = 0
+	}
+
+	f.sortLots()
+	for i := range f.Lots {
+		if quantity <= 0 {
+			break
+		}
+		take := f.Lots[i].Quantity
+		if take > quantity {
+			take = quantity
+		}
+		f.Lots[i].Quantity -= take
+		quantity -= take
+	}
+	f.RefreshExpiry()
+}
+
+// NearestLot returns the lot in stock expiring first, nil when there is none
+func (f *Food) NearestLot() *FoodLot {
+	f.sortLots()
+	for i := range f.Lots {
+		if f.Lots[i].Quantity > 0 {
+			return &f.Lots[i]
+		}
+	}
+	return nil
+}
+
+// RefreshExpiry sets the expiry date to the nearest expiry of the lots in stock.
+// It is kept when no lot in stock has one, e.g. once everything was used.
+// OpenedAt follows the earliest opened lot in stock, nil when they are all sealed.
+func (f *Food) RefreshExpiry() {
+	var nearest, opened *time.Time
+	inStock := false
+	for _, lot := range f.Lots {
+		if lot.Quantity <= 0 {
+			continue
+		}
+		inStock = true
+		if lot.ExpiryDate != nil && (nearest == nil || lot.ExpiryDate.Before(*nearest)) {
+			nearest = lot.ExpiryDate
+		}
+		if lot.OpenedAt != nil && (opened == nil || lot.OpenedAt.Before(*opened)) {
+			opened = lot.OpenedAt
+		}
+	}
+	if nearest != nil {
+		expiry := *nearest
+		f.ExpiryDate = &expiry
+	}
+	if inStock {
+		if opened != nil {
+			openedAt := *opened
+			opened = &openedAt
+		}
+		f.OpenedAt = opened
+	}
+}
+
+// sortLots orders the lots by expiry, lots without one last, then by age
+func (f *Food) sortLots() {
+	sort.SliceStable(f.Lots, func(i, j int) bool {
+		a, b := f.Lots[i], f.Lots[j]
+		switch {
+		case a.ExpiryDate == nil || b.ExpiryDate == nil:
+			if (a.ExpiryDate == nil) != (b.ExpiryDate == nil) {
+				return b.ExpiryDate == nil
+			}
+		case !a.ExpiryDate.Equal(*b.ExpiryDate):
+			return a.ExpiryDate.Before(*b.ExpiryDate)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Activity types recorded for achievements and streaks
+const (
+	ActivityFoodSaved    = "food_saved"
+	ActivityFoodScanned  = "food_scanned"
+	ActivityFoodConsumed = "food_consumed"
+	ActivityDonation     = "donation"
+)
+
+// Streak kinds
+const (
+	StreakDaily   = "daily"    // days in a row with any activity
+	StreakNoWaste = "no_waste" // days in a row without food expiring unused
+)
+
+// UserActivity is a domain event the achievements engine counts
+type UserActivity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_user_activities_user_type" json:"user_id"`
+	Type        string     `gorm:"type:varchar(50);not null;index:idx_user_activities_user_type" json:"type"`
+	ReferenceID *uuid.UUID `gorm:"type:uuid" json:"reference_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// UserStreak tracks consecutive days of one kind
+type UserStreak struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_user_streaks_user_kind" json:"user_id"`
+	Kind      string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_user_streaks_user_kind" json:"kind"`
+	Current   int        `gorm:"default:0" json:"current"`
+	Longest   int        `gorm:"default:0" json:"longest"`
+	LastDate  *time.Time `gorm:"type:date" json:"last_date"` // last day counted
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// UserAchievement is a badge a user unlocked, awarded once
+type UserAchievement struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_achievements_user_code" json:"user_id"`
+	Code        string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_achievements_user_code" json:"code"`
+	BonusPoints int       `gorm:"default:0" json:"bonus_points"`
+	AwardedAt   time.Time `json:"awarded_at"`
+}
+
+func (a *UserActivity) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+func (s *UserStreak) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+func (a *UserAchievement) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
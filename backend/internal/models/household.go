@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Household groups users sharing a kitchen, used for household leaderboards
+type Household struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
+	InviteCode string    `gorm:"type:varchar(20);uniqueIndex;not null" json:"invite_code"`
+	OwnerID    uuid.UUID `gorm:"type:uuid;not null" json:"owner_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relations
+	Members []HouseholdMember `gorm:"foreignKey:HouseholdID" json:"members,omitempty"`
+}
+
+// HouseholdMember links a user to their household, a user belongs to at most one
+type HouseholdMember struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HouseholdID uuid.UUID `gorm:"type:uuid;not null;index" json:"household_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	JoinedAt    time.Time `gorm:"autoCreateTime" json:"joined_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (h *Household) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+func (m *HouseholdMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Job run statuses
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobLease makes sure only one instance runs each scheduled slot of a job
+type JobLease struct {
+	Name             string    `gorm:"type:varchar(100);primary_key" json:"name"`
+	Owner            string    `gorm:"type:varchar(255);not null" json:"owner"` // instance holding the lease
+	LockedUntil      time.Time `gorm:"not null" json:"locked_until"`
+	LastScheduledFor time.Time `gorm:"not null" json:"last_scheduled_for"` // latest slot claimed by any instance
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (JobLease) TableName() string {
+	return "job_leases"
+}
+
+// JobRun records one execution of a scheduled job
+type JobRun struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	JobName      string     `gorm:"type:varchar(100);not null;index" json:"job_name"`
+	Instance     string     `gorm:"type:varchar(255);not null" json:"instance"`
+	ScheduledFor time.Time  `gorm:"not null" json:"scheduled_for"`
+	StartedAt    time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	Status       string     `gorm:"type:varchar(20);not null;default:'running'" json:"status"` // running, succeeded, failed
+	Result       string     `gorm:"type:text" json:"result"`
+	Error        string     `gorm:"type:text" json:"error"`
+}
+
+func (r *JobRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+func (JobRun) TableName() string {
+	return "job_runs"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification represents a persisted inbox entry for a user
+type Notification struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_notifications_user_dedup" json:"user_id"`
+	Type          string     `gorm:"type:varchar(50);not null;index" json:"type"` // expiring_soon, expired, low_stock, order_ready, voucher_expiring
+	Title         string     `gorm:"not null" json:"title"`
+	Message       string     `gorm:"type:text" json:"message"`
+	Severity      string     `gorm:"type:varchar(20);default:'info'" json:"severity"` // info, warning, critical
+	ReferenceID   *uuid.UUID `gorm:"type:uuid" json:"reference_id"`
+	ReferenceType string     `gorm:"type:varchar(50)" json:"reference_type"` // food, order, redemption
+	DedupKey      string     `gorm:"type:varchar(200);not null;uniqueIndex:idx_notifications_user_dedup" json:"-"`
+
+	// Snapshot of the referenced food at generation time
+	FoodName     string     `json:"food_name,omitempty"`
+	Quantity     float64    `json:"quantity,omitempty"`
+	Unit         string     `json:"unit,omitempty"`
+	ExpiryDate   *time.Time `json:"expiry_date,omitempty"`
+	DaysUntilExp *int       `json:"days_until_expiry,omitempty"`
+	ExpiryReason string     `gorm:"type:text" json:"expiry_reason,omitempty"` // why the expiry date last changed, e.g. the food was opened
+
+	IsRead      bool       `gorm:"default:false;index" json:"is_read"`
+	ReadAt      *time.Time `json:"read_at"`
+	DismissedAt *time.Time `gorm:"index" json:"dismissed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationPreference stores which notifications a user wants and when
+type NotificationPreference struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+
+	// Enabled notification types
+	ExpiringSoonEnabled    bool `gorm:"default:true" json:"expiring_soon_enabled"`
+	ExpiredEnabled         bool `gorm:"default:true" json:"expired_enabled"`
+	LowStockEnabled        bool `gorm:"default:true" json:"low_stock_enabled"`
+	OrderReadyEnabled      bool `gorm:"default:true" json:"order_ready_enabled"`
+	VoucherExpiringEnabled bool `gorm:"default:true" json:"voucher_expiring_enabled"`
+
+	// Expiry thresholds in days
+	DefaultThresholdDays int    `gorm:"default:30" json:"default_threshold_days"` // start notifying this many days before expiry
+	CriticalDays         int    `gorm:"default:3" json:"critical_days"`
+	WarningDays          int    `gorm:"default:7" json:"warning_days"`
+	CategoryThresholds   string `gorm:"type:jsonb;default:'{}'" json:"-"` // {"fish": 1, "canned": 14}
+
+	LowStockPercentage float64 `gorm:"default:20" json:"low_stock_percentage"`
+
+	// Daily digest
+	DigestEnabled bool   `gorm:"default:false" json:"digest_enabled"`
+	DigestTime    string `gorm:"type:varchar(5);default:'08:00'" json:"digest_time"` // HH:MM
+	Timezone      string `gorm:"type:varchar(50);default:'Asia/Jakarta'" json:"timezone"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (p *NotificationPreference) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.CategoryThresholds == "" {
+		p.CategoryThresholds = "{}"
+	}
+	return nil
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference returns the preferences used when a user has not saved any
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:                 userID,
+		ExpiringSoonEnabled:    true,
+		ExpiredEnabled:         true,
+		LowStockEnabled:        true,
+		OrderReadyEnabled:      true,
+		VoucherExpiringEnabled: true,
+		DefaultThresholdDays:   30,
+		CriticalDays:           3,
+		WarningDays:            7,
+		CategoryThresholds:     "{}",
+		LowStockPercentage:     20,
+		DigestEnabled:          false,
+		DigestTime:             "08:00",
+		Timezone:               "Asia/Jakarta",
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Point rule types
+const (
+	PointRuleBase       = "base"       // points an action earns
+	PointRuleMultiplier = "multiplier" // scales the base points when its conditions match
+)
+
+// PointRule decides how many points an action earns.
+// For each action the matching base rule with the highest priority applies,
+// then every matching multiplier, then the base rule's daily cap.
+type PointRule struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name   string    `gorm:"type:varchar(100);not null" json:"name"`
+	Action string    `gorm:"type:varchar(50);not null;index" json:"action"` // food_save, donation, journal_entry
+	Type   string    `gorm:"type:varchar(20);not null;default:'base'" json:"type"`
+
+	// Base rules
+	Points   int  `gorm:"default:0" json:"points"`       // per action, or per unit when PerUnit
+	PerUnit  bool `gorm:"default:false" json:"per_unit"` // e.g. per donated item
+	DailyCap int  `gorm:"default:0" json:"daily_cap"`    // max points per user per day from the action, 0 = no cap
+
+	// Multiplier rules
+	Multiplier float64 `gorm:"default:1" json:"multiplier"`
+
+	// Conditions, empty matches everything
+	Category       string     `gorm:"type:varchar(100)" json:"category"`
+	NearExpiryDays *int       `json:"near_expiry_days"` // food expiring within this many days (near-expiry rescue)
+	StartsAt       *time.Time `json:"starts_at"`        // campaign window
+	EndsAt         *time.Time `json:"ends_at"`
+
+	Priority  int       `gorm:"default:0" json:"priority"`
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *PointRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -35,6 +35,9 @@ type Recipe struct {
 	Source     string `json:"source"` // spoonacular, gemini, manual
 	SourceURL  string `json:"source_url"`
 
+	// Prompt template that generated the recipe, e.g. recipe_recommendations.v1.id
+	PromptVersion string `json:"prompt_version"`
+
 	// Dietary flags
 	IsHalal      bool `gorm:"default:false" json:"is_halal"`
 	IsVegetarian bool `gorm:"default:false" json:"is_vegetarian"`
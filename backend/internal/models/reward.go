@@ -34,6 +34,14 @@ type PointTransaction struct {
 	ReferenceType string     `gorm:"type:varchar(50)" json:"reference_type"` // food, journal, voucher
 	CreatedAt     time.Time  `json:"created_at"`
 
+	// Earn transactions are lots, spends and expiry use up the oldest lots first
+	Remaining int        `gorm:"default:0" json:"remaining"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"` // nil when points never expire
+
+	// Rule audit, nil for points not produced by a point rule (e.g. achievements)
+	PointRuleID *uuid.UUID `gorm:"type:uuid;index" json:"point_rule_id"`
+	RuleAudit   string     `gorm:"type:text" json:"rule_audit,omitempty"` // how the amount was calculated
+
 	// Relations
 	UserPoints UserPoints `gorm:"foreignKey:UserPointsID" json:"user_points,omitempty"`
 }
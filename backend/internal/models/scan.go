@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScanRecord is one food scan, what the model recognised and what the user finally saved
+type ScanRecord struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	ImageRef      string    `json:"image_ref"` // upload reference or the scanned URL
+	PromptVersion string    `json:"prompt_version"`
+	RawResult     string    `gorm:"type:jsonb" json:"raw_result"` // FoodScanResult as returned by the model
+
+	// What the model recognised, before learned aliases
+	RecognizedName     string  `gorm:"not null" json:"recognized_name"`
+	RecognizedCategory string  `json:"recognized_category"`
+	Confidence         float64 `json:"confidence"` // 0-1
+
+	// What the user was shown, after learned aliases
+	SuggestedName     string `json:"suggested_name"`
+	SuggestedCategory string `json:"suggested_category"`
+	AliasApplied      bool   `gorm:"default:false" json:"alias_applied"`
+
+	// What the user saved, empty until the scan is added to storage
+	FoodID        *uuid.UUID `gorm:"type:uuid" json:"food_id"`
+	SavedName     string     `json:"saved_name"`
+	SavedCategory string     `json:"saved_category"`
+	SavedAt       *time.Time `json:"saved_at"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (r *ScanRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// ScanAlias maps a name the model recognised to the name and category a user corrected it to.
+// It is applied to the user's later scans of the same food.
+type ScanAlias struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_scan_aliases_user_recognized" json:"user_id"`
+	RecognizedName string    `gorm:"not null;uniqueIndex:idx_scan_aliases_user_recognized" json:"recognized_name"` // lower case
+	Name           string    `gorm:"not null" json:"name"`
+	Category       string    `gorm:"not null" json:"category"`
+	TimesApplied   int       `gorm:"default:0" json:"times_applied"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (a *ScanAlias) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
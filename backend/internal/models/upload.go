@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Upload is an image stored in the blob store
+type Upload struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose      string    `gorm:"type:varchar(20);not null" json:"purpose"` // food, avatar, scan
+	Key          string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"key"`
+	ThumbnailKey string    `gorm:"type:varchar(255)" json:"thumbnail_key"`
+	ContentType  string    `gorm:"type:varchar(50);not null" json:"content_type"`
+	Size         int64     `gorm:"not null" json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (u *Upload) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
@@ -7,6 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents user account
 type User struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
@@ -15,9 +21,26 @@ type User struct {
 	Name      string    `gorm:"not null" json:"name"`
 	Phone     string    `json:"phone"`
 	Avatar    string    `json:"avatar"`
+	Role      string    `gorm:"type:varchar(20);default:'user'" json:"role"` // user, admin
+	Locale    string    `gorm:"type:varchar(5);default:'id'" json:"locale"`  // id, en; language of AI output
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Email verification
+	EmailVerified   bool       `gorm:"default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	// Login lockout
+	FailedLoginAttempts int        `gorm:"default:0" json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+
+	// Account deletion, the user is anonymised once the grace period ends
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	AnonymizedAt        *time.Time `json:"-"`
+
+	// Hidden from leaderboards when set
+	LeaderboardOptOut bool `gorm:"default:false" json:"leaderboard_opt_out"`
+
 	// Relations
 	Foods     []Food     `gorm:"foreignKey:UserID" json:"-"`
 	Donations []Donation `gorm:"foreignKey:UserID" json:"-"`
@@ -0,0 +1,143 @@
+// Package prompts holds the versioned prompt templates sent to the AI model.
+//
+// Templates are embedded from templates/<name>.v<version>.<locale>.tmpl. The
+// highest version of a prompt is the one in use, and older versions stay in the
+// tree so past results can be traced back to the exact text that produced them.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Prompt names
+const (
+	FoodScan              = "food_scan"
+	RecipeRecommendations = "recipe_recommendations"
+	NutritionAnalysis     = "nutrition_analysis"
+	TranslateToEnglish    = "translate_to_english"
+)
+
+// Supported locales
+const (
+	LocaleIndonesian = "id"
+	LocaleEnglish    = "en"
+
+	DefaultLocale = LocaleIndonesian
+)
+
+// Locales lists the supported locales
+var Locales = []string{LocaleIndonesian, LocaleEnglish}
+
+// Prompt is a rendered prompt with the version to record next to its result
+type Prompt struct {
+	Text    string
+	Version string // <name>.v<version>.<locale>, the template file it came from
+}
+
+type promptTemplate struct {
+	version  int
+	template *template.Template
+}
+
+var funcs = template.FuncMap{"join": strings.Join}
+
+// latest holds the highest version of each prompt, keyed by name then locale
+var latest = mustLoad()
+
+func mustLoad() map[string]map[string]promptTemplate {
+	loaded, err := load(templateFiles)
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
+
+func load(files fs.FS) (map[string]map[string]promptTemplate, error) {
+	paths, err := fs.Glob(files, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := map[string]map[string]promptTemplate{}
+	for _, file := range paths {
+		parts := strings.Split(strings.TrimSuffix(path.Base(file), ".tmpl"), ".")
+		if len(parts) != 3 || !strings.HasPrefix(parts[1], "v") {
+			return nil, fmt.Errorf("prompt template %s must be named <name>.v<version>.<locale>.tmpl", file)
+		}
+		name, locale := parts[0], parts[2]
+		version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("prompt template %s has an invalid version", file)
+		}
+
+		content, err := fs.ReadFile(files, file)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(path.Base(file)).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse prompt template %s: %w", file, err)
+		}
+
+		if loaded[name] == nil {
+			loaded[name] = map[string]promptTemplate{}
+		}
+		if current, ok := loaded[name][locale]; !ok || version > current.version {
+			loaded[name][locale] = promptTemplate{version: version, template: tmpl}
+		}
+	}
+	return loaded, nil
+}
+
+// NormalizeLocale maps a locale such as "id-ID" or "EN" to a supported locale.
+// Unsupported locales return DefaultLocale and false.
+func NormalizeLocale(locale string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(key, "-_"); i >= 0 {
+		key = key[:i]
+	}
+	for _, supported := range Locales {
+		if key == supported {
+			return supported, true
+		}
+	}
+	return DefaultLocale, false
+}
+
+// Render fills the latest version of a prompt in the user's locale. Prompts without
+// a variant in that locale fall back to English.
+func Render(name, locale string, data interface{}) (*Prompt, error) {
+	variants, ok := latest[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt %q", name)
+	}
+
+	locale, _ = NormalizeLocale(locale)
+	tmpl, ok := variants[locale]
+	if !ok {
+		locale = LocaleEnglish
+		if tmpl, ok = variants[locale]; !ok {
+			return nil, fmt.Errorf("prompt %q has no %s variant", name, locale)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render prompt %q: %w", name, err)
+	}
+
+	return &Prompt{
+		Text:    strings.TrimSpace(buf.String()),
+		Version: fmt.Sprintf("%s.v%d.%s", name, tmpl.version, locale),
+	}, nil
+}
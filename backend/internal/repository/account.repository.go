@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AccountData is everything stored about a user, used for data export
+type AccountData struct {
+	User                    models.User                     `json:"user"`
+	Foods                   []models.Food                   `json:"foods"`
+	Carts                   []models.Cart                   `json:"carts"`
+	Donations               []models.Donation               `json:"donations"`
+	Orders                  []models.Order                  `json:"orders"`
+	Transactions            []models.Transaction            `json:"transactions"`
+	Points                  *models.UserPoints              `json:"points"`
+	PointTransactions       []models.PointTransaction       `json:"point_transactions"`
+	Redemptions             []models.VoucherRedemption      `json:"redemptions"`
+	Notifications           []models.Notification           `json:"notifications"`
+	NotificationPreferences []models.NotificationPreference `json:"notification_preferences"`
+	Devices                 []models.Device                 `json:"devices"`
+	QuietHours              []models.QuietHours             `json:"quiet_hours"`
+	Sessions                []models.Session                `json:"sessions"`
+	Activities              []models.UserActivity           `json:"activities"`
+	Streaks                 []models.UserStreak             `json:"streaks"`
+	Achievements            []models.UserAchievement        `json:"achievements"`
+	Uploads                 []models.Upload                 `json:"uploads"`
+	ScanRecords             []models.ScanRecord             `json:"scan_records"`
+	ScanAliases             []models.ScanAlias              `json:"scan_aliases"`
+	FoodEvents              []models.FoodEvent              `json:"food_events"`
+	FoodLots                []models.FoodLot                `json:"food_lots"`
+}
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// ExportData loads all data belonging to a user
+func (r *AccountRepository) ExportData(userID uuid.UUID) (*AccountData, error) {
+	data := &AccountData{}
+
+	if err := r.db.Where("id = ?", userID).First(&data.User).Error; err != nil {
+		return nil, err
+	}
+
+	queries := []struct {
+		name string
+		dest interface{}
+		db   *gorm.DB
+	}{
+		{"foods", &data.Foods, r.db.Where("user_id = ?", userID)},
+		{"carts", &data.Carts, r.db.Where("user_id = ?", userID)},
+		{"donations", &data.Donations, r.db.Preload("Market").Where("user_id = ?", userID)},
+		{"orders", &data.Orders, r.db.Preload("Items").Where("user_id = ?", userID)},
+		{"transactions", &data.Transactions, r.db.Preload("Items").Where("user_id = ?", userID)},
+		{"redemptions", &data.Redemptions, r.db.Preload("Voucher").Where("user_id = ?", userID)},
+		{"notifications", &data.Notifications, r.db.Where("user_id = ?", userID)},
+		{"notification_preferences", &data.NotificationPreferences, r.db.Where("user_id = ?", userID)},
+		{"devices", &data.Devices, r.db.Where("user_id = ?", userID)},
+		{"quiet_hours", &data.QuietHours, r.db.Where("user_id = ?", userID)},
+		{"sessions", &data.Sessions, r.db.Where("user_id = ?", userID)},
+		{"activities", &data.Activities, r.db.Where("user_id = ?", userID)},
+		{"uploads", &data.Uploads, r.db.Where("user_id = ?", userID)},
+		{"scan_records", &data.ScanRecords, r.db.Where("user_id = ?", userID)},
+		{"scan_aliases", &data.ScanAliases, r.db.Where("user_id = ?", userID)},
+		{"food_events", &data.FoodEvents, r.db.Where("user_id = ?", userID)},
+		{"food_lots", &data.FoodLots, r.db.Where("user_id = ?", userID)},
+		{"point_transactions", &data.PointTransactions, r.db.
+			Where("user_points_id IN (?)", r.db.Model(&models.UserPoints{}).Select("id").Where("user_id = ?", userID))},
+	}
+	for _, q := range queries {
+		if err := q.db.Order("created_at ASC").Find(q.dest).Error; err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", q.name, err)
+		}
+	}
+
+	if err := r.db.Where("user_id = ?", userID).Order("kind ASC").Find(&data.Streaks).Error; err != nil {
+		return nil, fmt.Errorf("failed to export streaks: %w", err)
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("awarded_at ASC").Find(&data.Achievements).Error; err != nil {
+		return nil, fmt.Errorf("failed to export achievements: %w", err)
+	}
+
+	var points models.UserPoints
+	result := r.db.Where("user_id = ?", userID).Limit(1).Find(&points)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		data.Points = &points
+	}
+
+	return data, nil
+}
+
+// ScheduleDeletion sets or clears (nil) the time the account will be deleted
+func (r *AccountRepository) ScheduleDeletion(userID uuid.UUID, at *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("deletion_scheduled_at", at).Error
+}
+
+// FindDueForDeletion finds users whose deletion grace period has ended
+func (r *AccountRepository) FindDueForDeletion(now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.User{}).
+		Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Purge removes a user's personal data in one transaction.
+// Donations and orders are kept for merchants, so the user row and the foods
+// referenced by donations are anonymised instead of deleted.
+func (r *AccountRepository) Purge(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		userPoints := tx.Model(&models.UserPoints{}).Select("id").Where("user_id = ?", userID)
+		sessions := tx.Model(&models.Session{}).Select("id").Where("user_id = ?", userID)
+		transactions := tx.Model(&models.Transaction{}).Select("id").Where("user_id = ?", userID)
+		donatedFoods := tx.Model(&models.Donation{}).Unscoped().Select("food_id").Where("user_id = ?", userID)
+
+		// Children first so foreign keys are satisfied
+		deletes := []struct {
+			name  string
+			model interface{}
+			query *gorm.DB
+		}{
+			{"point_transactions", &models.PointTransaction{}, tx.Where("user_points_id IN (?)", userPoints)},
+			{"user_points", &models.UserPoints{}, tx.Where("user_id = ?", userID)},
+			{"refresh_tokens", &models.RefreshToken{}, tx.Where("session_id IN (?)", sessions)},
+			{"sessions", &models.Session{}, tx.Where("user_id = ?", userID)},
+			{"auth_tokens", &models.AuthToken{}, tx.Where("user_id = ?", userID)},
+			{"transaction_items", &models.TransactionItem{}, tx.Where("transaction_id IN (?)", transactions)},
+			{"transactions", &models.Transaction{}, tx.Where("user_id = ?", userID)},
+			{"voucher_redemptions", &models.VoucherRedemption{}, tx.Where("user_id = ?", userID)},
+			{"carts", &models.Cart{}, tx.Where("user_id = ?", userID)},
+			{"notifications", &models.Notification{}, tx.Where("user_id = ?", userID)},
+			{"notification_reads", &models.NotificationRead{}, tx.Where("user_id = ?", userID)},
+			{"notification_preferences", &models.NotificationPreference{}, tx.Where("user_id = ?", userID)},
+			{"push_deliveries", &models.PushDelivery{}, tx.Where("user_id = ?", userID)},
+			{"devices", &models.Device{}, tx.Where("user_id = ?", userID)},
+			{"quiet_hours", &models.QuietHours{}, tx.Where("user_id = ?", userID)},
+			{"user_activities", &models.UserActivity{}, tx.Where("user_id = ?", userID)},
+			{"user_streaks", &models.UserStreak{}, tx.Where("user_id = ?", userID)},
+			{"user_achievements", &models.UserAchievement{}, tx.Where("user_id = ?", userID)},
+			{"uploads", &models.Upload{}, tx.Where("user_id = ?", userID)},
+			{"scan_records", &models.ScanRecord{}, tx.Where("user_id = ?", userID)},
+			{"scan_aliases", &models.ScanAlias{}, tx.Where("user_id = ?", userID)},
+			{"food_events", &models.FoodEvent{}, tx.Where("user_id = ?", userID)},
+			{"food_lots", &models.FoodLot{}, tx.Where("user_id = ?", userID)},
+			{"foods", &models.Food{}, tx.Where("user_id = ? AND id NOT IN (?)", userID, donatedFoods)},
+		}
+		for _, d := range deletes {
+			if err := d.query.Unscoped().Delete(d.model).Error; err != nil {
+				return fmt.Errorf("failed to delete %s: %w", d.name, err)
+			}
+		}
+
+		if err := removeHouseholdMember(tx, userID); err != nil {
+			return fmt.Errorf("failed to leave household: %w", err)
+		}
+
+		// Donated foods stay for the donation record, stripped of personal details
+		if err := tx.Model(&models.Food{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"image_url": "",
+			"barcode":   "",
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymise foods: %w", err)
+		}
+		if err := tx.Model(&models.Donation{}).Unscoped().Where("user_id = ?", userID).
+			Update("notes", "").Error; err != nil {
+			return fmt.Errorf("failed to anonymise donations: %w", err)
+		}
+
+		now := time.Now()
+		return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"email":                 fmt.Sprintf("deleted-%s@deleted.invalid", userID),
+			"password":              "!", // not a bcrypt hash, so no password ever matches
+			"name":                  "Deleted User",
+			"phone":                 "",
+			"avatar":                "",
+			"email_verified":        false,
+			"email_verified_at":     nil,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+			"deletion_scheduled_at": nil,
+			"anonymized_at":         now,
+		}).Error
+	})
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// FoodsAddedBucket counts foods added in a time bucket per add method
+type FoodsAddedBucket struct {
+	Bucket    time.Time `json:"bucket"`
+	AddMethod string    `json:"add_method"`
+	Count     int64     `json:"count"`
+}
+
+// ConsumptionStat holds average days from purchase to consumption per category
+type ConsumptionStat struct {
+	Category      string  `json:"category"`
+	AvgDays       float64 `json:"avg_days"`
+	ConsumedItems int64   `json:"consumed_items"`
+}
+
+// ExpiryRateBucket holds how many items reached their expiry date in a bucket
+// and how many of them were still in storage (wasted) at that point
+type ExpiryRateBucket struct {
+	Bucket     time.Time `json:"bucket"`
+	TotalItems int64     `json:"total_items"`
+	Expired    int64     `json:"expired"`
+	ExpiryRate float64   `json:"expiry_rate"`
+}
+
+// WasteReasonStat counts wasted items by the last state change before they expired.
+// LastEvent is a models.FoodEvent* type, or none when the food was never opened or moved.
+type WasteReasonStat struct {
+	LastEvent string `json:"last_event"`
+	Items     int64  `json:"items"`
+}
+
+// SpendingBucket holds money spent in a bucket per source
+type SpendingBucket struct {
+	Bucket time.Time `json:"bucket"`
+	Source string    `json:"source"` // transaction, order
+	Amount float64   `json:"amount"`
+	Count  int64     `json:"count"`
+}
+
+// PointsSourceBucket holds points earned in a bucket per source
+type PointsSourceBucket struct {
+	Bucket time.Time `json:"bucket"`
+	Source string    `json:"source"`
+	Points int64     `json:"points"`
+}
+
+type AnalyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// FoodsAddedByMethod returns foods added per bucket grouped by add method
+func (r *AnalyticsRepository) FoodsAddedByMethod(userID uuid.UUID, from, to time.Time, bucket string) ([]FoodsAddedBucket, error) {
+	var results []FoodsAddedBucket
+	err := r.db.Model(&models.Food{}).
+		Select("date_trunc(?, created_at) AS bucket, COALESCE(NULLIF(add_method, ''), 'manual') AS add_method, COUNT(*) AS count", bucket).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Group("1, 2").
+		Order("1 ASC, 2 ASC").
+		Scan(&results).Error
+	return results, err
+}
+
+// AverageConsumptionDays returns average days between purchase and consumption per category.
+// A food counts as consumed once its quantity reached 0; the last update is used as consumption time.
+func (r *AnalyticsRepository) AverageConsumptionDays(userID uuid.UUID, from, to time.Time) ([]ConsumptionStat, error) {
+	var results []ConsumptionStat
+	err := r.db.Model(&models.Food{}).
+		Select("category, AVG(EXTRACT(EPOCH FROM (updated_at - COALESCE(purchase_date, created_at))) / 86400) AS avg_days, COUNT(*) AS consumed_items").
+		Where("user_id = ? AND quantity <= 0 AND updated_at >= ? AND updated_at < ?", userID, from, to).
+		Group("category").
+		Order("category ASC").
+		Scan(&results).Error
+	return results, err
+}
+
+// ExpiryRate returns, per bucket of expiry date, how many items expired while still in storage
+func (r *AnalyticsRepository) ExpiryRate(userID uuid.UUID, from, to time.Time, bucket string) ([]ExpiryRateBucket, error) {
+	var results []ExpiryRateBucket
+	err := r.db.Model(&models.Food{}).
+		Select(`date_trunc(?, expiry_date) AS bucket,
+			COUNT(*) AS total_items,
+			COUNT(*) FILTER (WHERE quantity > 0) AS expired,
+			ROUND(COUNT(*) FILTER (WHERE quantity > 0)::numeric / NULLIF(COUNT(*), 0), 4) AS expiry_rate`, bucket).
+		Where("user_id = ? AND expiry_date IS NOT NULL AND expiry_date >= ? AND expiry_date < ? AND expiry_date < NOW()", userID, from, to).
+		Group("1").
+		Order("1 ASC").
+		Scan(&results).Error
+	return results, err
+}
+
+// WastedByLastEvent returns the items that expired in storage grouped by their last state change
+func (r *AnalyticsRepository) WastedByLastEvent(userID uuid.UUID, from, to time.Time) ([]WasteReasonStat, error) {
+	var results []WasteReasonStat
+	err := r.db.Raw(`
+		SELECT COALESCE(last_event.type, 'none') AS last_event, COUNT(*) AS items
+		FROM foods
+		LEFT JOIN LATERAL (
+			SELECT type FROM food_events
+			WHERE food_events.food_id = foods.id
+			ORDER BY created_at DESC
+			LIMIT 1
+		) last_event ON true
+		WHERE foods.user_id = ? AND foods.quantity > 0 AND foods.expiry_date IS NOT NULL
+			AND foods.expiry_date >= ? AND foods.expiry_date < ? AND foods.expiry_date < NOW()
+		GROUP BY 1
+		ORDER BY 2 DESC, 1 ASC`, userID, from, to).Scan(&results).Error
+	return results, err
+}
+
+// SpendingBySource returns completed supermarket transactions and picked up orders per bucket
+func (r *AnalyticsRepository) SpendingBySource(userID uuid.UUID, from, to time.Time, bucket string) ([]SpendingBucket, error) {
+	var results []SpendingBucket
+	err := r.db.Raw(`
+		SELECT bucket, source, SUM(amount) AS amount, COUNT(*) AS count FROM (
+			SELECT date_trunc(@bucket, created_at) AS bucket, 'transaction' AS source, total_amount AS amount
+			FROM transactions
+			WHERE user_id = @user AND status = 'completed' AND created_at >= @from AND created_at < @to
+			UNION ALL
+			SELECT date_trunc(@bucket, created_at) AS bucket, 'order' AS source, final_amount AS amount
+			FROM orders
+			WHERE user_id = @user AND status = 'completed' AND created_at >= @from AND created_at < @to
+		) spend
+		GROUP BY bucket, source
+		ORDER BY bucket ASC, source ASC`,
+		map[string]interface{}{
+			"bucket": bucket,
+			"user":   userID,
+			"from":   from,
+			"to":     to,
+		}).Scan(&results).Error
+	return results, err
+}
+
+// PointsBySource returns earned points per bucket grouped by source
+func (r *AnalyticsRepository) PointsBySource(userID uuid.UUID, from, to time.Time, bucket string) ([]PointsSourceBucket, error) {
+	var results []PointsSourceBucket
+	err := r.db.Model(&models.PointTransaction{}).
+		Select("date_trunc(?, point_transactions.created_at) AS bucket, point_transactions.source AS source, COALESCE(SUM(point_transactions.amount), 0) AS points", bucket).
+		Joins("JOIN user_points ON user_points.id = point_transactions.user_points_id").
+		Where("user_points.user_id = ? AND point_transactions.type = ? AND point_transactions.created_at >= ? AND point_transactions.created_at < ?", userID, "earn", from, to).
+		Group("1, 2").
+		Order("1 ASC, 2 ASC").
+		Scan(&results).Error
+	return results, err
+}
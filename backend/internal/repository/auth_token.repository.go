@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuthTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthTokenRepository(db *gorm.DB) *AuthTokenRepository {
+	return &AuthTokenRepository{db: db}
+}
+
+// Create stores a new token after invalidating earlier unused tokens with the same purpose,
+// so only the most recently sent link works
+func (r *AuthTokenRepository) Create(token *models.AuthToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AuthToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindByHash finds an unused, unexpired token
+func (r *AuthTokenRepository) FindByHash(purpose, hash string) (*models.AuthToken, error) {
+	var token models.AuthToken
+	err := r.db.Where("purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, hash, time.Now()).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid or expired token")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes a token, returning false when it was already used
+func (r *AuthTokenRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.AuthToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
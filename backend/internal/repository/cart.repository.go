@@ -50,6 +50,15 @@ func (r *CartRepository) FindPending(userID uuid.UUID) ([]models.Cart, error) {
 	return carts, err
 }
 
+// FindPendingByName finds unpurchased cart items with the same name, ignoring case
+func (r *CartRepository) FindPendingByName(userID uuid.UUID, name string) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := r.db.Where("user_id = ? AND is_purchased = ? AND LOWER(TRIM(item_name)) = LOWER(TRIM(?))", userID, false, name).
+		Order("created_at ASC").
+		Find(&carts).Error
+	return carts, err
+}
+
 // FindPurchased finds purchased cart items for a user
 func (r *CartRepository) FindPurchased(userID uuid.UUID) ([]models.Cart, error) {
 	var carts []models.Cart
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// Upsert registers a device token, moving it to the given user if it was registered before
+func (r *DeviceRepository) Upsert(device *models.Device) error {
+	device.LastSeenAt = time.Now()
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "last_seen_at", "updated_at"}),
+	}).Create(device).Error
+	if err != nil {
+		return err
+	}
+
+	// Reload so the caller gets the ID of an already registered token
+	var stored models.Device
+	if err := r.db.Where("token = ?", device.Token).First(&stored).Error; err != nil {
+		return err
+	}
+	*device = stored
+	return nil
+}
+
+// FindByUser finds all devices of a user
+func (r *DeviceRepository) FindByUser(userID uuid.UUID) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.db.Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+// Delete removes a device owned by a user
+func (r *DeviceRepository) Delete(userID, id uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Device{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("device not found")
+	}
+	return nil
+}
+
+// DeleteByToken removes a device token, e.g. when the push provider reports it as unregistered
+func (r *DeviceRepository) DeleteByToken(token string) error {
+	return r.db.Where("token = ?", token).Delete(&models.Device{}).Error
+}
+
+// CreateDelivery records a push delivery unless one with the same dedup key exists.
+// Returns true when a new row was created.
+func (r *DeviceRepository) CreateDelivery(delivery *models.PushDelivery) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateDelivery saves delivery status changes
+func (r *DeviceRepository) UpdateDelivery(delivery *models.PushDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// FindRetryableDeliveries finds deferred or failed deliveries that still have attempts left.
+// Rejected deliveries failed for good and are not retried.
+func (r *DeviceRepository) FindRetryableDeliveries(maxAttempts int, since time.Time) ([]models.PushDelivery, error) {
+	var deliveries []models.PushDelivery
+	err := r.db.Where("(status = ? OR (status = ? AND attempts < ?)) AND created_at >= ?", "deferred", "failed", maxAttempts, since).
+		Order("created_at ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetQuietHours retrieves quiet hours of a user, nil when not configured
+func (r *DeviceRepository) GetQuietHours(userID uuid.UUID) (*models.QuietHours, error) {
+	var quietHours models.QuietHours
+	err := r.db.Where("user_id = ?", userID).First(&quietHours).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quietHours, nil
+}
+
+// SaveQuietHours creates or updates quiet hours of a user
+func (r *DeviceRepository) SaveQuietHours(quietHours *models.QuietHours) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "start_time", "end_time", "timezone", "updated_at"}),
+	}).Create(quietHours).Error
+}
@@ -58,6 +58,11 @@ func (r *DonationRepository) GetDonationByID(id uint) (*models.Donation, error)
 	return &donation, err
 }
 
+// UpdateDonationPoints sets the points a donation earned
+func (r *DonationRepository) UpdateDonationPoints(id uint, points int) error {
+	return r.db.Model(&models.Donation{}).Where("id = ?", id).Update("points_earned", points).Error
+}
+
 func (r *DonationRepository) UpdateDonationStatus(id uint, status string) error {
 	return r.db.Model(&models.Donation{}).Where("id = ?", id).Update("status", status).Error
 }
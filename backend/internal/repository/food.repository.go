@@ -7,8 +7,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Lots are used up first-expiring-first-out, lots without an expiry date last
+const foodLotOrder = "expiry_date ASC NULLS LAST, created_at ASC"
+
 type FoodRepository struct {
 	db *gorm.DB
 }
@@ -17,8 +21,23 @@ func NewFoodRepository(db *gorm.DB) *FoodRepository {
 	return &FoodRepository{db: db}
 }
 
-// Create creates a new food item
+// Create creates a new food item, with a first lot holding its quantity unless lots are given
 func (r *FoodRepository) Create(food *models.Food) error {
+	if len(food.Lots) == 0 && food.Quantity > 0 {
+		source := food.AddMethod
+		if source == "" {
+			source = "manual"
+		}
+		food.Lots = []models.FoodLot{{
+			UserID:          food.UserID,
+			Quantity:        food.Quantity,
+			InitialQuantity: food.Quantity,
+			PurchaseDate:    food.PurchaseDate,
+			ExpiryDate:      food.ExpiryDate,
+			OpenedAt:        food.OpenedAt,
+			Source:          source,
+		}}
+	}
 	return r.db.Create(food).Error
 }
 
@@ -113,9 +132,94 @@ func (r *FoodRepository) FindByLocation(userID uuid.UUID, location string) ([]mo
 	return foods, err
 }
 
-// Update updates food item
+// Update updates food item, and its lots when they were loaded
 func (r *FoodRepository) Update(food *models.Food) error {
-	return r.db.Save(food).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveFood(tx, food)
+	})
+}
+
+// FindLots returns the lots of a food that are still in stock, expiring first
+func (r *FoodRepository) FindLots(foodID uuid.UUID) ([]models.FoodLot, error) {
+	var lots []models.FoodLot
+	err := r.db.Where("food_id = ? AND quantity > 0", foodID).Order(foodLotOrder).Find(&lots).Error
+	return lots, err
+}
+
+// UpdateStock locks a food with its lots in stock and saves the changes made by update,
+// so concurrent consumption can't use the same lot twice. An error from update rolls back.
+func (r *FoodRepository) UpdateStock(foodID uuid.UUID, update func(food *models.Food) error) (*models.Food, error) {
+	return r.UpdateStockWithEvent(foodID, func(food *models.Food) (*models.FoodEvent, error) {
+		return nil, update(food)
+	})
+}
+
+// UpdateStockWithEvent is UpdateStock for state transitions, the event update returns
+// is recorded in the same transaction
+func (r *FoodRepository) UpdateStockWithEvent(foodID uuid.UUID, update func(food *models.Food) (*models.FoodEvent, error)) (*models.Food, error) {
+	return r.lockStock(foodID, func(tx *gorm.DB, food *models.Food) error {
+		event, err := update(food)
+		if err != nil {
+			return err
+		}
+		if err := saveFood(tx, food); err != nil {
+			return err
+		}
+		if event != nil {
+			return tx.Create(event).Error
+		}
+		return nil
+	})
+}
+
+// DonateStock takes a donation's quantity from the lots expiring first and records the donation
+// in the same transaction, so two donations can't give away the same stock
+func (r *FoodRepository) DonateStock(donation *models.Donation) (*models.Food, error) {
+	return r.lockStock(donation.FoodID, func(tx *gorm.DB, food *models.Food) error {
+		if food.Quantity < float64(donation.Quantity) {
+			return errors.New("insufficient food quantity")
+		}
+		food.Consume(float64(donation.Quantity))
+		if err := saveFood(tx, food); err != nil {
+			return err
+		}
+		return tx.Create(donation).Error
+	})
+}
+
+// lockStock runs fn in a transaction on the food and its lots in stock, locked FOR UPDATE
+func (r *FoodRepository) lockStock(foodID uuid.UUID, fn func(tx *gorm.DB, food *models.Food) error) (*models.Food, error) {
+	var food models.Food
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", foodID).First(&food).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("food not found")
+			}
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("food_id = ? AND quantity > 0", foodID).
+			Order(foodLotOrder).
+			Find(&food.Lots).Error; err != nil {
+			return err
+		}
+		return fn(tx, &food)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &food, nil
+}
+
+// saveFood saves a food and its loaded lots, lots without an ID are created
+func saveFood(tx *gorm.DB, food *models.Food) error {
+	for i := range food.Lots {
+		if err := tx.Save(&food.Lots[i]).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Omit(clause.Associations).Save(food).Error
 }
 
 // Delete deletes a food item
@@ -200,3 +304,66 @@ func (r *FoodRepository) FindByNameExact(userID uuid.UUID, name string) ([]model
 		Find(&foods).Error
 	return foods, err
 }
+
+// FindAllExpiringSoon finds food of every user expiring within specified days
+func (r *FoodRepository) FindAllExpiringSoon(days int) ([]models.Food, error) {
+	var foods []models.Food
+	expiryDate := time.Now().AddDate(0, 0, days)
+
+	err := r.db.Where("expiry_date IS NOT NULL AND expiry_date <= ? AND expiry_date > ? AND quantity > 0",
+		expiryDate, time.Now()).
+		Order("expiry_date ASC").
+		Find(&foods).Error
+
+	return foods, err
+}
+
+// FindAllExpired finds expired food items of every user
+func (r *FoodRepository) FindAllExpired() ([]models.Food, error) {
+	var foods []models.Food
+
+	err := r.db.Where("expiry_date IS NOT NULL AND expiry_date < ? AND quantity > 0", time.Now()).
+		Order("expiry_date DESC").
+		Find(&foods).Error
+
+	return foods, err
+}
+
+// FindAllLowStock finds food of every user whose remaining quantity is at or below
+// the given percentage of its initial quantity
+func (r *FoodRepository) FindAllLowStock(percentage float64) ([]models.Food, error) {
+	var foods []models.Food
+
+	err := r.db.Where("initial_quantity > 0 AND quantity > 0 AND quantity * 100 <= initial_quantity * ?", percentage).
+		Order("updated_at DESC").
+		Find(&foods).Error
+
+	return foods, err
+}
+
+// FindEvents returns the state transitions of a food, oldest first
+func (r *FoodRepository) FindEvents(foodID uuid.UUID) ([]models.FoodEvent, error) {
+	var events []models.FoodEvent
+	err := r.db.Where("food_id = ?", foodID).Order("created_at ASC").Find(&events).Error
+	return events, err
+}
+
+// FindLatestEvents returns the latest state transition of each food that has one
+func (r *FoodRepository) FindLatestEvents(foodIDs []uuid.UUID) (map[uuid.UUID]models.FoodEvent, error) {
+	latest := make(map[uuid.UUID]models.FoodEvent)
+	if len(foodIDs) == 0 {
+		return latest, nil
+	}
+
+	var events []models.FoodEvent
+	err := r.db.Raw(`SELECT DISTINCT ON (food_id) * FROM food_events
+		WHERE food_id IN ?
+		ORDER BY food_id, created_at DESC`, foodIDs).Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		latest[event.FoodID] = event
+	}
+	return latest, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaderboardRow is one user's points earned in a leaderboard period
+type LeaderboardRow struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Avatar string    `json:"avatar"`
+	Points int       `json:"points"`
+}
+
+type GamificationRepository struct {
+	db *gorm.DB
+}
+
+func NewGamificationRepository(db *gorm.DB) *GamificationRepository {
+	return &GamificationRepository{db: db}
+}
+
+// CreateActivity records a domain event of a user
+func (r *GamificationRepository) CreateActivity(activity *models.UserActivity) error {
+	return r.db.Create(activity).Error
+}
+
+// CountActivities returns the number of activities of a user per type
+func (r *GamificationRepository) CountActivities(userID uuid.UUID) (map[string]int, error) {
+	var rows []struct {
+		Type  string
+		Count int
+	}
+	err := r.db.Model(&models.UserActivity{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// UpdateStreak locks the user's streak of a kind, creating it if needed, and saves the changes made by update
+func (r *GamificationRepository) UpdateStreak(userID uuid.UUID, kind string, update func(streak *models.UserStreak)) (*models.UserStreak, error) {
+	var streak models.UserStreak
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}},
+			DoNothing: true,
+		}).Create(&models.UserStreak{UserID: userID, Kind: kind}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND kind = ?", userID, kind).
+			First(&streak).Error; err != nil {
+			return err
+		}
+
+		update(&streak)
+		return tx.Save(&streak).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &streak, nil
+}
+
+// GetStreaks returns all streaks of a user
+func (r *GamificationRepository) GetStreaks(userID uuid.UUID) ([]models.UserStreak, error) {
+	var streaks []models.UserStreak
+	err := r.db.Where("user_id = ?", userID).Order("kind ASC").Find(&streaks).Error
+	return streaks, err
+}
+
+// CreateAchievement stores an unlocked achievement.
+// Returns false when the user already had it.
+func (r *GamificationRepository) CreateAchievement(achievement *models.UserAchievement) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "code"}},
+		DoNothing: true,
+	}).Create(achievement)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetAchievements returns the achievements a user unlocked
+func (r *GamificationRepository) GetAchievements(userID uuid.UUID) ([]models.UserAchievement, error) {
+	var achievements []models.UserAchievement
+	err := r.db.Where("user_id = ?", userID).Order("awarded_at ASC").Find(&achievements).Error
+	return achievements, err
+}
+
+// FindUsersWithActivity returns the users with an activity of one of the types in [from, to)
+func (r *GamificationRepository) FindUsersWithActivity(types []string, from, to time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.UserActivity{}).
+		Where("type IN ? AND created_at >= ? AND created_at < ?", types, from, to).
+		Distinct("user_id").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// FindUsersWithWaste returns the users with food that expired in [from, to) while still in storage
+func (r *GamificationRepository) FindUsersWithWaste(from, to time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Food{}).
+		Where("expiry_date >= ? AND expiry_date < ? AND quantity > 0", from, to).
+		Distinct("user_id").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// leaderboardQuery sums points earned in [from, to) per visible user.
+// memberIDs limits the board to a household, nil means everyone.
+func (r *GamificationRepository) leaderboardQuery(from, to time.Time, memberIDs []uuid.UUID) *gorm.DB {
+	query := r.db.Table("point_transactions").
+		Joins("JOIN user_points ON user_points.id = point_transactions.user_points_id").
+		Joins("JOIN users ON users.id = user_points.user_id").
+		Where("point_transactions.type = ? AND point_transactions.created_at >= ? AND point_transactions.created_at < ?", "earn", from, to).
+		Where("users.leaderboard_opt_out = ? AND users.anonymized_at IS NULL", false)
+	if memberIDs != nil {
+		query = query.Where("users.id IN ?", memberIDs)
+	}
+	return query
+}
+
+// GetLeaderboard returns the users with the most points earned in [from, to)
+func (r *GamificationRepository) GetLeaderboard(from, to time.Time, memberIDs []uuid.UUID, limit int) ([]LeaderboardRow, error) {
+	var rows []LeaderboardRow
+	err := r.leaderboardQuery(from, to, memberIDs).
+		Select("users.id AS user_id, users.name, users.avatar, SUM(point_transactions.amount) AS points").
+		Group("users.id, users.name, users.avatar").
+		// Ties go to whoever got there first
+		Order("points DESC, MAX(point_transactions.created_at) ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetLeaderboardPosition returns a user's points in [from, to) and how many users earned more
+func (r *GamificationRepository) GetLeaderboardPosition(userID uuid.UUID, from, to time.Time, memberIDs []uuid.UUID) (int, int, error) {
+	var points int
+	if err := r.leaderboardQuery(from, to, memberIDs).
+		Where("users.id = ?", userID).
+		Select("COALESCE(SUM(point_transactions.amount), 0)").
+		Scan(&points).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var ahead int64
+	higher := r.leaderboardQuery(from, to, memberIDs).
+		Select("users.id").
+		Group("users.id").
+		Having("SUM(point_transactions.amount) > ?", points)
+	if err := r.db.Table("(?) AS ahead", higher).Count(&ahead).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return points, int(ahead), nil
+}
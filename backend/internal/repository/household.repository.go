@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type HouseholdRepository struct {
+	db *gorm.DB
+}
+
+func NewHouseholdRepository(db *gorm.DB) *HouseholdRepository {
+	return &HouseholdRepository{db: db}
+}
+
+// Create creates a household with its owner as first member
+func (r *HouseholdRepository) Create(household *models.Household) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(household).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: household.ID,
+			UserID:      household.OwnerID,
+		}).Error
+	})
+}
+
+// FindByUserID finds the household a user belongs to, nil when they have none
+func (r *HouseholdRepository) FindByUserID(userID uuid.UUID) (*models.Household, error) {
+	var household models.Household
+	err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("joined_at ASC")
+	}).Preload("Members.User").
+		Where("id IN (?)", r.db.Model(&models.HouseholdMember{}).Select("household_id").Where("user_id = ?", userID)).
+		First(&household).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &household, nil
+}
+
+// FindByInviteCode finds a household by its invite code
+func (r *HouseholdRepository) FindByInviteCode(code string) (*models.Household, error) {
+	var household models.Household
+	err := r.db.Where("invite_code = ?", code).First(&household).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("household not found")
+		}
+		return nil, err
+	}
+	return &household, nil
+}
+
+// GetMemberIDs returns the user IDs of a household's members
+func (r *HouseholdRepository) GetMemberIDs(householdID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.HouseholdMember{}).Where("household_id = ?", householdID).Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// AddMember adds a user to a household
+func (r *HouseholdRepository) AddMember(householdID, userID uuid.UUID) error {
+	return r.db.Create(&models.HouseholdMember{HouseholdID: householdID, UserID: userID}).Error
+}
+
+// RemoveMember removes a user from their household. The longest-standing member
+// takes over an owner who leaves, and an empty household is deleted.
+func (r *HouseholdRepository) RemoveMember(userID uuid.UUID) error {
+	return removeHouseholdMember(r.db, userID)
+}
+
+func removeHouseholdMember(db *gorm.DB, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var member models.HouseholdMember
+		result := tx.Where("user_id = ?", userID).Limit(1).Find(&member)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+
+		var next models.HouseholdMember
+		result = tx.Where("household_id = ?", member.HouseholdID).Order("joined_at ASC").Limit(1).Find(&next)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Delete(&models.Household{}, "id = ?", member.HouseholdID).Error
+		}
+		return tx.Model(&models.Household{}).
+			Where("id = ? AND owner_id = ?", member.HouseholdID, userID).
+			Update("owner_id", next.UserID).Error
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// AcquireLease claims the slot scheduledFor of a job for owner.
+// It fails when another instance holds the lease or the slot was already claimed.
+func (r *JobRepository) AcquireLease(name, owner string, scheduledFor time.Time, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result := r.db.Exec(`
+		INSERT INTO job_leases (name, owner, locked_until, last_scheduled_for, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			owner = EXCLUDED.owner,
+			locked_until = EXCLUDED.locked_until,
+			last_scheduled_for = EXCLUDED.last_scheduled_for,
+			updated_at = EXCLUDED.updated_at
+		WHERE job_leases.locked_until < ? AND job_leases.last_scheduled_for < EXCLUDED.last_scheduled_for`,
+		name, owner, now.Add(ttl), scheduledFor, now, now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseLease ends the lease early so the next slot isn't delayed
+func (r *JobRepository) ReleaseLease(name, owner string) error {
+	return r.db.Model(&models.JobLease{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("locked_until", time.Now()).Error
+}
+
+func (r *JobRepository) CreateRun(run *models.JobRun) error {
+	return r.db.Create(run).Error
+}
+
+// FinishRun stores the outcome of a run
+func (r *JobRepository) FinishRun(id uuid.UUID, status, result, errMessage string) error {
+	return r.db.Model(&models.JobRun{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"result":      result,
+			"error":       errMessage,
+			"finished_at": time.Now(),
+		}).Error
+}
+
+// FindRuns lists the latest runs, of one job when name is set
+func (r *JobRepository) FindRuns(name string, limit int) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	query := r.db.Order("started_at DESC").Limit(limit)
+	if name != "" {
+		query = query.Where("job_name = ?", name)
+	}
+	err := query.Find(&runs).Error
+	return runs, err
+}
+
+// DeleteRunsBefore removes run history older than the given time
+func (r *JobRepository) DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("started_at < ?", before).Delete(&models.JobRun{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateIfNotExists inserts a notification unless one with the same dedup key exists.
+// Returns true when a new row was created.
+func (r *NotificationRepository) CreateIfNotExists(notification *models.Notification) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByID finds a notification owned by a user
+func (r *NotificationRepository) FindByID(userID, id uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("notification not found")
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// FindByUser finds non-dismissed notifications for a user with pagination.
+// status is one of: all, read, unread. types optionally restricts notification types.
+func (r *NotificationRepository) FindByUser(userID uuid.UUID, status string, types []string, page, limit int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.Model(&models.Notification{}).Where("user_id = ? AND dismissed_at IS NULL", userID)
+	switch status {
+	case "read":
+		query = query.Where("is_read = ?", true)
+	case "unread":
+		query = query.Where("is_read = ?", false)
+	}
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+// CountUnread counts unread, non-dismissed notifications for a user
+func (r *NotificationRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ? AND dismissed_at IS NULL", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// SetRead marks a notification as read or unread
+func (r *NotificationRepository) SetRead(userID, id uuid.UUID, read bool) error {
+	var readAt *time.Time
+	if read {
+		now := time.Now()
+		readAt = &now
+	}
+
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{
+			"is_read": read,
+			"read_at": readAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+// MarkAllAsRead marks every unread notification of a user as read
+func (r *NotificationRepository) MarkAllAsRead(userID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ? AND dismissed_at IS NULL", userID, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Dismiss hides a notification from the inbox; dismissed rows are kept for deduplication
+func (r *NotificationRepository) Dismiss(userID, id uuid.UUID) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND dismissed_at IS NULL", id, userID).
+		Update("dismissed_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("notification not found")
+	}
+	return nil
+}
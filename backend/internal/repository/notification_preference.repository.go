@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type NotificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// FindByUser finds the saved preferences of a user, nil when none were saved
+func (r *NotificationPreferenceRepository) FindByUser(userID uuid.UUID) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).First(&pref).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// Save creates or updates preferences of a user
+func (r *NotificationPreferenceRepository) Save(pref *models.NotificationPreference) error {
+	// Select("*") makes gorm persist false/zero values instead of column defaults
+	if pref.ID == uuid.Nil {
+		return r.db.Select("*").Omit("User").Create(pref).Error
+	}
+	return r.db.Select("*").Omit("User").Save(pref).Error
+}
+
+// FindDigestEnabled finds the preferences of every user who wants a daily digest
+func (r *NotificationPreferenceRepository) FindDigestEnabled() ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.db.Where("digest_enabled = ?", true).Find(&prefs).Error
+	return prefs, err
+}
+
+// MaxLowStockPercentage returns the highest low stock percentage any user configured
+func (r *NotificationPreferenceRepository) MaxLowStockPercentage() (float64, error) {
+	var max float64
+	err := r.db.Model(&models.NotificationPreference{}).
+		Where("low_stock_enabled = ?", true).
+		Select("COALESCE(MAX(low_stock_percentage), 0)").
+		Scan(&max).Error
+	return max, err
+}
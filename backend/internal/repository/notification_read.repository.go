@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"gorm.io/gorm"
@@ -42,8 +45,9 @@ func (r *NotificationReadRepository) GetReadNotifications(userID uuid.UUID) (map
 	return readMap, nil
 }
 
-// DeleteOldReads deletes read notifications older than 30 days
-func (r *NotificationReadRepository) DeleteOldReads() error {
-	return r.db.Where("read_at < NOW() - INTERVAL '30 days'").
-		Delete(&models.NotificationRead{}).Error
+// DeleteOldReads deletes read markers older than the given time and returns how many were removed
+func (r *NotificationReadRepository) DeleteOldReads(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("read_at < ?", before).
+		Delete(&models.NotificationRead{})
+	return result.RowsAffected, result.Error
 }
@@ -51,3 +51,10 @@ func (r *OrderRepository) UpdateOrderStatus(id uuid.UUID, status string) error {
 func (r *OrderRepository) UpdateOrder(order *models.Order) error {
 	return r.db.Save(order).Error
 }
+
+// GetOrdersByStatus retrieves orders of every user with the given status
+func (r *OrderRepository) GetOrdersByStatus(status string) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Where("status = ?", status).Order("created_at ASC").Find(&orders).Error
+	return orders, err
+}
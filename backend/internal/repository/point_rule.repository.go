@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PointRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewPointRuleRepository(db *gorm.DB) *PointRuleRepository {
+	return &PointRuleRepository{db: db}
+}
+
+func (r *PointRuleRepository) Create(rule *models.PointRule) error {
+	// Select all fields so false and 0 aren't replaced by the column defaults
+	return r.db.Select("*").Create(rule).Error
+}
+
+func (r *PointRuleRepository) FindByID(id uuid.UUID) (*models.PointRule, error) {
+	var rule models.PointRule
+	err := r.db.Where("id = ?", id).First(&rule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("point rule not found")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindAll lists rules, optionally of one action
+func (r *PointRuleRepository) FindAll(action string) ([]models.PointRule, error) {
+	var rules []models.PointRule
+	query := r.db.Order("action ASC, type ASC, priority DESC, created_at ASC")
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// FindActive returns the active rules of an action whose campaign window contains now,
+// highest priority first
+func (r *PointRuleRepository) FindActive(action string, now time.Time) ([]models.PointRule, error) {
+	var rules []models.PointRule
+	err := r.db.
+		Where("action = ? AND is_active = ?", action, true).
+		Where("(starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Order("priority DESC, created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *PointRuleRepository) Update(rule *models.PointRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *PointRuleRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.PointRule{}, "id = ?", id).Error
+}
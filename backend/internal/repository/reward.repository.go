@@ -1,9 +1,15 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RewardRepository struct {
@@ -54,25 +60,100 @@ func (r *RewardRepository) GetUserPoints(userID uuid.UUID) (*models.UserPoints,
 	return r.GetUserPointsByUserID(userID)
 }
 
-// DeductPoints deducts points from user and creates a transaction
+// EarnPoints credits the transaction's amount to a user as a new lot
+func (r *RewardRepository) EarnPoints(userID uuid.UUID, transaction *models.PointTransaction) error {
+	userPoints, err := r.GetOrCreateUserPoints(userID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return creditLot(tx, userPoints.ID, transaction)
+	})
+}
+
+// EarnPointsCapped credits the transaction build returns for the points the user already earned
+// from the source since the given time. The balance stays locked in between, so concurrent
+// awards under a cap see each other. Nothing is credited when build returns no points.
+func (r *RewardRepository) EarnPointsCapped(userID uuid.UUID, source string, since time.Time, build func(earned int) *models.PointTransaction) error {
+	userPoints, err := r.GetOrCreateUserPoints(userID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userPoints.ID).First(&models.UserPoints{}).Error; err != nil {
+			return err
+		}
+
+		var earned int
+		if err := tx.Model(&models.PointTransaction{}).
+			Where("user_points_id = ? AND type = ? AND source = ? AND created_at >= ?", userPoints.ID, "earn", source, since).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&earned).Error; err != nil {
+			return err
+		}
+
+		transaction := build(earned)
+		if transaction == nil || transaction.Amount <= 0 {
+			return nil
+		}
+		return creditLot(tx, userPoints.ID, transaction)
+	})
+}
+
+// creditLot stores the transaction as a new earn lot and adds its points to the balance
+func creditLot(tx *gorm.DB, userPointsID uuid.UUID, transaction *models.PointTransaction) error {
+	points := transaction.Amount
+	transaction.UserPointsID = userPointsID
+	transaction.Type = "earn"
+	transaction.Remaining = points
+
+	if err := tx.Create(transaction).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.UserPoints{}).
+		Where("id = ?", userPointsID).
+		Updates(map[string]interface{}{
+			"total_points":     gorm.Expr("total_points + ?", points),
+			"available_points": gorm.Expr("available_points + ?", points),
+		}).Error
+}
+
+// DeductPoints deducts points from user and creates a transaction.
+// The points are taken from the oldest unexpired lots first.
 func (r *RewardRepository) DeductPoints(userID uuid.UUID, points int, source, description string, referenceID *uuid.UUID, referenceType string) error {
 	// Start transaction
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Get user points
+		// Get user points, locked so concurrent spends can't overdraw
 		var userPoints models.UserPoints
-		if err := tx.Where("user_id = ?", userID).First(&userPoints).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&userPoints).Error; err != nil {
 			return err
 		}
 
+		// Lots past their expiry may not have been processed by the expiry job yet,
+		// take them out of the balance before checking it
+		expired, err := expireDueLots(tx, userPoints.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		userPoints.AvailablePoints -= expired
+
 		// Check if user has enough points
 		if userPoints.AvailablePoints < points {
 			return gorm.ErrInvalidData
 		}
 
+		if err := consumeLots(tx, userPoints.ID, points); err != nil {
+			return err
+		}
+
 		// Update points
 		userPoints.AvailablePoints -= points
 		userPoints.UsedPoints += points
-		if err := tx.Save(&userPoints).Error; err != nil {
+		if err := tx.Model(&userPoints).Select("available_points", "used_points").Updates(&userPoints).Error; err != nil {
 			return err
 		}
 
@@ -90,6 +171,211 @@ func (r *RewardRepository) DeductPoints(userID uuid.UUID, points int, source, de
 	})
 }
 
+// consumeLots takes points from the oldest unexpired earn lots
+func consumeLots(tx *gorm.DB, userPointsID uuid.UUID, points int) error {
+	var lots []models.PointTransaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_points_id = ? AND type = ? AND remaining > 0", userPointsID, "earn").
+		Order("created_at ASC, id ASC").
+		Find(&lots).Error; err != nil {
+		return err
+	}
+
+	takes, short := planLotConsumption(lots, points, time.Now())
+	if short > 0 {
+		// The balance says the points exist but the lots do not hold them, see makansikctl recompute-points
+		return fmt.Errorf("point lots of %s are %d points short of the balance", userPointsID, short)
+	}
+
+	for _, take := range takes {
+		if err := tx.Model(&models.PointTransaction{}).
+			Where("id = ?", take.lot.ID).
+			Update("remaining", take.lot.Remaining-take.points).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lotTake is the number of points to take from one lot
+type lotTake struct {
+	lot    models.PointTransaction
+	points int
+}
+
+// planLotConsumption takes points from the oldest lots first, skipping expired ones.
+// Returns what to take from each lot and the points the lots could not cover.
+func planLotConsumption(lots []models.PointTransaction, points int, now time.Time) ([]lotTake, int) {
+	sorted := append([]models.PointTransaction(nil), lots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	var takes []lotTake
+	for _, lot := range sorted {
+		if points == 0 {
+			break
+		}
+		if lot.Remaining <= 0 || (lot.ExpiresAt != nil && !lot.ExpiresAt.After(now)) {
+			continue
+		}
+		take := lot.Remaining
+		if take > points {
+			take = points
+		}
+		takes = append(takes, lotTake{lot: lot, points: take})
+		points -= take
+	}
+	return takes, points
+}
+
+// ExpireLots posts an expired transaction for every lot past its expiry that still has points
+// and removes them from the available balance. Returns the number of lots and points expired.
+func (r *RewardRepository) ExpireLots(ctx context.Context, now time.Time) (int, int, error) {
+	var lots []models.PointTransaction
+	if err := r.db.WithContext(ctx).Where("type = ? AND remaining > 0 AND expires_at <= ?", "earn", now).
+		Order("expires_at ASC").
+		Find(&lots).Error; err != nil {
+		return 0, 0, err
+	}
+
+	expiredLots, expiredPoints := 0, 0
+	for _, lot := range lots {
+		if err := ctx.Err(); err != nil {
+			return expiredLots, expiredPoints, err
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Lock the balance before the lot, in the same order as DeductPoints
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", lot.UserPointsID).First(&models.UserPoints{}).Error; err != nil {
+				return err
+			}
+
+			// Claim the lot, a concurrent spend may have used it in the meantime
+			var current models.PointTransaction
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", lot.ID).First(&current).Error; err != nil {
+				return err
+			}
+			if current.Remaining <= 0 {
+				return nil
+			}
+
+			if err := expireLot(tx, &current); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.UserPoints{}).
+				Where("id = ?", current.UserPointsID).
+				Update("available_points", gorm.Expr("available_points - ?", current.Remaining)).Error; err != nil {
+				return err
+			}
+
+			expiredLots++
+			expiredPoints += current.Remaining
+			return nil
+		})
+		if err != nil {
+			return expiredLots, expiredPoints, err
+		}
+	}
+
+	return expiredLots, expiredPoints, nil
+}
+
+// expireDueLots expires a user's lots that are past their expiry and returns the points expired.
+// The caller holds the lock on the user's points and updates the available balance.
+func expireDueLots(tx *gorm.DB, userPointsID uuid.UUID, now time.Time) (int, error) {
+	var lots []models.PointTransaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_points_id = ? AND type = ? AND remaining > 0 AND expires_at <= ?", userPointsID, "earn", now).
+		Find(&lots).Error; err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range lots {
+		if err := expireLot(tx, &lots[i]); err != nil {
+			return 0, err
+		}
+		expired += lots[i].Remaining
+	}
+	return expired, nil
+}
+
+// expireLot empties a locked lot and records the expired transaction, leaving the balance to the caller
+func expireLot(tx *gorm.DB, lot *models.PointTransaction) error {
+	if err := tx.Model(&models.PointTransaction{}).Where("id = ?", lot.ID).Update("remaining", 0).Error; err != nil {
+		return err
+	}
+
+	lotID := lot.ID
+	return tx.Create(&models.PointTransaction{
+		UserPointsID:  lot.UserPointsID,
+		Type:          "expired",
+		Amount:        lot.Remaining,
+		Source:        "expiry",
+		Description:   fmt.Sprintf("%d points earned on %s expired", lot.Remaining, lot.CreatedAt.Format("2006-01-02")),
+		ReferenceID:   &lotID,
+		ReferenceType: "point_lot",
+	}).Error
+}
+
+// SumEarnedSince returns the points a user earned from a source since the given time
+func (r *RewardRepository) SumEarnedSince(userID uuid.UUID, source string, since time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&models.PointTransaction{}).
+		Joins("JOIN user_points ON user_points.id = point_transactions.user_points_id").
+		Where("user_points.user_id = ? AND point_transactions.type = ? AND point_transactions.source = ? AND point_transactions.created_at >= ?",
+			userID, "earn", source, since).
+		Select("COALESCE(SUM(point_transactions.amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetExpiringPoints sums the unspent points that expire before the given time
+// and returns the earliest of those expiry dates.
+func (r *RewardRepository) GetExpiringPoints(userPointsID uuid.UUID, before time.Time) (int, *time.Time, error) {
+	var result struct {
+		Points     int
+		NextExpiry *time.Time
+	}
+	err := r.db.Model(&models.PointTransaction{}).
+		Select("COALESCE(SUM(remaining), 0) AS points, MIN(expires_at) AS next_expiry").
+		Where("user_points_id = ? AND type = ? AND remaining > 0", userPointsID, "earn").
+		Where("expires_at > ? AND expires_at <= ?", time.Now(), before).
+		Scan(&result).Error
+	return result.Points, result.NextExpiry, err
+}
+
+// FindAllUserPoints retrieves the points balance of every user
+func (r *RewardRepository) FindAllUserPoints() ([]models.UserPoints, error) {
+	var points []models.UserPoints
+	err := r.db.Order("created_at ASC").Find(&points).Error
+	return points, err
+}
+
+// SumTransactionsByType totals a user's point transactions per type (earn, spend, expired)
+func (r *RewardRepository) SumTransactionsByType(userPointsID uuid.UUID) (map[string]int, error) {
+	var rows []struct {
+		Type  string
+		Total int
+	}
+	err := r.db.Model(&models.PointTransaction{}).
+		Select("type, COALESCE(SUM(amount), 0) AS total").
+		Where("user_points_id = ?", userPointsID).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int, len(rows))
+	for _, row := range rows {
+		totals[row.Type] = row.Total
+	}
+	return totals, nil
+}
+
 // PointTransaction methods
 func (r *RewardRepository) CreateTransaction(transaction *models.PointTransaction) error {
 	return r.db.Create(transaction).Error
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+func TestPlanLotConsumption(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	lot := func(name string, ageDays, remaining int, expiresInDays *int) models.PointTransaction {
+		l := models.PointTransaction{
+			ID:          uuid.New(),
+			Type:        "earn",
+			Amount:      remaining,
+			Remaining:   remaining,
+			Description: name,
+		}
+		l.CreatedAt = now.AddDate(0, 0, -ageDays)
+		if expiresInDays != nil {
+			expiresAt := now.AddDate(0, 0, *expiresInDays)
+			l.ExpiresAt = &expiresAt
+		}
+		return l
+	}
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		lots      []models.PointTransaction
+		points    int
+		want      map[string]int
+		wantShort int
+	}{
+		{
+			name:   "oldest lot first",
+			lots:   []models.PointTransaction{lot("new", 1, 50, days(300)), lot("old", 100, 50, days(200))},
+			points: 30,
+			want:   map[string]int{"old": 30},
+		},
+		{
+			name:   "spills into the next lot",
+			lots:   []models.PointTransaction{lot("old", 100, 50, days(200)), lot("new", 1, 50, days(300))},
+			points: 70,
+			want:   map[string]int{"old": 50, "new": 20},
+		},
+		{
+			name:   "expired lot is skipped",
+			lots:   []models.PointTransaction{lot("expired", 400, 50, days(-1)), lot("new", 1, 50, days(300))},
+			points: 40,
+			want:   map[string]int{"new": 40},
+		},
+		{
+			name:   "lot expiring right now counts as expired",
+			lots:   []models.PointTransaction{lot("due", 365, 50, days(0)), lot("new", 1, 50, days(300))},
+			points: 40,
+			want:   map[string]int{"new": 40},
+		},
+		{
+			name:   "lot without expiry never expires",
+			lots:   []models.PointTransaction{lot("forever", 2000, 50, nil), lot("new", 1, 50, days(300))},
+			points: 60,
+			want:   map[string]int{"forever": 50, "new": 10},
+		},
+		{
+			name:      "expired points cannot be spent",
+			lots:      []models.PointTransaction{lot("expired", 400, 100, days(-1)), lot("new", 1, 50, days(300))},
+			points:    80,
+			want:      map[string]int{"new": 50},
+			wantShort: 30,
+		},
+		{
+			name:   "used up lots are skipped",
+			lots:   []models.PointTransaction{lot("empty", 100, 0, days(200)), lot("new", 1, 50, days(300))},
+			points: 10,
+			want:   map[string]int{"new": 10},
+		},
+		{
+			name:   "nothing to spend",
+			lots:   []models.PointTransaction{lot("old", 100, 50, days(200))},
+			points: 0,
+			want:   map[string]int{},
+		},
+		{
+			name:      "no lots",
+			points:    10,
+			want:      map[string]int{},
+			wantShort: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			takes, short := planLotConsumption(tt.lots, tt.points, now)
+
+			if short != tt.wantShort {
+				t.Errorf("short = %d, want %d", short, tt.wantShort)
+			}
+			got := make(map[string]int, len(takes))
+			for _, take := range takes {
+				got[take.lot.Description] = take.points
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("takes = %v, want %v", got, tt.want)
+			}
+			for name, points := range tt.want {
+				if got[name] != points {
+					t.Errorf("takes = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
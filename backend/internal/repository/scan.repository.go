@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScanAccuracyStat holds how often scans in a group were saved as recognised.
+// Model correct compares with what the model recognised, suggestion correct with
+// what the user was shown after their learned aliases.
+type ScanAccuracyStat struct {
+	Group             string  `json:"group"`
+	Scans             int64   `json:"scans"`
+	Saved             int64   `json:"saved"`
+	ModelCorrect      int64   `json:"model_correct"`
+	SuggestionCorrect int64   `json:"suggestion_correct"`
+	NameCorrected     int64   `json:"name_corrected"`
+	CategoryCorrected int64   `json:"category_corrected"`
+	AliasApplied      int64   `json:"alias_applied"`
+	AvgConfidence     float64 `json:"avg_confidence"`
+}
+
+// ScanCorrection counts how often a recognised name was saved as another name
+type ScanCorrection struct {
+	RecognizedName string `json:"recognized_name"`
+	SavedName      string `json:"saved_name"`
+	SavedCategory  string `json:"saved_category"`
+	Count          int64  `json:"count"`
+}
+
+const scanAccuracySelect = `%s AS "group",
+	COUNT(*) AS scans,
+	COUNT(*) FILTER (WHERE saved_at IS NOT NULL) AS saved,
+	COUNT(*) FILTER (WHERE saved_at IS NOT NULL AND lower(saved_name) = lower(recognized_name) AND lower(saved_category) = lower(recognized_category)) AS model_correct,
+	COUNT(*) FILTER (WHERE saved_at IS NOT NULL AND lower(saved_name) = lower(suggested_name) AND lower(saved_category) = lower(suggested_category)) AS suggestion_correct,
+	COUNT(*) FILTER (WHERE saved_at IS NOT NULL AND lower(saved_name) <> lower(suggested_name)) AS name_corrected,
+	COUNT(*) FILTER (WHERE saved_at IS NOT NULL AND lower(saved_category) <> lower(suggested_category)) AS category_corrected,
+	COUNT(*) FILTER (WHERE alias_applied) AS alias_applied,
+	COALESCE(AVG(confidence), 0) AS avg_confidence`
+
+type ScanRepository struct {
+	db *gorm.DB
+}
+
+func NewScanRepository(db *gorm.DB) *ScanRepository {
+	return &ScanRepository{db: db}
+}
+
+func (r *ScanRepository) CreateRecord(record *models.ScanRecord) error {
+	return r.db.Create(record).Error
+}
+
+// FindRecord finds a scan of a user
+func (r *ScanRepository) FindRecord(userID, id uuid.UUID) (*models.ScanRecord, error) {
+	var record models.ScanRecord
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scan not found")
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *ScanRepository) UpdateRecord(record *models.ScanRecord) error {
+	return r.db.Save(record).Error
+}
+
+// FindAlias returns the user's alias of a recognised name, or nil when there is none
+func (r *ScanRepository) FindAlias(userID uuid.UUID, recognizedName string) (*models.ScanAlias, error) {
+	var alias models.ScanAlias
+	result := r.db.Where("user_id = ? AND recognized_name = ?", userID, recognizedName).Limit(1).Find(&alias)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &alias, nil
+}
+
+// FindAliases returns the aliases of a user, most used first
+func (r *ScanRepository) FindAliases(userID uuid.UUID) ([]models.ScanAlias, error) {
+	var aliases []models.ScanAlias
+	err := r.db.Where("user_id = ?", userID).Order("times_applied DESC, recognized_name ASC").Find(&aliases).Error
+	return aliases, err
+}
+
+// UpsertAlias creates an alias or points the existing alias of the name to the new correction
+func (r *ScanRepository) UpsertAlias(alias *models.ScanAlias) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "recognized_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "category", "updated_at"}),
+	}).Create(alias).Error
+}
+
+// IncrementAliasUses counts that an alias was applied to a scan
+func (r *ScanRepository) IncrementAliasUses(id uuid.UUID) error {
+	return r.db.Model(&models.ScanAlias{}).Where("id = ?", id).
+		UpdateColumn("times_applied", gorm.Expr("times_applied + 1")).Error
+}
+
+// DeleteAlias removes an alias by ID, returning "alias not found" when the user has no such alias
+func (r *ScanRepository) DeleteAlias(userID, id uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.ScanAlias{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("alias not found")
+	}
+	return nil
+}
+
+// DeleteAliasByName removes the alias of a recognised name, if any
+func (r *ScanRepository) DeleteAliasByName(userID uuid.UUID, recognizedName string) error {
+	return r.db.Where("user_id = ? AND recognized_name = ?", userID, recognizedName).Delete(&models.ScanAlias{}).Error
+}
+
+// AccuracyBy returns scan accuracy grouped by a SQL expression over scan_records.
+// A nil userID covers all users.
+func (r *ScanRepository) AccuracyBy(groupExpr string, userID *uuid.UUID, from, to time.Time, args ...interface{}) ([]ScanAccuracyStat, error) {
+	var results []ScanAccuracyStat
+	query := r.db.Model(&models.ScanRecord{}).
+		Select(fmt.Sprintf(scanAccuracySelect, groupExpr), args...).
+		Where("created_at >= ? AND created_at < ?", from, to)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Group("1").Order("1 ASC").Scan(&results).Error
+	return results, err
+}
+
+// TopCorrections returns the most frequent corrections of saved scans
+func (r *ScanRepository) TopCorrections(userID *uuid.UUID, from, to time.Time, limit int) ([]ScanCorrection, error) {
+	var results []ScanCorrection
+	query := r.db.Model(&models.ScanRecord{}).
+		Select("lower(recognized_name) AS recognized_name, saved_name, saved_category, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ? AND saved_at IS NOT NULL", from, to).
+		Where("(lower(saved_name) <> lower(recognized_name) OR lower(saved_category) <> lower(recognized_category))")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Group("1, 2, 3").Order("4 DESC, 1 ASC").Limit(limit).Scan(&results).Error
+	return results, err
+}
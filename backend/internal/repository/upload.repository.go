@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type UploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) *UploadRepository {
+	return &UploadRepository{db: db}
+}
+
+func (r *UploadRepository) Create(upload *models.Upload) error {
+	return r.db.Create(upload).Error
+}
+
+// FindByKey finds an upload by the key of its original or its thumbnail
+func (r *UploadRepository) FindByKey(key string) (*models.Upload, error) {
+	var upload models.Upload
+	err := r.db.Where("key = ? OR thumbnail_key = ?", key, key).First(&upload).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("upload not found")
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// FindByUserID returns all uploads of a user
+func (r *UploadRepository) FindByUserID(userID uuid.UUID) ([]models.Upload, error) {
+	var uploads []models.Upload
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&uploads).Error
+	return uploads, err
+}
+
+// DeleteByUserID removes the upload records of a user
+func (r *UploadRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.Upload{}).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
@@ -47,9 +48,38 @@ func (r *UserRepository) FindByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
-// Update updates user information
+// Update updates user information. The lockout counters are left out,
+// they change concurrently with logins and have their own targeted updates.
 func (r *UserRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit("failed_login_attempts", "locked_until").Save(user).Error
+}
+
+// IncrementFailedLogins counts a failed login and returns the new number of consecutive failures
+func (r *UserRepository) IncrementFailedLogins(id uuid.UUID) (int, error) {
+	var attempts int
+	err := r.db.Raw(
+		"UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE id = ? RETURNING failed_login_attempts",
+		id,
+	).Scan(&attempts).Error
+	return attempts, err
+}
+
+// LockUntil locks the account for logins until the given time
+func (r *UserRepository) LockUntil(id uuid.UUID, until time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("locked_until", until).Error
+}
+
+// ResetFailedLogins clears failed attempts and any lock after a successful login
+func (r *UserRepository) ResetFailedLogins(id uuid.UUID) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error
+}
+
+// SetLeaderboardOptOut hides or shows the user on leaderboards without touching other columns
+func (r *UserRepository) SetLeaderboardOptOut(id uuid.UUID, optOut bool) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("leaderboard_opt_out", optOut).Error
 }
 
 // Delete deletes a user
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -95,3 +96,29 @@ func (r *VoucherRepository) GetActiveRedemptionsByUserAndVoucher(userID, voucher
 		Find(&redemptions).Error
 	return redemptions, err
 }
+
+// ExpireStaleRedemptions marks active redemptions past their expiry as expired
+func (r *VoucherRepository) ExpireStaleRedemptions(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.VoucherRedemption{}).
+		Where("status = ? AND expires_at <= ?", "active", now).
+		Update("status", "expired")
+	return result.RowsAffected, result.Error
+}
+
+// DeactivateExpiredVouchers turns off active vouchers whose validity has ended
+func (r *VoucherRepository) DeactivateExpiredVouchers(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Voucher{}).
+		Where("is_active = ? AND valid_until < ?", true, now).
+		Update("is_active", false)
+	return result.RowsAffected, result.Error
+}
+
+// FindRedemptionsExpiringBefore retrieves active redemptions that expire before the given time
+func (r *VoucherRepository) FindRedemptionsExpiringBefore(before time.Time) ([]models.VoucherRedemption, error) {
+	var redemptions []models.VoucherRedemption
+	err := r.db.Preload("Voucher").
+		Where("status = ? AND expires_at > ? AND expires_at <= ?", "active", time.Now(), before).
+		Order("expires_at ASC").
+		Find(&redemptions).Error
+	return redemptions, err
+}
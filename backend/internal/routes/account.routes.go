@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterAccountRoutes(router *gin.RouterGroup, accountHandler *handler.AccountHandler, jwtConfig *config.JWTConfig) {
+	account := router.Group("/auth/me")
+	account.Use(middleware.AuthMiddleware(jwtConfig))
+	{
+		account.GET("/export", accountHandler.ExportData)
+		account.DELETE("", accountHandler.DeleteAccount)
+		account.POST("/cancel-deletion", accountHandler.CancelDeletion)
+	}
+}
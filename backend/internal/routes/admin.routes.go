@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterAdminRoutes(router *gin.RouterGroup, pointRuleHandler *handler.PointRuleHandler, aiCacheHandler *handler.AICacheHandler, foodHandler *handler.FoodHandler, jwtConfig *config.JWTConfig, isAdmin middleware.AdminChecker) {
+	admin := router.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(jwtConfig), middleware.RequireAdmin(isAdmin))
+	{
+		// Point earning rules
+		admin.GET("/point-rules", pointRuleHandler.GetPointRules)
+		admin.POST("/point-rules", pointRuleHandler.CreatePointRule)
+		admin.PUT("/point-rules/:id", pointRuleHandler.UpdatePointRule)
+		admin.DELETE("/point-rules/:id", pointRuleHandler.DeletePointRule)
+
+		// AI response cache
+		admin.GET("/ai-cache", aiCacheHandler.GetStats)
+		admin.DELETE("/ai-cache", aiCacheHandler.Purge)
+
+		// Scanner quality
+		admin.GET("/scan-accuracy", foodHandler.GetAllScanAccuracy)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterAnalyticsRoutes(router *gin.RouterGroup, analyticsHandler *handler.AnalyticsHandler, jwtConfig *config.JWTConfig) {
+	analytics := router.Group("/analytics")
+	analytics.Use(middleware.AuthMiddleware(jwtConfig))
+	{
+		analytics.GET("/foods-added", analyticsHandler.GetFoodsAdded)
+		analytics.GET("/consumption-time", analyticsHandler.GetConsumptionTime)
+		analytics.GET("/expiry-rate", analyticsHandler.GetExpiryRate)
+		analytics.GET("/spending", analyticsHandler.GetSpending)
+		analytics.GET("/points", analyticsHandler.GetPointsBySource)
+	}
+}
@@ -7,12 +7,19 @@ import (
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 )
 
-func RegisterAuthRoutes(router *gin.RouterGroup, authHandler *handler.AuthHandler, jwtConfig *config.JWTConfig) {
+func RegisterAuthRoutes(router *gin.RouterGroup, authHandler *handler.AuthHandler, jwtConfig *config.JWTConfig, rateLimit *config.RateLimitConfig) {
 	auth := router.Group("/auth")
 	{
+		// Credential endpoints share one per-IP limit against brute force
+		credentialLimit := middleware.RateLimitMiddleware(rateLimit.Login)
+
 		// Public routes
 		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
+		auth.POST("/login", credentialLimit, authHandler.Login)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/forgot-password", credentialLimit, authHandler.ForgotPassword)
+		auth.POST("/reset-password", credentialLimit, authHandler.ResetPassword)
 
 		// Protected routes
 		protected := auth.Group("")
@@ -20,6 +27,14 @@ func RegisterAuthRoutes(router *gin.RouterGroup, authHandler *handler.AuthHandle
 		{
 			protected.GET("/me", authHandler.GetProfile)
 			protected.PUT("/profile", authHandler.UpdateProfile)
+			protected.PUT("/password", authHandler.ChangePassword)
+			protected.POST("/verify-email/resend", authHandler.ResendVerificationEmail)
+			protected.POST("/logout", authHandler.Logout)
+
+			// Sessions
+			protected.GET("/sessions", authHandler.GetSessions)
+			protected.DELETE("/sessions", authHandler.RevokeOtherSessions)
+			protected.DELETE("/sessions/:id", authHandler.RevokeSession)
 		}
 	}
 }
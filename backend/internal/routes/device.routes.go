@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterDeviceRoutes(router *gin.RouterGroup, deviceHandler *handler.DeviceHandler, jwtConfig *config.JWTConfig) {
+	devices := router.Group("/devices")
+	devices.Use(middleware.AuthMiddleware(jwtConfig))
+	{
+		devices.POST("", deviceHandler.RegisterDevice)
+		devices.GET("", deviceHandler.GetDevices)
+		devices.DELETE("/:id", deviceHandler.UnregisterDevice)
+
+		// Quiet hours
+		devices.GET("/quiet-hours", deviceHandler.GetQuietHours)
+		devices.PUT("/quiet-hours", deviceHandler.SetQuietHours)
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 )
 
-func RegisterFoodRoutes(router *gin.RouterGroup, foodHandler *handler.FoodHandler, jwtConfig *config.JWTConfig) {
+func RegisterFoodRoutes(router *gin.RouterGroup, foodHandler *handler.FoodHandler, jwtConfig *config.JWTConfig, rateLimit *config.RateLimitConfig) {
 	foods := router.Group("/foods")
 	foods.Use(middleware.AuthMiddleware(jwtConfig))
 	{
@@ -18,12 +18,20 @@ func RegisterFoodRoutes(router *gin.RouterGroup, foodHandler *handler.FoodHandle
 		foods.PUT("/:id", foodHandler.UpdateFood)
 		foods.DELETE("/:id", foodHandler.DeleteFood)
 
-		// Scanning
-		foods.POST("/scan", foodHandler.ScanFood)
+		// Scanning (Gemini-backed, rate limited per user)
+		foods.POST("/scan", middleware.RateLimitMiddleware(rateLimit.Scan), foodHandler.ScanFood)
 		foods.POST("/add-scanned", foodHandler.AddScannedFood)
+		foods.GET("/scan/accuracy", foodHandler.GetScanAccuracy)
+		foods.GET("/scan/aliases", foodHandler.GetScanAliases)
+		foods.DELETE("/scan/aliases/:id", foodHandler.DeleteScanAlias)
 		foods.GET("/check-duplicate", foodHandler.CheckDuplicate)
 		foods.PATCH("/:id/stock", foodHandler.UpdateStock)
 
+		// State transitions that recalculate the expiry date
+		foods.POST("/:id/open", foodHandler.OpenFood)
+		foods.POST("/:id/move", foodHandler.MoveFood)
+		foods.GET("/:id/events", foodHandler.GetFoodEvents)
+
 		// Seed dummy data (development only)
 		foods.POST("/seed-dummy", foodHandler.SeedDummyFoods)
 
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterGamificationRoutes(router *gin.RouterGroup, gamificationHandler *handler.GamificationHandler, jwtConfig *config.JWTConfig) {
+	rewards := router.Group("/rewards")
+	rewards.Use(middleware.AuthMiddleware(jwtConfig))
+	{
+		rewards.GET("/achievements", gamificationHandler.GetAchievements)
+		rewards.GET("/streaks", gamificationHandler.GetStreaks)
+		rewards.GET("/leaderboard", gamificationHandler.GetLeaderboard)
+		rewards.PUT("/leaderboard/privacy", gamificationHandler.UpdateLeaderboardPrivacy)
+	}
+}
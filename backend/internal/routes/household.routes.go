@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterHouseholdRoutes(router *gin.RouterGroup, householdHandler *handler.HouseholdHandler, jwtConfig *config.JWTConfig) {
+	households := router.Group("/households")
+	households.Use(middleware.AuthMiddleware(jwtConfig))
+	{
+		households.POST("", householdHandler.CreateHousehold)
+		households.GET("/me", householdHandler.GetMyHousehold)
+		households.POST("/join", householdHandler.JoinHousehold)
+		households.POST("/leave", householdHandler.LeaveHousehold)
+	}
+}
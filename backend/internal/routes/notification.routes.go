@@ -13,6 +13,12 @@ func RegisterNotificationRoutes(router *gin.RouterGroup, notificationHandler *ha
 	{
 		notifications.GET("", notificationHandler.GetNotifications)
 		notifications.GET("/expiring", notificationHandler.GetExpiringNotifications)
+		notifications.GET("/unread-count", notificationHandler.GetUnreadCount)
+		notifications.GET("/preferences", notificationHandler.GetPreferences)
+		notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+		notifications.POST("/read-all", notificationHandler.MarkAllAsRead)
 		notifications.POST("/:id/read", notificationHandler.MarkNotificationAsRead)
+		notifications.POST("/:id/unread", notificationHandler.MarkNotificationAsUnread)
+		notifications.DELETE("/:id", notificationHandler.DismissNotification)
 	}
 }
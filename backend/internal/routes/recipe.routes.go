@@ -7,7 +7,7 @@ import (
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 )
 
-func RegisterRecipeRoutes(router *gin.RouterGroup, recipeHandler *handler.RecipeHandler, jwtConfig *config.JWTConfig) {
+func RegisterRecipeRoutes(router *gin.RouterGroup, recipeHandler *handler.RecipeHandler, jwtConfig *config.JWTConfig, rateLimit *config.RateLimitConfig) {
 	recipes := router.Group("/recipes")
 	recipes.Use(middleware.AuthMiddleware(jwtConfig))
 	{
@@ -16,7 +16,7 @@ func RegisterRecipeRoutes(router *gin.RouterGroup, recipeHandler *handler.Recipe
 		recipes.GET("/category", recipeHandler.GetRecipesByCategory)
 		recipes.GET("/search", recipeHandler.SearchRecipes)
 		recipes.GET("/dietary", recipeHandler.GetRecipesByDietary)
-		recipes.GET("/recommended", recipeHandler.GetRecommendedRecipes)
+		recipes.GET("/recommended", middleware.RateLimitMiddleware(rateLimit.Recommend), recipeHandler.GetRecommendedRecipes)
 
 		// Fetch directly from Yummy.co.id (no import/save)
 		recipes.GET("/yummy", recipeHandler.GetYummyRecipes)
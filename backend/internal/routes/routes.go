@@ -1,19 +1,35 @@
 package routes
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/varel183/MakanSikScan/backend/internal/config"
 	"github.com/varel183/MakanSikScan/backend/internal/handler"
 	"github.com/varel183/MakanSikScan/backend/internal/middleware"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
 	"github.com/varel183/MakanSikScan/backend/internal/service"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
 	"gorm.io/gorm"
 )
 
-// SetupRoutes initializes all routes and dependencies
-func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
+// Background holds the long-running components whose lifecycle is controlled by main
+type Background struct {
+	Scheduler *service.Scheduler
+	EventHub  *service.EventHub
+}
+
+// SetupRoutes initializes all routes and dependencies.
+// It returns the background components so the caller controls their lifecycle.
+func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) *Background {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	authTokenRepo := repository.NewAuthTokenRepository(db)
+	accountRepo := repository.NewAccountRepository(db)
 	foodRepo := repository.NewFoodRepository(db)
 	donationRepo := repository.NewDonationRepository(db)
 	recipeRepo := repository.NewRecipeRepository(db)
@@ -21,24 +37,55 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	rewardRepo := repository.NewRewardRepository(db)
 	voucherRepo := repository.NewVoucherRepository(db)
 	notifReadRepo := repository.NewNotificationReadRepository(db)
+	notificationPrefRepo := repository.NewNotificationPreferenceRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	deviceRepo := repository.NewDeviceRepository(db)
 	supermarketRepo := repository.NewSupermarketRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	gamificationRepo := repository.NewGamificationRepository(db)
+	householdRepo := repository.NewHouseholdRepository(db)
+	pointRuleRepo := repository.NewPointRuleRepository(db)
+	uploadRepo := repository.NewUploadRepository(db)
+	scanRepo := repository.NewScanRepository(db)
+
+	blobStore, err := service.NewBlobStore(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+	expiryService, err := service.NewExpiryService(&cfg.Expiry)
+	if err != nil {
+		log.Fatalf("Failed to load expiry rules: %v", err)
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg)
-	foodService := service.NewFoodService(foodRepo, rewardRepo)
-	scannerService := service.NewScannerService(cfg)
+	eventHub := service.NewEventHub()
+	uploadService := service.NewUploadService(uploadRepo, blobStore, &cfg.Storage)
+	pushService := service.NewPushService(deviceRepo, service.NewNotifier(&cfg.Push), &cfg.Push)
+	authService := service.NewAuthService(userRepo, sessionRepo, authTokenRepo, service.NewMailer(&cfg.Mail), uploadService, cfg)
+	rewardService := service.NewRewardService(rewardRepo, pointRuleRepo, &cfg.Reward)
+	gamificationService := service.NewGamificationService(gamificationRepo, householdRepo, userRepo, rewardService, uploadService, eventHub)
+	// One Gemini client, so every AI feature shares the retry budget and circuit breaker
 	geminiService := service.NewGeminiService(cfg)
-	donationService := service.NewDonationService(donationRepo, foodRepo, userRepo, rewardRepo)
+	foodService := service.NewFoodService(foodRepo, rewardService, gamificationService, uploadService, expiryService, eventHub)
+	scannerService := service.NewScannerService(cfg, userRepo, scanRepo, geminiService, uploadService, expiryService)
+	donationService := service.NewDonationService(donationRepo, foodRepo, userRepo, rewardService, gamificationService)
 	yummyService := service.NewYummyService(recipeRepo, geminiService, cfg)
-	recipeService := service.NewRecipeService(recipeRepo, foodRepo, yummyService, cfg)
-	cartService := service.NewCartService(cartRepo)
-	rewardService := service.NewRewardService(rewardRepo)
+	recipeService := service.NewRecipeService(recipeRepo, foodRepo, userRepo, geminiService, yummyService, cfg)
+	cartService := service.NewCartService(cartRepo, eventHub)
 	voucherService := service.NewVoucherService(voucherRepo, rewardRepo)
-	notificationService := service.NewNotificationService(foodRepo, notifReadRepo)
-	supermarketService := service.NewSupermarketService(supermarketRepo, transactionRepo, foodRepo)
-	orderService := service.NewOrderService(orderRepo, voucherRepo, foodRepo)
+	notificationService := service.NewNotificationService(notificationRepo, foodRepo, orderRepo, voucherRepo, notifReadRepo, notificationPrefRepo, pushService, eventHub)
+	supermarketService := service.NewSupermarketService(supermarketRepo, transactionRepo, foodRepo, expiryService)
+	orderService := service.NewOrderService(orderRepo, voucherRepo, foodRepo, supermarketRepo, foodService, pushService, expiryService, eventHub)
+	analyticsService := service.NewAnalyticsService(analyticsRepo)
+	accountService := service.NewAccountService(accountRepo, userRepo, sessionRepo, uploadService, &cfg.Account)
+	householdService := service.NewHouseholdService(householdRepo, uploadService)
+	scheduler := service.NewScheduler(jobRepo, &cfg.Scheduler)
+
+	// Reject access tokens of revoked sessions
+	utils.SetSessionChecker(authService.CheckSession)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
@@ -51,6 +98,16 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	supermarketHandler := handler.NewSupermarketHandler(supermarketService)
 	orderHandler := handler.NewOrderHandler(orderService)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
+	deviceHandler := handler.NewDeviceHandler(pushService)
+	streamHandler := handler.NewStreamHandler(eventHub)
+	accountHandler := handler.NewAccountHandler(accountService)
+	gamificationHandler := handler.NewGamificationHandler(gamificationService)
+	householdHandler := handler.NewHouseholdHandler(householdService)
+	pointRuleHandler := handler.NewPointRuleHandler(rewardService)
+	aiCacheHandler := handler.NewAICacheHandler(geminiService)
+	localStore, _ := blobStore.(*service.LocalBlobStore)
+	uploadHandler := handler.NewUploadHandler(uploadService, localStore, cfg.Storage.MaxUploadSize)
 
 	// Apply CORS middleware
 	router.Use(middleware.CORSMiddleware())
@@ -75,16 +132,24 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		})
 
 		// Register module routes
-		RegisterAuthRoutes(v1, authHandler, &cfg.JWT)
-		RegisterFoodRoutes(v1, foodHandler, &cfg.JWT)
+		RegisterAuthRoutes(v1, authHandler, &cfg.JWT, &cfg.RateLimit)
+		RegisterFoodRoutes(v1, foodHandler, &cfg.JWT, &cfg.RateLimit)
 		RegisterDonationRoutes(v1, donationHandler, &cfg.JWT)
-		RegisterRecipeRoutes(v1, recipeHandler, &cfg.JWT)
+		RegisterRecipeRoutes(v1, recipeHandler, &cfg.JWT, &cfg.RateLimit)
 		RegisterCartRoutes(v1, cartHandler, &cfg.JWT)
 		RegisterRewardRoutes(v1, rewardHandler, &cfg.JWT)
 		SetupVoucherRoutes(v1, voucherHandler, &cfg.JWT)
 		RegisterNotificationRoutes(v1, notificationHandler, &cfg.JWT)
 		RegisterSupermarketRoutes(v1, supermarketHandler, &cfg.JWT)
 		RegisterOrderRoutes(v1, orderHandler, &cfg.JWT)
+		RegisterAnalyticsRoutes(v1, analyticsHandler, &cfg.JWT)
+		RegisterDeviceRoutes(v1, deviceHandler, &cfg.JWT)
+		RegisterStreamRoutes(v1, streamHandler, &cfg.JWT)
+		RegisterAccountRoutes(v1, accountHandler, &cfg.JWT)
+		RegisterGamificationRoutes(v1, gamificationHandler, &cfg.JWT)
+		RegisterHouseholdRoutes(v1, householdHandler, &cfg.JWT)
+		RegisterAdminRoutes(v1, pointRuleHandler, aiCacheHandler, foodHandler, &cfg.JWT, authService.IsAdmin)
+		RegisterUploadRoutes(v1, uploadHandler, &cfg.JWT)
 	} // 404 handler
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(404, gin.H{
@@ -92,4 +157,54 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 			"message": "Route not found",
 		})
 	})
+
+	// Background jobs
+	scheduler.MustRegister("notifications", everySpec(cfg.Notification.GenerateInterval, 15*time.Minute), func(ctx context.Context) (string, error) {
+		// Resend pushes deferred by quiet hours or failed earlier
+		pushService.RetryPending(ctx)
+
+		created, err := notificationService.GenerateNotifications(ctx)
+		return fmt.Sprintf("generated %d notifications", created), err
+	})
+	scheduler.MustRegister("account-purge", everySpec(cfg.Account.PurgeInterval, time.Hour), func(ctx context.Context) (string, error) {
+		purged, err := accountService.PurgeDueAccounts(ctx)
+		return fmt.Sprintf("deleted %d accounts", purged), err
+	})
+	scheduler.MustRegister("expire-redemptions", cfg.Scheduler.ExpireRedemptions, func(ctx context.Context) (string, error) {
+		expired, err := voucherService.ExpireStaleRedemptions(ctx)
+		return fmt.Sprintf("expired %d redemptions", expired), err
+	})
+	scheduler.MustRegister("deactivate-vouchers", cfg.Scheduler.DeactivateVouchers, func(ctx context.Context) (string, error) {
+		deactivated, err := voucherService.DeactivateExpiredVouchers(ctx)
+		return fmt.Sprintf("deactivated %d vouchers", deactivated), err
+	})
+	scheduler.MustRegister("expire-points", cfg.Scheduler.ExpirePoints, func(ctx context.Context) (string, error) {
+		lots, points, err := rewardService.ExpirePoints(ctx)
+		return fmt.Sprintf("expired %d points from %d lots", points, lots), err
+	})
+	scheduler.MustRegister("no-waste-streaks", cfg.Scheduler.NoWasteStreaks, func(ctx context.Context) (string, error) {
+		updated, err := gamificationService.UpdateNoWasteStreaks(ctx, time.Now().AddDate(0, 0, -1))
+		return fmt.Sprintf("updated %d no-waste streaks", updated), err
+	})
+	scheduler.MustRegister("prune-notification-reads", cfg.Scheduler.PruneNotificationReads, func(ctx context.Context) (string, error) {
+		deleted, err := notifReadRepo.DeleteOldReads(ctx, time.Now().Add(-cfg.Scheduler.NotificationReadRetention))
+		return fmt.Sprintf("deleted %d read markers", deleted), err
+	})
+	scheduler.MustRegister("prune-job-runs", cfg.Scheduler.PruneJobRuns, func(ctx context.Context) (string, error) {
+		deleted, err := jobRepo.DeleteRunsBefore(ctx, time.Now().Add(-cfg.Scheduler.JobRunRetention))
+		return fmt.Sprintf("deleted %d job runs", deleted), err
+	})
+
+	return &Background{
+		Scheduler: scheduler,
+		EventHub:  eventHub,
+	}
+}
+
+// everySpec turns an interval setting into an @every schedule
+func everySpec(interval, fallback time.Duration) string {
+	if interval <= 0 {
+		interval = fallback
+	}
+	return "@every " + interval.String()
 }
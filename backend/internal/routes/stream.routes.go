@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterStreamRoutes(router *gin.RouterGroup, streamHandler *handler.StreamHandler, jwtConfig *config.JWTConfig) {
+	stream := router.Group("/stream")
+	stream.Use(middleware.AuthMiddleware(jwtConfig))
+	{
+		stream.GET("", streamHandler.Stream)
+	}
+}
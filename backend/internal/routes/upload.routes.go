@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/handler"
+	"github.com/varel183/MakanSikScan/backend/internal/middleware"
+)
+
+func RegisterUploadRoutes(router *gin.RouterGroup, uploadHandler *handler.UploadHandler, jwtConfig *config.JWTConfig) {
+	uploads := router.Group("/uploads")
+	{
+		// Signed URLs authorize file downloads, so image tags work without a token
+		uploads.GET("/files/*key", uploadHandler.ServeFile)
+
+		uploads.POST("", middleware.AuthMiddleware(jwtConfig), uploadHandler.Upload)
+	}
+}
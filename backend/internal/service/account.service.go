@@ -0,0 +1,183 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type AccountService struct {
+	accountRepo   *repository.AccountRepository
+	userRepo      *repository.UserRepository
+	sessionRepo   *repository.SessionRepository
+	uploadService *UploadService
+	gracePeriod   time.Duration
+}
+
+func NewAccountService(accountRepo *repository.AccountRepository, userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, uploadService *UploadService, cfg *config.AccountConfig) *AccountService {
+	return &AccountService{
+		accountRepo:   accountRepo,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		uploadService: uploadService,
+		gracePeriod:   cfg.DeletionGracePeriod,
+	}
+}
+
+// exportedDevice is a device without its push token, which works like a credential
+type exportedDevice struct {
+	ID         uuid.UUID `json:"id"`
+	Platform   string    `json:"platform"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportData returns a zip archive with one JSON file per kind of data stored about the user
+func (s *AccountService) ExportData(userID uuid.UUID) ([]byte, error) {
+	data, err := s.accountRepo.ExportData(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]exportedDevice, len(data.Devices))
+	for i, device := range data.Devices {
+		devices[i] = exportedDevice{
+			ID:         device.ID,
+			Platform:   device.Platform,
+			LastSeenAt: device.LastSeenAt,
+			CreatedAt:  device.CreatedAt,
+		}
+	}
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", data.User},
+		{"foods.json", data.Foods},
+		{"carts.json", data.Carts},
+		{"donations.json", data.Donations},
+		{"orders.json", data.Orders},
+		{"transactions.json", data.Transactions},
+		{"points.json", data.Points},
+		{"point_transactions.json", data.PointTransactions},
+		{"voucher_redemptions.json", data.Redemptions},
+		{"notifications.json", data.Notifications},
+		{"notification_preferences.json", data.NotificationPreferences},
+		{"devices.json", devices},
+		{"quiet_hours.json", data.QuietHours},
+		{"sessions.json", data.Sessions},
+		{"activities.json", data.Activities},
+		{"streaks.json", data.Streaks},
+		{"achievements.json", data.Achievements},
+		{"uploads.json", data.Uploads},
+		{"scan_records.json", data.ScanRecords},
+		{"scan_aliases.json", data.ScanAliases},
+		{"food_events.json", data.FoodEvents},
+		{"food_lots.json", data.FoodLots},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := archive.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(f.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RequestDeletion schedules the account for deletion after the grace period and signs out
+// every device. Logging in again during the grace period allows cancelling.
+func (s *AccountService) RequestDeletion(userID uuid.UUID, password string) (*time.Time, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.ComparePassword(user.Password, password); err != nil {
+		return nil, errors.New("password is incorrect")
+	}
+	if user.DeletionScheduledAt != nil {
+		return user.DeletionScheduledAt, nil
+	}
+
+	scheduledAt := time.Now().Add(s.gracePeriod)
+	if err := s.accountRepo.ScheduleDeletion(userID, &scheduledAt); err != nil {
+		return nil, err
+	}
+
+	// Revoking the sessions invalidates their access and refresh tokens
+	if _, err := s.sessionRepo.RevokeAllExcept(userID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🗑️  Account %s scheduled for deletion at %s", userID, scheduledAt.Format(time.RFC3339))
+	return &scheduledAt, nil
+}
+
+// CancelDeletion keeps the account if it is still within the grace period
+func (s *AccountService) CancelDeletion(userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.DeletionScheduledAt == nil {
+		return errors.New("account deletion not requested")
+	}
+
+	return s.accountRepo.ScheduleDeletion(userID, nil)
+}
+
+// PurgeDueAccounts deletes the data of every account whose grace period has ended
+func (s *AccountService) PurgeDueAccounts(ctx context.Context) (int, error) {
+	ids, err := s.accountRepo.FindDueForDeletion(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		// Files first, the upload records are needed to find them
+		if err := s.uploadService.DeleteUserFiles(ctx, id); err != nil {
+			log.Printf("❌ Failed to delete files of account %s: %v", id, err)
+			continue
+		}
+		if err := s.accountRepo.Purge(id); err != nil {
+			log.Printf("❌ Failed to delete account %s: %v", id, err)
+			continue
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		log.Printf("🗑️  Deleted %d accounts after their grace period", purged)
+	}
+	return purged, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Kinds of cached AI responses
+const (
+	aiCacheScan = "scan"
+)
+
+// aiCache is an in-memory LRU of model responses. Entries expire after the TTL and the
+// least recently used entry is evicted once the cache is full. A nil cache caches nothing.
+type aiCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    *list.List // front is most recently used
+	byKey      map[string]*list.Element
+	counters   map[string]*AICacheCounter
+	evictions  int64
+}
+
+type aiCacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// AICacheCounter counts lookups of one kind of response
+type AICacheCounter struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// AICacheStats describes the response cache
+type AICacheStats struct {
+	Enabled    bool                      `json:"enabled"`
+	Entries    int                       `json:"entries"`
+	MaxEntries int                       `json:"max_entries"`
+	TTLSeconds int64                     `json:"ttl_seconds"`
+	Hits       int64                     `json:"hits"`
+	Misses     int64                     `json:"misses"`
+	HitRate    float64                   `json:"hit_rate"`
+	Evictions  int64                     `json:"evictions"`
+	Kinds      map[string]AICacheCounter `json:"kinds"`
+}
+
+// newAICache returns nil, which disables caching, when ttl or maxEntries is not positive
+func newAICache(ttl time.Duration, maxEntries int) *aiCache {
+	if ttl <= 0 || maxEntries <= 0 {
+		return nil
+	}
+	return &aiCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    list.New(),
+		byKey:      map[string]*list.Element{},
+		counters:   map[string]*AICacheCounter{},
+	}
+}
+
+// get returns the cached value of a key and counts the lookup as a hit or miss of kind
+func (c *aiCache) get(kind, key string, now time.Time) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counter := c.counter(kind)
+	element, ok := c.byKey[key]
+	if ok && now.Before(element.Value.(*aiCacheEntry).expiresAt) {
+		c.entries.MoveToFront(element)
+		counter.Hits++
+		return element.Value.(*aiCacheEntry).value, true
+	}
+	if ok {
+		c.remove(element)
+	}
+	counter.Misses++
+	return nil, false
+}
+
+// set stores a value, evicting the least recently used entries when the cache is full
+func (c *aiCache) set(key string, value interface{}, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.byKey[key]; ok {
+		entry := element.Value.(*aiCacheEntry)
+		entry.value, entry.expiresAt = value, now.Add(c.ttl)
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.byKey[key] = c.entries.PushFront(&aiCacheEntry{key: key, value: value, expiresAt: now.Add(c.ttl)})
+	for c.entries.Len() > c.maxEntries {
+		c.remove(c.entries.Back())
+		c.evictions++
+	}
+}
+
+// purge drops every entry, counters are kept
+func (c *aiCache) purge() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := c.entries.Len()
+	c.entries.Init()
+	c.byKey = map[string]*list.Element{}
+	return removed
+}
+
+func (c *aiCache) stats() AICacheStats {
+	if c == nil {
+		return AICacheStats{Kinds: map[string]AICacheCounter{}}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := AICacheStats{
+		Enabled:    true,
+		Entries:    c.entries.Len(),
+		MaxEntries: c.maxEntries,
+		TTLSeconds: int64(c.ttl.Seconds()),
+		Evictions:  c.evictions,
+		Kinds:      make(map[string]AICacheCounter, len(c.counters)),
+	}
+	for kind, counter := range c.counters {
+		stats.Kinds[kind] = *counter
+		stats.Hits += counter.Hits
+		stats.Misses += counter.Misses
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+func (c *aiCache) counter(kind string) *AICacheCounter {
+	counter, ok := c.counters[kind]
+	if !ok {
+		counter = &AICacheCounter{}
+		c.counters[kind] = counter
+	}
+	return counter
+}
+
+func (c *aiCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.byKey, element.Value.(*aiCacheEntry).key)
+}
+
+type aiCacheBypassKey struct{}
+
+// WithoutAICache makes AI calls made with the returned context skip the response cache.
+// Fresh responses are still stored, so they replace the cached ones.
+func WithoutAICache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, aiCacheBypassKey{}, true)
+}
+
+func aiCacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(aiCacheBypassKey{}).(bool)
+	return bypass
+}
+
+// scanCacheKey identifies a scan by the image content and the prompt that analysed it
+func scanCacheKey(image []byte, promptVersion string) string {
+	sum := sha256.Sum256(image)
+	return aiCacheScan + ":" + promptVersion + ":" + hex.EncodeToString(sum[:])
+}
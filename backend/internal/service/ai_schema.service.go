@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+// ErrInvalidAIResponse is returned when the model's output still fails validation after a corrective retry
+var ErrInvalidAIResponse = errors.New("AI returned an invalid response")
+
+type aiFieldType int
+
+const (
+	aiString aiFieldType = iota
+	aiNumber
+	aiInteger
+	aiBoolean
+	aiStringList
+	aiStringMap
+)
+
+// aiField describes one field of the JSON objects a prompt asks for
+type aiField struct {
+	Name      string
+	Type      aiFieldType
+	Required  bool
+	Enum      []string
+	Normalize func(string) (string, bool) // maps other spellings onto Enum
+	Min, Max  float64                     // range of numeric fields
+}
+
+// aiSchema is the expected shape of a model response. Output is checked and
+// normalised against it before it is decoded, and it is sent to Gemini as the
+// response schema when it can be expressed there.
+type aiSchema struct {
+	Name   string // task, used in logs
+	Fields []aiField
+	List   bool // a JSON array of objects instead of one object
+}
+
+var foodScanSchema = &aiSchema{
+	Name: "food scan",
+	Fields: []aiField{
+		{Name: "name", Type: aiString, Required: true},
+		{Name: "category", Type: aiString, Required: true, Enum: models.FoodCategories, Normalize: models.NormalizeFoodCategory},
+		{Name: "confidence", Type: aiNumber, Required: true, Min: 0, Max: 100},
+		{Name: "calories", Type: aiNumber, Required: true, Min: 0, Max: 900},
+		{Name: "protein", Type: aiNumber, Required: true, Min: 0, Max: 100},
+		{Name: "carbohydrates", Type: aiNumber, Required: true, Min: 0, Max: 100},
+		{Name: "fat", Type: aiNumber, Required: true, Min: 0, Max: 100},
+		{Name: "is_halal", Type: aiBoolean, Required: true},
+		{Name: "expiry_days", Type: aiInteger, Required: true, Min: 0, Max: 3650},
+		{Name: "storage_tips", Type: aiString},
+	},
+}
+
+var recipeRecommendationSchema = &aiSchema{
+	Name: "recipe recommendations",
+	List: true,
+	Fields: []aiField{
+		{Name: "title", Type: aiString, Required: true},
+		{Name: "description", Type: aiString},
+		{Name: "ingredients", Type: aiStringMap, Required: true},
+		{Name: "instructions", Type: aiStringList, Required: true},
+		{Name: "prep_time", Type: aiInteger, Required: true, Min: 0, Max: 1440},
+		{Name: "cook_time", Type: aiInteger, Required: true, Min: 0, Max: 1440},
+		{Name: "servings", Type: aiInteger, Required: true, Min: 1, Max: 100},
+		{Name: "difficulty", Type: aiString, Required: true, Enum: []string{"Easy", "Medium", "Hard"}},
+		{Name: "category", Type: aiString, Required: true, Enum: []string{"Breakfast", "Lunch", "Dinner", "Snack", "Dessert"}},
+		{Name: "cuisine", Type: aiString},
+		{Name: "calories", Type: aiNumber, Min: 0, Max: 5000},
+		{Name: "protein", Type: aiNumber, Min: 0, Max: 500},
+		{Name: "carbs", Type: aiNumber, Min: 0, Max: 500},
+		{Name: "fat", Type: aiNumber, Min: 0, Max: 500},
+		{Name: "is_halal", Type: aiBoolean, Required: true},
+		{Name: "is_vegetarian", Type: aiBoolean, Required: true},
+		{Name: "is_vegan", Type: aiBoolean, Required: true},
+		{Name: "match_percentage", Type: aiNumber, Required: true, Min: 0, Max: 100},
+		{Name: "missing_items", Type: aiStringList},
+		{Name: "tips", Type: aiString},
+	},
+}
+
+var nutritionAnalysisSchema = &aiSchema{
+	Name: "nutrition analysis",
+	Fields: []aiField{
+		{Name: "total_calories", Type: aiNumber, Required: true, Min: 0, Max: 20000},
+		{Name: "total_protein", Type: aiNumber, Required: true, Min: 0, Max: 2000},
+		{Name: "total_carbs", Type: aiNumber, Required: true, Min: 0, Max: 2000},
+		{Name: "total_fat", Type: aiNumber, Required: true, Min: 0, Max: 2000},
+		{Name: "calorie_goal", Type: aiNumber, Required: true, Min: 500, Max: 10000},
+		{Name: "protein_goal", Type: aiNumber, Required: true, Min: 0, Max: 1000},
+		{Name: "carbs_goal", Type: aiNumber, Required: true, Min: 0, Max: 2000},
+		{Name: "fat_goal", Type: aiNumber, Required: true, Min: 0, Max: 1000},
+		{Name: "calorie_status", Type: aiString, Required: true, Enum: []string{"deficit", "balanced", "surplus"}},
+		{Name: "recommendations", Type: aiStringList, Required: true},
+		{Name: "meal_distribution", Type: aiStringMap},
+		{Name: "health_score", Type: aiInteger, Required: true, Min: 0, Max: 100},
+		{Name: "warnings", Type: aiStringList},
+	},
+}
+
+// generateJSON asks the model for JSON in the shape of schema and decodes it into dest.
+// Output that fails validation is sent back once with the problems so the model can fix it.
+func (s *GeminiService) generateJSON(ctx context.Context, schema *aiSchema, dest interface{}, parts ...genai.Part) error {
+	text, err := s.generate(ctx, schema, parts...)
+	if err != nil {
+		return err
+	}
+
+	normalized, problems := schema.check(s.extractJSON(text))
+	if len(problems) > 0 {
+		log.Printf("⚠️  Invalid Gemini %s response, asking for a correction: %s", schema.Name, strings.Join(problems, "; "))
+
+		corrective := append(append([]genai.Part{}, parts...), genai.Text(fmt.Sprintf(
+			"Your previous response was:\n%s\n\nIt is invalid because:\n- %s\n\nReturn the corrected JSON only, with every problem fixed.",
+			text, strings.Join(problems, "\n- "))))
+		text, err = s.generate(ctx, schema, corrective...)
+		if err != nil {
+			return err
+		}
+
+		normalized, problems = schema.check(s.extractJSON(text))
+		if len(problems) > 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidAIResponse, strings.Join(problems, "; "))
+		}
+	}
+
+	return json.Unmarshal(normalized, dest)
+}
+
+// check validates a response and returns it re-encoded with normalised values
+func (schema *aiSchema) check(text string) ([]byte, []string) {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		return nil, []string{"response is not valid JSON: " + err.Error()}
+	}
+
+	var problems []string
+	if schema.List {
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, []string{"response must be a JSON array"}
+		}
+		if len(items) == 0 {
+			return nil, []string{"response array is empty"}
+		}
+		for i, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("item %d must be an object", i))
+				continue
+			}
+			problems = append(problems, schema.checkObject(obj, fmt.Sprintf("item %d: ", i))...)
+		}
+	} else {
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, []string{"response must be a JSON object"}
+		}
+		problems = schema.checkObject(obj, "")
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	return normalized, nil
+}
+
+// checkObject validates the fields of one object, normalising values in place
+func (schema *aiSchema) checkObject(obj map[string]interface{}, prefix string) []string {
+	var problems []string
+	for _, field := range schema.Fields {
+		value, present := obj[field.Name]
+		if !present || value == nil {
+			if field.Required {
+				problems = append(problems, prefix+field.Name+" is required")
+			}
+			continue
+		}
+
+		normalized, problem := field.check(value)
+		if problem != "" {
+			problems = append(problems, prefix+field.Name+" "+problem)
+			continue
+		}
+		obj[field.Name] = normalized
+	}
+	return problems
+}
+
+// check validates a value of the field and returns its normalised form, or a problem
+func (field *aiField) check(value interface{}) (interface{}, string) {
+	switch field.Type {
+	case aiString:
+		str, ok := value.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		str = strings.TrimSpace(str)
+		if field.Required && str == "" {
+			return nil, "must not be empty"
+		}
+		if len(field.Enum) > 0 {
+			canonical, ok := field.canonical(str)
+			if !ok {
+				return nil, fmt.Sprintf("must be one of: %s (got %q)", strings.Join(field.Enum, ", "), str)
+			}
+			str = canonical
+		}
+		return str, ""
+
+	case aiNumber, aiInteger:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case string:
+			// Models sometimes quote numbers
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, "must be a number"
+			}
+			number = parsed
+		default:
+			return nil, "must be a number"
+		}
+		if math.IsNaN(number) || number < field.Min || number > field.Max {
+			return nil, fmt.Sprintf("must be between %g and %g (got %g)", field.Min, field.Max, number)
+		}
+		if field.Type == aiInteger {
+			number = math.Round(number)
+		}
+		return number, ""
+
+	case aiBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, ""
+		case string:
+			if parsed, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return parsed, ""
+			}
+		}
+		return nil, "must be true or false"
+
+	case aiStringList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, "must be an array of strings"
+		}
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			str, ok := item.(string)
+			if !ok {
+				return nil, "must be an array of strings"
+			}
+			if str = strings.TrimSpace(str); str != "" {
+				list = append(list, str)
+			}
+		}
+		if field.Required && len(list) == 0 {
+			return nil, "must not be empty"
+		}
+		return list, ""
+
+	case aiStringMap:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "must be an object"
+		}
+		if field.Required && len(entries) == 0 {
+			return nil, "must not be empty"
+		}
+		for key, entry := range entries {
+			if _, ok := entry.(string); !ok {
+				entries[key] = fmt.Sprint(entry)
+			}
+		}
+		return entries, ""
+	}
+	return value, ""
+}
+
+// canonical matches a value against the enum, through Normalize when set
+func (field *aiField) canonical(value string) (string, bool) {
+	if field.Normalize != nil {
+		return field.Normalize(value)
+	}
+	for _, option := range field.Enum {
+		if strings.EqualFold(option, value) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// responseSchema converts the schema for Gemini's structured output.
+// Free-form maps can't be expressed there, those schemas only use JSON mode.
+func (schema *aiSchema) responseSchema() *genai.Schema {
+	object := &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{}}
+	for _, field := range schema.Fields {
+		var property *genai.Schema
+		switch field.Type {
+		case aiString:
+			property = &genai.Schema{Type: genai.TypeString}
+			if len(field.Enum) > 0 {
+				property.Format = "enum"
+				property.Enum = field.Enum
+			}
+		case aiNumber:
+			property = &genai.Schema{Type: genai.TypeNumber}
+		case aiInteger:
+			property = &genai.Schema{Type: genai.TypeInteger}
+		case aiBoolean:
+			property = &genai.Schema{Type: genai.TypeBoolean}
+		case aiStringList:
+			property = &genai.Schema{Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}}
+		default:
+			return nil
+		}
+		object.Properties[field.Name] = property
+		if field.Required {
+			object.Required = append(object.Required, field.Name)
+		}
+	}
+
+	if schema.List {
+		return &genai.Schema{Type: genai.TypeArray, Items: object}
+	}
+	return object
+}
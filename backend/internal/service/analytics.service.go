@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+)
+
+// Supported bucket sizes for time-series analytics (postgres date_trunc units)
+var validAnalyticsBuckets = map[string]bool{
+	"day":   true,
+	"week":  true,
+	"month": true,
+	"year":  true,
+}
+
+// AnalyticsQuery describes the date range and bucket size of an analytics request
+type AnalyticsQuery struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Bucket string    `json:"bucket"`
+}
+
+type AnalyticsService struct {
+	analyticsRepo *repository.AnalyticsRepository
+}
+
+func NewAnalyticsService(analyticsRepo *repository.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+	}
+}
+
+// NewAnalyticsQuery builds a query from raw parameters (YYYY-MM-DD dates).
+// Defaults to the last 90 days bucketed per defaultBucket.
+func NewAnalyticsQuery(fromStr, toStr, bucket, defaultBucket string) (*AnalyticsQuery, error) {
+	now := time.Now()
+	query := &AnalyticsQuery{
+		From:   now.AddDate(0, 0, -90),
+		To:     now,
+		Bucket: defaultBucket,
+	}
+
+	if fromStr != "" {
+		from, err := time.ParseInLocation("2006-01-02", fromStr, time.Local)
+		if err != nil {
+			return nil, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		query.From = from
+	}
+	if toStr != "" {
+		to, err := time.ParseInLocation("2006-01-02", toStr, time.Local)
+		if err != nil {
+			return nil, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		// Make the end date inclusive
+		query.To = to.AddDate(0, 0, 1)
+	}
+	if bucket != "" {
+		query.Bucket = bucket
+	}
+
+	if !validAnalyticsBuckets[query.Bucket] {
+		return nil, errors.New("invalid bucket, must be one of: day, week, month, year")
+	}
+	if !query.From.Before(query.To) {
+		return nil, errors.New("from date must be before to date")
+	}
+
+	return query, nil
+}
+
+// GetFoodsAdded returns items added per bucket by add method
+func (s *AnalyticsService) GetFoodsAdded(userID uuid.UUID, q *AnalyticsQuery) ([]repository.FoodsAddedBucket, error) {
+	return s.analyticsRepo.FoodsAddedByMethod(userID, q.From, q.To, q.Bucket)
+}
+
+// GetConsumptionTime returns average days from purchase to consumption per category
+func (s *AnalyticsService) GetConsumptionTime(userID uuid.UUID, q *AnalyticsQuery) ([]repository.ConsumptionStat, error) {
+	return s.analyticsRepo.AverageConsumptionDays(userID, q.From, q.To)
+}
+
+// GetExpiryRate returns the share of items that expired before being used, per bucket,
+// and the wasted items by the last state change that set their expiry date
+func (s *AnalyticsService) GetExpiryRate(userID uuid.UUID, q *AnalyticsQuery) (map[string]interface{}, error) {
+	buckets, err := s.analyticsRepo.ExpiryRate(userID, q.From, q.To, q.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	byLastEvent, err := s.analyticsRepo.WastedByLastEvent(userID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var total, expired int64
+	for _, b := range buckets {
+		total += b.TotalItems
+		expired += b.Expired
+	}
+
+	overall := 0.0
+	if total > 0 {
+		overall = float64(expired) / float64(total)
+	}
+
+	return map[string]interface{}{
+		"buckets":              buckets,
+		"total_items":          total,
+		"expired":              expired,
+		"expiry_rate":          overall,
+		"wasted_by_last_event": byLastEvent,
+	}, nil
+}
+
+// GetSpending returns money spent per bucket from supermarket transactions and orders
+func (s *AnalyticsService) GetSpending(userID uuid.UUID, q *AnalyticsQuery) (map[string]interface{}, error) {
+	buckets, err := s.analyticsRepo.SpendingBySource(userID, q.From, q.To, q.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	for _, b := range buckets {
+		total += b.Amount
+	}
+
+	return map[string]interface{}{
+		"buckets":     buckets,
+		"total_spent": total,
+	}, nil
+}
+
+// GetPointsBySource returns points earned per bucket by source
+func (s *AnalyticsService) GetPointsBySource(userID uuid.UUID, q *AnalyticsQuery) (map[string]interface{}, error) {
+	buckets, err := s.analyticsRepo.PointsBySource(userID, q.From, q.To, q.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	bySource := make(map[string]int64)
+	var total int64
+	for _, b := range buckets {
+		bySource[b.Source] += b.Points
+		total += b.Points
+	}
+
+	return map[string]interface{}{
+		"buckets":      buckets,
+		"by_source":    bySource,
+		"total_points": total,
+	}, nil
+}
@@ -2,11 +2,15 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/config"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/prompts"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
 	"github.com/varel183/MakanSikScan/backend/internal/utils"
 )
@@ -24,34 +28,55 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int64        `json:"expires_in"` // access token lifetime in seconds
+	User         UserResponse `json:"user"`
 }
 
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	Phone     *string   `json:"phone"`
-	Avatar    *string   `json:"avatar"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  uuid.UUID  `json:"id"`
+	Email               string     `json:"email"`
+	Name                string     `json:"name"`
+	Phone               *string    `json:"phone"`
+	Avatar              *string    `json:"avatar"`
+	Role                string     `json:"role"`
+	Locale              string     `json:"locale"`
+	EmailVerified       bool       `json:"email_verified"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type AuthService struct {
-	userRepo *repository.UserRepository
-	config   *config.Config
+	userRepo      *repository.UserRepository
+	sessionRepo   *repository.SessionRepository
+	authTokenRepo *repository.AuthTokenRepository
+	mailer        Mailer
+	uploadService *UploadService
+	config        *config.Config
 }
 
-func NewAuthService(userRepo *repository.UserRepository, config *config.Config) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	authTokenRepo *repository.AuthTokenRepository,
+	mailer Mailer,
+	uploadService *UploadService,
+	config *config.Config,
+) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		config:   config,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		authTokenRepo: authTokenRepo,
+		mailer:        mailer,
+		uploadService: uploadService,
+		config:        config,
 	}
 }
 
 // Register creates a new user account
-func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+func (s *AuthService) Register(req *RegisterRequest, client ClientInfo) (*AuthResponse, error) {
 	// Check if email already exists
 	exists, err := s.userRepo.ExistsByEmail(req.Email)
 	if err != nil {
@@ -79,41 +104,75 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, s.config.JWT.Secret, s.config.JWT.Expiration)
-	if err != nil {
-		return nil, err
-	}
+	// Registration succeeds even if the email can't be sent; the user can request it again
+	go func() {
+		if err := s.sendVerificationEmail(user); err != nil {
+			log.Printf("❌ Failed to send verification email to %s: %v", user.Email, err)
+		}
+	}()
 
-	return &AuthResponse{
-		Token: token,
-		User:  s.toUserResponse(user),
-	}, nil
+	// Start a session and issue its tokens
+	return s.startSession(user, client)
 }
 
 // Login authenticates user and returns JWT token
-func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+func (s *AuthService) Login(req *LoginRequest, client ClientInfo) (*AuthResponse, error) {
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Refuse locked accounts before checking the password. The error is the same as for
+	// a wrong password, so locking an account doesn't reveal that it exists.
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, errors.New("invalid email or password")
+	}
+
 	// Compare password
 	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
+		s.recordFailedLogin(user)
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, s.config.JWT.Secret, s.config.JWT.Expiration)
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+			log.Printf("❌ Failed to reset login attempts for %s: %v", user.ID, err)
+		}
+	}
+
+	// Start a session and issue its tokens
+	return s.startSession(user, client)
+}
+
+// recordFailedLogin counts a failed attempt and locks the account once the threshold is reached.
+// Each failure past the threshold doubles the lock, up to the configured maximum.
+func (s *AuthService) recordFailedLogin(user *models.User) {
+	attempts, err := s.userRepo.IncrementFailedLogins(user.ID)
 	if err != nil {
-		return nil, err
+		log.Printf("❌ Failed to record login attempt for %s: %v", user.ID, err)
+		return
+	}
+
+	lockout := s.config.Lockout
+	if lockout.Threshold <= 0 || attempts < lockout.Threshold {
+		return
+	}
+
+	duration := lockout.BaseDuration
+	for i := lockout.Threshold; i < attempts && duration < lockout.MaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > lockout.MaxDuration {
+		duration = lockout.MaxDuration
+	}
+
+	if err := s.userRepo.LockUntil(user.ID, time.Now().Add(duration)); err != nil {
+		log.Printf("❌ Failed to lock account %s: %v", user.ID, err)
+		return
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  s.toUserResponse(user),
-	}, nil
+	log.Printf("🔒 Account %s locked for %s after %d failed logins", user.ID, duration, attempts)
 }
 
 // GetProfile retrieves user profile by ID
@@ -127,8 +186,17 @@ func (s *AuthService) GetProfile(userID uuid.UUID) (*UserResponse, error) {
 	return &response, nil
 }
 
+// IsAdmin reports whether the user has the admin role
+func (s *AuthService) IsAdmin(userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.Role == models.RoleAdmin, nil
+}
+
 // UpdateProfile updates user profile
-func (s *AuthService) UpdateProfile(userID uuid.UUID, name, phone, avatar string) (*UserResponse, error) {
+func (s *AuthService) UpdateProfile(userID uuid.UUID, name, phone, avatar, locale string) (*UserResponse, error) {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, err
@@ -141,7 +209,18 @@ func (s *AuthService) UpdateProfile(userID uuid.UUID, name, phone, avatar string
 		user.Phone = phone
 	}
 	if avatar != "" {
-		user.Avatar = avatar
+		avatarRef, err := s.uploadService.NormalizeImageRef(userID, avatar)
+		if err != nil {
+			return nil, err
+		}
+		user.Avatar = avatarRef
+	}
+	if locale != "" {
+		normalized, ok := prompts.NormalizeLocale(locale)
+		if !ok {
+			return nil, fmt.Errorf("unsupported locale, use one of: %s", strings.Join(prompts.Locales, ", "))
+		}
+		user.Locale = normalized
 	}
 
 	if err := s.userRepo.Update(user); err != nil {
@@ -159,16 +238,21 @@ func (s *AuthService) toUserResponse(user *models.User) UserResponse {
 		phone = &user.Phone
 	}
 	if user.Avatar != "" {
-		avatar = &user.Avatar
+		url := s.uploadService.ResolveURL(user.Avatar)
+		avatar = &url
 	}
 
 	return UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		Phone:     phone,
-		Avatar:    avatar,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:                  user.ID,
+		Email:               user.Email,
+		Name:                user.Name,
+		Phone:               phone,
+		Avatar:              avatar,
+		Role:                user.Role,
+		Locale:              user.Locale,
+		EmailVerified:       user.EmailVerified,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}
 }
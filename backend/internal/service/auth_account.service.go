@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// ResendVerificationEmail sends a new verification link to the user
+func (s *AuthService) ResendVerificationEmail(userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.New("email already verified")
+	}
+	return s.sendVerificationEmail(user)
+}
+
+// VerifyEmail marks the user's email as verified using a token from the verification email
+func (s *AuthService) VerifyEmail(token string) error {
+	authToken, err := s.consumeToken(models.AuthTokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(authToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+// ForgotPassword emails a password reset link.
+// Unknown emails are ignored so the endpoint doesn't reveal which accounts exist.
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.createToken(user.ID, models.AuthTokenPasswordReset, s.config.Mail.PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(EmailMessage{
+		To:      user.Email,
+		Subject: "Reset your MakanSikScan password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. It expires in %s.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.",
+			user.Name, s.config.Mail.PasswordResetTokenTTL, s.emailLink("reset-password", token),
+		),
+	})
+}
+
+// ResetPassword sets a new password using a token from the reset email and signs out every device
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	authToken, err := s.consumeToken(models.AuthTokenPasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(authToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	// Receiving the email proves ownership of the address
+	if !user.EmailVerified {
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+	}
+
+	// A successful reset also lifts any login lockout
+	if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+		return err
+	}
+
+	_, err = s.sessionRepo.RevokeAllExcept(user.ID, uuid.Nil)
+	return err
+}
+
+// ChangePassword replaces the password after checking the old one and signs out other devices
+func (s *AuthService) ChangePassword(userID, currentSessionID uuid.UUID, req *ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.ComparePassword(user.Password, req.OldPassword); err != nil {
+		return errors.New("old password is incorrect")
+	}
+	if req.OldPassword == req.NewPassword {
+		return errors.New("new password must be different from the old password")
+	}
+
+	if err := s.setPassword(user, req.NewPassword); err != nil {
+		return err
+	}
+
+	_, err = s.sessionRepo.RevokeAllExcept(user.ID, currentSessionID)
+	return err
+}
+
+func (s *AuthService) setPassword(user *models.User, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return s.userRepo.Update(user)
+}
+
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	token, err := s.createToken(user.ID, models.AuthTokenEmailVerification, s.config.Mail.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(EmailMessage{
+		To:      user.Email,
+		Subject: "Verify your MakanSikScan email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address with the link below. It expires in %s.\n\n%s",
+			user.Name, s.config.Mail.VerificationTokenTTL, s.emailLink("verify-email", token),
+		),
+	})
+}
+
+// createToken stores the hash of a new single-use token and returns the raw token
+func (s *AuthService) createToken(userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	token, hash, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.authTokenRepo.Create(&models.AuthToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeToken validates a token and marks it used so it can't be replayed
+func (s *AuthService) consumeToken(purpose, token string) (*models.AuthToken, error) {
+	authToken, err := s.authTokenRepo.FindByHash(purpose, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.authTokenRepo.MarkUsed(authToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("⚠️  Token %s was used concurrently", authToken.ID)
+		return nil, errors.New("invalid or expired token")
+	}
+
+	return authToken, nil
+}
+
+func (s *AuthService) emailLink(path, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", s.config.Mail.LinkBaseURL, path, url.QueryEscape(token))
+}
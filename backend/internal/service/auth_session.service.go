@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+// ClientInfo describes the device a session was started from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Current    bool      `json:"current"`
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+// Each refresh token works once; presenting a used one revokes the whole session.
+func (s *AuthService) Refresh(refreshToken string, client ClientInfo) (*AuthResponse, error) {
+	stored, err := s.sessionRepo.FindRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.FindByID(stored.SessionID)
+	if err != nil || !session.IsActive() {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	if stored.UsedAt != nil {
+		s.revokeReusedSession(session)
+		return nil, errors.New("refresh token reuse detected")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New("refresh token expired")
+	}
+
+	// Guard against two requests racing with the same token
+	ok, err := s.sessionRepo.MarkRefreshTokenUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.revokeReusedSession(session)
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	expiresAt := time.Now().Add(s.config.JWT.RefreshExpiration)
+	if err := s.sessionRepo.Touch(session.ID, client.IPAddress, expiresAt); err != nil {
+		return nil, err
+	}
+	session.ExpiresAt = expiresAt
+
+	return s.issueTokens(user, session)
+}
+
+// Logout revokes the session the request was made with
+func (s *AuthService) Logout(userID, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		// Tokens issued before sessions existed have nothing to revoke
+		return nil
+	}
+	return s.sessionRepo.Revoke(userID, sessionID)
+}
+
+// GetSessions lists the user's active sessions
+func (s *AuthService) GetSessions(userID, currentSessionID uuid.UUID) ([]SessionResponse, error) {
+	sessions, err := s.sessionRepo.FindActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			CreatedAt:  session.CreatedAt,
+			Current:    session.ID == currentSessionID,
+		}
+	}
+
+	return responses, nil
+}
+
+// RevokeSession signs out one of the user's devices
+func (s *AuthService) RevokeSession(userID, sessionID uuid.UUID) error {
+	return s.sessionRepo.Revoke(userID, sessionID)
+}
+
+// RevokeOtherSessions signs out every device except the current one
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID uuid.UUID) (int64, error) {
+	return s.sessionRepo.RevokeAllExcept(userID, currentSessionID)
+}
+
+// CheckSession rejects access tokens whose session was revoked or expired
+func (s *AuthService) CheckSession(sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return errors.New("session not found")
+	}
+	if !session.IsActive() {
+		return errors.New("session revoked")
+	}
+	return nil
+}
+
+// startSession creates a session for a freshly authenticated user and issues its tokens
+func (s *AuthService) startSession(user *models.User, client ClientInfo) (*AuthResponse, error) {
+	session := &models.Session{
+		UserID:     user.ID,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IPAddress:  client.IPAddress,
+		LastUsedAt: time.Now(),
+		ExpiresAt:  time.Now().Add(s.config.JWT.RefreshExpiration),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// issueTokens creates an access token and a new refresh token for the session
+func (s *AuthService) issueTokens(user *models.User, session *models.Session) (*AuthResponse, error) {
+	token, err := utils.GenerateJWT(user.ID, session.ID, s.config.JWT.Secret, s.config.JWT.Expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.CreateRefreshToken(&models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hash,
+		ExpiresAt: session.ExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.config.JWT.Expiration.Seconds()),
+		User:         s.toUserResponse(user),
+	}, nil
+}
+
+// revokeReusedSession ends a session whose refresh token was presented twice,
+// since either the client or an attacker holds a stolen copy
+func (s *AuthService) revokeReusedSession(session *models.Session) {
+	log.Printf("⚠️  Refresh token reuse detected for session %s, revoking", session.ID)
+	if err := s.sessionRepo.Revoke(session.UserID, session.ID); err != nil {
+		log.Printf("❌ Failed to revoke session %s: %v", session.ID, err)
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+)
+
+// ErrBlobNotFound is returned for keys that don't exist
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores files by key, e.g. "uploads/<user>/<id>.jpg"
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a download URL that stays valid until expiresAt
+	SignedURL(key string, expiresAt time.Time) (string, error)
+	// KeyFromURL returns the key of a URL made by SignedURL
+	KeyFromURL(rawURL string) (string, bool)
+}
+
+// NewBlobStore returns the blob store selected by config
+func NewBlobStore(cfg *config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "s3":
+		return NewS3BlobStore(cfg)
+	case "local", "":
+		return NewLocalBlobStore(cfg.LocalDir, cfg.PublicBaseURL, cfg.SigningSecret)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// LocalFilesPath is where the API serves the files of the local blob store
+const LocalFilesPath = "/api/v1/uploads/files/"
+
+// LocalBlobStore keeps files on the local filesystem, served by the API with HMAC-signed URLs.
+// Meant for development and single-instance deployments.
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalBlobStore(dir, baseURL, secret string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalBlobStore{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+	}, nil
+}
+
+// path maps a key to a file, rejecting keys that would escape the storage directory
+func (s *LocalBlobStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write then rename so readers never see a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Open opens a stored file for serving
+func (s *LocalBlobStore) Open(key string) (io.ReadSeekCloser, os.FileInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrBlobNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+func (s *LocalBlobStore) SignedURL(key string, expiresAt time.Time) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.sign(key, expires)},
+	}
+	return s.baseURL + LocalFilesPath + key + "?" + query.Encode(), nil
+}
+
+func (s *LocalBlobStore) KeyFromURL(rawURL string) (string, bool) {
+	prefix := s.baseURL + LocalFilesPath
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(rawURL, prefix)
+	if i := strings.Index(key, "?"); i >= 0 {
+		key = key[:i]
+	}
+	return key, key != ""
+}
+
+// Verify checks the expiry and signature of a signed URL
+func (s *LocalBlobStore) Verify(key, expires, signature string, now time.Time) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
+
+func (s *LocalBlobStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
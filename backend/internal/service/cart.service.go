@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
+	"github.com/varel183/MakanSikScan/backend/internal/units"
 )
 
 type CreateCartRequest struct {
@@ -45,21 +46,48 @@ type CartResponse struct {
 
 type CartService struct {
 	cartRepo *repository.CartRepository
+	eventHub *EventHub
 }
 
-func NewCartService(cartRepo *repository.CartRepository) *CartService {
+func NewCartService(cartRepo *repository.CartRepository, eventHub *EventHub) *CartService {
 	return &CartService{
 		cartRepo: cartRepo,
+		eventHub: eventHub,
 	}
 }
 
-// CreateCartItem creates a new cart item
+// CreateCartItem creates a new cart item, or adds the quantity to a pending item
+// with the same name when the units convert, e.g. 500 g onto 1.5 kg
 func (s *CartService) CreateCartItem(userID uuid.UUID, req *CreateCartRequest) (*CartResponse, error) {
+	unit, err := units.Normalize(req.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.cartRepo.FindPendingByName(userID, req.ItemName)
+	if err != nil {
+		return nil, err
+	}
+	for _, cart := range pending {
+		quantity, err := units.Convert(req.Quantity, unit, cart.Unit, cart.ItemName)
+		if err != nil {
+			continue
+		}
+		cart.Quantity += quantity
+		if err := s.cartRepo.Update(&cart); err != nil {
+			return nil, err
+		}
+
+		response := s.toCartResponse(&cart)
+		s.publishCartUpdated(userID, "updated", response)
+		return response, nil
+	}
+
 	cart := &models.Cart{
 		UserID:      userID,
 		ItemName:    req.ItemName,
 		Quantity:    req.Quantity,
-		Unit:        req.Unit,
+		Unit:        unit,
 		Category:    req.Category,
 		IsPurchased: false,
 	}
@@ -78,7 +106,10 @@ func (s *CartService) CreateCartItem(userID uuid.UUID, req *CreateCartRequest) (
 		return nil, err
 	}
 
-	return s.toCartResponse(cart), nil
+	response := s.toCartResponse(cart)
+	s.publishCartUpdated(userID, "created", response)
+
+	return response, nil
 }
 
 // GetCartItem retrieves a cart item by ID
@@ -162,12 +193,20 @@ func (s *CartService) UpdateCartItem(id uuid.UUID, req *UpdateCartRequest) (*Car
 	if req.ItemName != nil {
 		cart.ItemName = *req.ItemName
 	}
+	if req.Unit != nil {
+		// A new unit alone converts the quantity, e.g. 1.5 kg becomes 1500 g
+		unit, err := units.Normalize(*req.Unit)
+		if err != nil {
+			return nil, err
+		}
+		if quantity, err := units.Convert(cart.Quantity, cart.Unit, unit, cart.ItemName); err == nil {
+			cart.Quantity = quantity
+		}
+		cart.Unit = unit
+	}
 	if req.Quantity != nil {
 		cart.Quantity = *req.Quantity
 	}
-	if req.Unit != nil {
-		cart.Unit = *req.Unit
-	}
 	if req.Category != nil {
 		cart.Category = *req.Category
 	}
@@ -188,27 +227,69 @@ func (s *CartService) UpdateCartItem(id uuid.UUID, req *UpdateCartRequest) (*Car
 		return nil, err
 	}
 
-	return s.toCartResponse(cart), nil
+	response := s.toCartResponse(cart)
+	s.publishCartUpdated(cart.UserID, "updated", response)
+
+	return response, nil
 }
 
 // MarkAsPurchased marks a cart item as purchased
 func (s *CartService) MarkAsPurchased(id uuid.UUID) error {
-	return s.cartRepo.MarkAsPurchased(id)
+	cart, err := s.cartRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cartRepo.MarkAsPurchased(id); err != nil {
+		return err
+	}
+
+	cart.IsPurchased = true
+	s.publishCartUpdated(cart.UserID, "purchased", s.toCartResponse(cart))
+	return nil
 }
 
 // MarkAllAsPurchased marks all pending items as purchased
 func (s *CartService) MarkAllAsPurchased(userID uuid.UUID) error {
-	return s.cartRepo.MarkAllAsPurchased(userID)
+	if err := s.cartRepo.MarkAllAsPurchased(userID); err != nil {
+		return err
+	}
+
+	s.publishCartUpdated(userID, "all_purchased", nil)
+	return nil
 }
 
 // DeleteCartItem deletes a cart item
 func (s *CartService) DeleteCartItem(id uuid.UUID) error {
-	return s.cartRepo.Delete(id)
+	cart, err := s.cartRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cartRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.publishCartUpdated(cart.UserID, "deleted", map[string]interface{}{"id": id})
+	return nil
 }
 
 // ClearPurchased deletes all purchased items
 func (s *CartService) ClearPurchased(userID uuid.UUID) error {
-	return s.cartRepo.DeleteAllPurchased(userID)
+	if err := s.cartRepo.DeleteAllPurchased(userID); err != nil {
+		return err
+	}
+
+	s.publishCartUpdated(userID, "cleared_purchased", nil)
+	return nil
+}
+
+// publishCartUpdated notifies the user's open streams that the cart changed
+func (s *CartService) publishCartUpdated(userID uuid.UUID, action string, item interface{}) {
+	s.eventHub.Publish(userID, EventCartUpdated, map[string]interface{}{
+		"action": action,
+		"item":   item,
+	})
 }
 
 // toCartResponse converts Cart model to CartResponse DTO
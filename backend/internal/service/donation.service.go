@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
@@ -9,23 +11,26 @@ import (
 )
 
 type DonationService struct {
-	donationRepo *repository.DonationRepository
-	foodRepo     *repository.FoodRepository
-	userRepo     *repository.UserRepository
-	rewardRepo   *repository.RewardRepository
+	donationRepo        *repository.DonationRepository
+	foodRepo            *repository.FoodRepository
+	userRepo            *repository.UserRepository
+	rewardService       *RewardService
+	gamificationService *GamificationService
 }
 
 func NewDonationService(
 	donationRepo *repository.DonationRepository,
 	foodRepo *repository.FoodRepository,
 	userRepo *repository.UserRepository,
-	rewardRepo *repository.RewardRepository,
+	rewardService *RewardService,
+	gamificationService *GamificationService,
 ) *DonationService {
 	return &DonationService{
-		donationRepo: donationRepo,
-		foodRepo:     foodRepo,
-		userRepo:     userRepo,
-		rewardRepo:   rewardRepo,
+		donationRepo:        donationRepo,
+		foodRepo:            foodRepo,
+		userRepo:            userRepo,
+		rewardService:       rewardService,
+		gamificationService: gamificationService,
 	}
 }
 
@@ -71,10 +76,6 @@ func (s *DonationService) CreateDonationWithUUID(userID uuid.UUID, foodID uuid.U
 		return nil, errors.New("you don't own this food item")
 	}
 
-	if int(food.Quantity) < quantity {
-		return nil, errors.New("insufficient food quantity")
-	}
-
 	// Validate market exists
 	market, err := s.donationRepo.GetMarketByID(marketID)
 	if err != nil {
@@ -85,55 +86,42 @@ func (s *DonationService) CreateDonationWithUUID(userID uuid.UUID, foodID uuid.U
 		return nil, errors.New("market is not active")
 	}
 
-	// Calculate points earned (10 points per item donated)
-	pointsEarned := quantity * 10
+	// Calculate points earned from the donation rules
+	award, err := s.rewardService.CalculatePoints(userID, ActionDonation, PointsContext{
+		Category:   food.Category,
+		Quantity:   quantity,
+		ExpiryDate: food.ExpiryDate,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Create donation
+	// Create donation, checked against and taken from the locked stock, the lots expiring first
 	donation := &models.Donation{
 		UserID:       userID,
 		FoodID:       foodID,
 		MarketID:     marketID,
 		Quantity:     quantity,
-		PointsEarned: pointsEarned,
+		PointsEarned: award.Points,
 		Status:       "confirmed",
 		Notes:        notes,
 	}
 
-	if err := s.donationRepo.CreateDonation(donation); err != nil {
+	if _, err := s.foodRepo.DonateStock(donation); err != nil {
 		return nil, err
 	}
 
-	// Update food quantity
-	food.Quantity -= float64(quantity)
-	if err := s.foodRepo.Update(food); err != nil {
+	// Award points
+	if err := s.rewardService.EarnAward(userID, award, fmt.Sprintf("Donated %d %s", quantity, food.Name), nil, ""); err != nil {
 		return nil, err
 	}
-
-	// Get or create user points
-	userPoints, err := s.rewardRepo.GetOrCreateUserPoints(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create point transaction
-	transaction := &models.PointTransaction{
-		ID:           uuid.New(),
-		UserPointsID: userPoints.ID,
-		Type:         "earn",
-		Amount:       pointsEarned,
-		Source:       "donation",
-		Description:  "Donation reward",
-	}
-	if err := s.rewardRepo.CreateTransaction(transaction); err != nil {
-		return nil, err
-	}
-
-	// Update user points
-	newAvailablePoints := userPoints.AvailablePoints + pointsEarned
-	newTotalPoints := userPoints.TotalPoints + pointsEarned
-	if err := s.rewardRepo.UpdatePoints(userPoints.ID, newAvailablePoints, newTotalPoints, userPoints.UsedPoints); err != nil {
-		return nil, err
+	if award.Points != donation.PointsEarned {
+		// A concurrent award used up more of the daily cap than estimated
+		if err := s.donationRepo.UpdateDonationPoints(donation.ID, award.Points); err != nil {
+			log.Printf("⚠️  Failed to update points of donation %d: %v", donation.ID, err)
+		}
 	}
+	s.gamificationService.RecordActivity(userID, models.ActivityDonation, &foodID) // donations have numeric IDs, reference the donated food
 
 	// Load relations
 	donation, _ = s.donationRepo.GetDonationByID(donation.ID)
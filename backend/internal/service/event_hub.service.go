@@ -0,0 +1,192 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventFoodCreated         EventType = "food.created"
+	EventFoodUpdated         EventType = "food.updated"
+	EventFoodDeleted         EventType = "food.deleted"
+	EventCartUpdated         EventType = "cart.updated"
+	EventOrderStatusChanged  EventType = "order.status_changed"
+	EventNotificationNew     EventType = "notification.new"
+	EventAchievementUnlocked EventType = "achievement.unlocked"
+)
+
+const (
+	// Events kept per user so reconnecting clients can catch up
+	eventHistorySize = 100
+	// How long events are kept for a reconnect, older ones and idle users are dropped
+	eventHistoryRetention = 5 * time.Minute
+	// Events buffered per subscriber before it is considered too slow
+	subscriberBufferSize = 32
+)
+
+// Event is a typed message delivered to a user's open streams
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      EventType   `json:"type"`
+	UserID    uuid.UUID   `json:"-"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Subscription receives the events of one user until it is closed
+type Subscription struct {
+	Events <-chan Event
+	events chan Event
+	userID uuid.UUID
+}
+
+// EventHub is an in-process pub/sub hub that fans events out to each user's streams
+type EventHub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	subscribers map[uuid.UUID]map[*Subscription]struct{}
+	history     map[uuid.UUID][]Event
+	lastPrune   time.Time
+	closed      bool
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{
+		// Start from the clock so IDs keep increasing across restarts
+		// and stale Last-Event-ID values never hide new events
+		lastID:      uint64(time.Now().UnixMilli()),
+		subscribers: make(map[uuid.UUID]map[*Subscription]struct{}),
+		history:     make(map[uuid.UUID][]Event),
+	}
+}
+
+// Publish sends an event to every open stream of the user
+func (h *EventHub) Publish(userID uuid.UUID, eventType EventType, data interface{}) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	event := Event{
+		ID:        h.lastID,
+		Type:      eventType,
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	history := append(h.history[userID], event)
+	if len(history) > eventHistorySize {
+		history = history[len(history)-eventHistorySize:]
+	}
+	h.history[userID] = history
+	h.pruneLocked(event.CreatedAt)
+
+	for sub := range h.subscribers[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			// Drop slow subscribers; they reconnect with Last-Event-ID and replay
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// Subscribe opens a stream for the user and returns the events published after lastEventID
+func (h *EventHub) Subscribe(userID uuid.UUID, lastEventID uint64) (*Subscription, []Event) {
+	events := make(chan Event, subscriberBufferSize)
+	sub := &Subscription{Events: events, events: events, userID: userID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(events)
+		return sub, nil
+	}
+
+	var missed []Event
+	if lastEventID > 0 {
+		for _, event := range h.history[userID] {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+
+	return sub, missed
+}
+
+// Unsubscribe closes a stream, it is safe to call more than once
+func (h *EventHub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+// Close ends every open stream so the HTTP server can shut down
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// pruneLocked drops events older than the retention, and the history of users without
+// recent events with it. It runs at most once per retention period.
+func (h *EventHub) pruneLocked(now time.Time) {
+	if now.Sub(h.lastPrune) < eventHistoryRetention {
+		return
+	}
+	h.lastPrune = now
+
+	cutoff := now.Add(-eventHistoryRetention)
+	for userID, events := range h.history {
+		// Events are in publish order
+		i := sort.Search(len(events), func(i int) bool { return events[i].CreatedAt.After(cutoff) })
+		switch {
+		case i == len(events):
+			delete(h.history, userID)
+		case i > 0:
+			h.history[userID] = append([]Event(nil), events[i:]...)
+		}
+	}
+}
+
+func (h *EventHub) removeLocked(sub *Subscription) {
+	subs, ok := h.subscribers[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.events)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.userID)
+	}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+// Storage zones, free-text food locations are mapped onto one of them
+const (
+	StorageZonePantry  = "pantry"
+	StorageZoneFridge  = "fridge"
+	StorageZoneFreezer = "freezer"
+)
+
+// StorageZones lists the storage zones, coldest last
+var StorageZones = []string{StorageZonePantry, StorageZoneFridge, StorageZoneFreezer}
+
+// Thawed food keeps this many days when no ExpiryConfig is given
+const defaultThawedDays = 2
+
+// shelfLife holds how many days food keeps in each storage zone
+type shelfLife struct {
+	Pantry  int `json:"pantry"`
+	Fridge  int `json:"fridge"`
+	Freezer int `json:"freezer"`
+}
+
+func (l shelfLife) days(zone string) int {
+	switch zone {
+	case StorageZoneFridge:
+		return l.Fridge
+	case StorageZoneFreezer:
+		return l.Freezer
+	default:
+		return l.Pantry
+	}
+}
+
+// categoryShelfLife is the shelf life of a category, sealed and once opened
+type categoryShelfLife struct {
+	Typical string    `json:"typical"` // zone the category is usually kept in
+	Sealed  shelfLife `json:"sealed"`
+	Opened  shelfLife `json:"opened"`
+}
+
+// shelfLifeTable holds conservative shelf lives in days per category. 0 means the
+// food should be eaten the same day, e.g. raw meat left out of the fridge.
+// Entries can be replaced per category with ExpiryConfig.RulesFile.
+var shelfLifeTable = map[string]categoryShelfLife{
+	models.CategoryVegetable: {StorageZoneFridge, shelfLife{5, 7, 240}, shelfLife{1, 4, 240}},
+	models.CategoryFruit:     {StorageZoneFridge, shelfLife{5, 10, 240}, shelfLife{1, 3, 240}},
+	models.CategoryMeat:      {StorageZoneFridge, shelfLife{0, 3, 180}, shelfLife{0, 2, 180}},
+	models.CategoryFish:      {StorageZoneFridge, shelfLife{0, 2, 120}, shelfLife{0, 1, 120}},
+	models.CategoryDairy:     {StorageZoneFridge, shelfLife{1, 7, 90}, shelfLife{0, 5, 90}},
+	models.CategoryGrain:     {StorageZonePantry, shelfLife{180, 240, 365}, shelfLife{90, 120, 365}},
+	models.CategoryFrozen:    {StorageZoneFreezer, shelfLife{0, 2, 180}, shelfLife{0, 1, 90}},
+	models.CategoryCanned:    {StorageZonePantry, shelfLife{730, 730, 730}, shelfLife{0, 4, 60}},
+	models.CategoryBeverage:  {StorageZonePantry, shelfLife{180, 270, 365}, shelfLife{2, 7, 90}},
+	models.CategorySnack:     {StorageZonePantry, shelfLife{90, 120, 180}, shelfLife{14, 30, 90}},
+	models.CategoryOther:     {StorageZonePantry, shelfLife{7, 14, 180}, shelfLife{3, 7, 90}},
+}
+
+// Words in a location that name its zone, checked coldest first
+// so "Kitchen Fridge" is a fridge and "Freezer door" a freezer
+var storageZoneKeywords = []struct {
+	zone     string
+	keywords []string
+}{
+	{StorageZoneFreezer, []string{"freezer", "frozen", "beku"}},
+	{StorageZoneFridge, []string{"fridge", "refrigerator", "kulkas", "lemari es", "chiller"}},
+	{StorageZonePantry, []string{"pantry", "cabinet", "cupboard", "counter", "kitchen", "basket", "shelf", "lemari", "dapur", "rak", "meja"}},
+}
+
+// Fridge positions, which only count when the location names no zone,
+// so "Door" is in the fridge but "Kitchen cabinet door" is not
+var fridgePositionKeywords = []string{"drawer", "door", "upper", "middle", "lower"}
+
+// ExpiryInput describes a food whose expiry date is predicted
+type ExpiryInput struct {
+	Category string
+	Location string     // free text, mapped with StorageZone
+	From     time.Time  // purchase date, when the sealed shelf life starts
+	OpenedAt *time.Time // nil while sealed
+	HintDays int        // item-specific estimate for its usual storage, e.g. from a scan or product, 0 if unknown
+}
+
+// ExpiryChange is how a state transition changes the expiry date of a food
+type ExpiryChange struct {
+	Type     string // models.FoodEvent*
+	FromZone string
+	ToZone   string
+	Expiry   time.Time
+	Reason   string
+}
+
+// ExpiryService predicts expiry dates from a shelf-life table per category and storage zone
+// and adjusts them when food is opened, frozen or thawed. A nil service uses the built-in rules.
+type ExpiryService struct {
+	table      map[string]categoryShelfLife
+	thawedDays int
+}
+
+// NewExpiryService loads the expiry rules, the built-in table with the categories
+// of the rules file replaced
+func NewExpiryService(cfg *config.ExpiryConfig) (*ExpiryService, error) {
+	if cfg.ThawedDays < 0 {
+		return nil, fmt.Errorf("thawed days must not be negative, got %d", cfg.ThawedDays)
+	}
+	s := &ExpiryService{table: shelfLifeTable, thawedDays: cfg.ThawedDays}
+	if cfg.RulesFile == "" {
+		return s, nil
+	}
+
+	overrides, err := loadShelfLifeRules(cfg.RulesFile)
+	if err != nil {
+		return nil, err
+	}
+	s.table = make(map[string]categoryShelfLife, len(shelfLifeTable))
+	for category, life := range shelfLifeTable {
+		s.table[category] = life
+	}
+	for category, life := range overrides {
+		s.table[category] = life
+	}
+	return s, nil
+}
+
+// loadShelfLifeRules reads a JSON object of category to shelf life, e.g.
+// {"Meat": {"typical": "fridge", "sealed": {"pantry": 0, "fridge": 3, "freezer": 180}, "opened": {...}}}
+func loadShelfLifeRules(path string) (map[string]categoryShelfLife, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expiry rules: %w", err)
+	}
+	var raw map[string]categoryShelfLife
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid expiry rules: %w", err)
+	}
+
+	rules := make(map[string]categoryShelfLife, len(raw))
+	for name, life := range raw {
+		category, ok := models.NormalizeFoodCategory(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid expiry rules: unknown category %q", name)
+		}
+		if life.Typical != StorageZonePantry && life.Typical != StorageZoneFridge && life.Typical != StorageZoneFreezer {
+			return nil, fmt.Errorf("invalid expiry rules: %s has unknown typical zone %q", category, life.Typical)
+		}
+		for _, days := range []int{life.Sealed.Pantry, life.Sealed.Fridge, life.Sealed.Freezer, life.Opened.Pantry, life.Opened.Fridge, life.Opened.Freezer} {
+			if days < 0 {
+				return nil, fmt.Errorf("invalid expiry rules: %s has negative shelf life", category)
+			}
+		}
+		rules[category] = life
+	}
+	return rules, nil
+}
+
+// rules returns the shelf life of a category
+func (s *ExpiryService) rules(category string) categoryShelfLife {
+	category, _ = models.NormalizeFoodCategory(category)
+	if s != nil {
+		if life, ok := s.table[category]; ok {
+			return life
+		}
+	}
+	return shelfLifeTable[category]
+}
+
+func (s *ExpiryService) thawDays() int {
+	if s == nil {
+		return defaultThawedDays
+	}
+	return s.thawedDays
+}
+
+// Predict returns the expiry date of a food. An item-specific hint is scaled by how much
+// longer or shorter the category keeps in the actual zone than in its usual one. Opened
+// food expires after the opened shelf life unless the sealed date comes first.
+func (s *ExpiryService) Predict(in ExpiryInput) time.Time {
+	life := s.rules(in.Category)
+	zone := s.StorageZone(in.Location, in.Category)
+
+	sealedDays := life.Sealed.days(zone)
+	if typical := life.Sealed.days(life.Typical); in.HintDays > 0 && typical > 0 {
+		sealedDays = int(math.Round(float64(in.HintDays) * float64(sealedDays) / float64(typical)))
+	}
+	expiry := in.From.AddDate(0, 0, sealedDays)
+
+	if in.OpenedAt != nil {
+		openedDays := life.Opened.days(zone)
+		if openedExpiry := in.OpenedAt.AddDate(0, 0, openedDays); openedExpiry.Before(expiry) {
+			expiry = openedExpiry
+		}
+	}
+	return expiry
+}
+
+// PredictPtr is Predict for optional model fields
+func (s *ExpiryService) PredictPtr(in ExpiryInput) *time.Time {
+	expiry := s.Predict(in)
+	return &expiry
+}
+
+// StorageZone maps a free-text location such as "Refrigerator - Top shelf" to a zone.
+// Locations that name no zone, e.g. a supermarket, get the usual zone of the category.
+func (s *ExpiryService) StorageZone(location, category string) string {
+	for _, candidate := range storageZoneKeywords {
+		if containsWord(location, candidate.keywords) {
+			return candidate.zone
+		}
+	}
+	if containsWord(location, fridgePositionKeywords) {
+		return StorageZoneFridge
+	}
+
+	return s.rules(category).Typical
+}
+
+// containsWord reports whether text contains one of the keywords as whole words,
+// so "door" is not found in "outdoor"
+func containsWord(text string, keywords []string) bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	normalized := " " + strings.Join(words, " ") + " "
+	for _, keyword := range keywords {
+		if strings.Contains(normalized, " "+keyword+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Open returns how opening a food at the given time changes its expiry date.
+// The opened shelf life starts counting, unless the current date comes first.
+func (s *ExpiryService) Open(food *models.Food, openedAt time.Time) ExpiryChange {
+	zone := s.StorageZone(food.Location, food.Category)
+	days := s.rules(food.Category).Opened.days(zone)
+
+	change := ExpiryChange{
+		Type:     models.FoodEventOpened,
+		FromZone: zone,
+		ToZone:   zone,
+		Expiry:   openedAt.AddDate(0, 0, days),
+		Reason:   fmt.Sprintf("Opened on %s, keeps %s in the %s once opened", openedAt.Format("02 Jan 2006"), formatDays(days), zone),
+	}
+	if current := s.currentExpiry(food); current.Before(change.Expiry) {
+		change.Expiry = current
+		change.Reason = fmt.Sprintf("Opened on %s, the expiry date of %s still comes first", openedAt.Format("02 Jan 2006"), current.Format("02 Jan 2006"))
+	}
+	return change
+}
+
+// Move returns how moving a food to another location at the given time changes its expiry date.
+// Freezing starts the freezer shelf life, thawed food keeps the configured thawed days at most
+// and moves between pantry and fridge scale the remaining days by the shelf lives of both zones.
+func (s *ExpiryService) Move(food *models.Food, location string, at time.Time) ExpiryChange {
+	life := s.rules(food.Category)
+	state := life.Sealed
+	if food.OpenedAt != nil {
+		state = life.Opened
+	}
+
+	change := ExpiryChange{
+		FromZone: s.StorageZone(food.Location, food.Category),
+		ToZone:   s.StorageZone(location, food.Category),
+	}
+	current := s.currentExpiry(food)
+	date := at.Format("02 Jan 2006")
+
+	switch {
+	case change.FromZone == change.ToZone:
+		change.Type = models.FoodEventMoved
+		change.Expiry = current
+		change.Reason = fmt.Sprintf("Moved within the %s on %s, expiry unchanged", change.ToZone, date)
+
+	case change.ToZone == StorageZoneFreezer:
+		days := state.Freezer
+		change.Type = models.FoodEventFrozen
+		change.Expiry = at.AddDate(0, 0, days)
+		change.Reason = fmt.Sprintf("Frozen on %s, keeps %s in the freezer", date, formatDays(days))
+		if current.After(change.Expiry) {
+			change.Expiry = current
+			change.Reason = fmt.Sprintf("Frozen on %s, the expiry date of %s comes later", date, current.Format("02 Jan 2006"))
+		}
+
+	case change.FromZone == StorageZoneFreezer:
+		days := state.days(change.ToZone)
+		if thawed := s.thawDays(); thawed < days {
+			days = thawed
+		}
+		change.Type = models.FoodEventThawed
+		change.Expiry = at.AddDate(0, 0, days)
+		change.Reason = fmt.Sprintf("Thawed into the %s on %s, eat within %s", change.ToZone, date, formatDays(days))
+
+	default:
+		change.Type = models.FoodEventMoved
+		change.Expiry = current
+		change.Reason = fmt.Sprintf("Moved from the %s to the %s on %s after it had expired", change.FromZone, change.ToZone, date)
+		if remaining := current.Sub(at); remaining > 0 {
+			// Food that keeps N days in one zone and M in the other has M/N of its remaining time left
+			toDays := state.days(change.ToZone)
+			if fromDays := state.days(change.FromZone); fromDays > 0 {
+				change.Expiry = at.Add(time.Duration(float64(remaining) * float64(toDays) / float64(fromDays)))
+			} else {
+				change.Expiry = at.AddDate(0, 0, toDays)
+			}
+			change.Reason = fmt.Sprintf("Moved from the %s to the %s on %s, keeps %s there", change.FromZone, change.ToZone, date, formatDays(int(change.Expiry.Sub(at).Hours()/24)))
+		}
+	}
+	return change
+}
+
+// currentExpiry returns the expiry date of a food, predicted when it has none
+func (s *ExpiryService) currentExpiry(food *models.Food) time.Time {
+	if food.ExpiryDate != nil {
+		return *food.ExpiryDate
+	}
+	from := food.CreatedAt
+	if food.PurchaseDate != nil {
+		from = *food.PurchaseDate
+	}
+	return s.Predict(ExpiryInput{
+		Category: food.Category,
+		Location: food.Location,
+		From:     from,
+		OpenedAt: food.OpenedAt,
+	})
+}
+
+func formatDays(days int) string {
+	switch days {
+	case 0:
+		return "less than a day"
+	case 1:
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+func TestStorageZone(t *testing.T) {
+	tests := []struct {
+		location string
+		category string
+		want     string
+	}{
+		{"Freezer", models.CategoryMeat, StorageZoneFreezer},
+		{"Freezer door", models.CategoryMeat, StorageZoneFreezer},
+		{"Kitchen Fridge", models.CategoryGrain, StorageZoneFridge},
+		{"Refrigerator - Top shelf", models.CategoryGrain, StorageZoneFridge},
+		{"Lemari es", models.CategoryGrain, StorageZoneFridge},
+		{"Lemari dapur", models.CategoryDairy, StorageZonePantry},
+		{"Pantry - lower shelf", models.CategoryDairy, StorageZonePantry},
+		{"Kitchen cabinet door", models.CategoryDairy, StorageZonePantry},
+		{"Dapur drawer", models.CategoryDairy, StorageZonePantry},
+		{"Door", models.CategoryGrain, StorageZoneFridge},
+		{"Middle drawer", models.CategoryGrain, StorageZoneFridge},
+
+		// No zone named, the category's usual zone
+		{"Outdoor", models.CategoryDairy, StorageZoneFridge},
+		{"Outdoor", models.CategoryGrain, StorageZonePantry},
+		{"Supermarket", models.CategoryFrozen, StorageZoneFreezer},
+		{"", models.CategoryMeat, StorageZoneFridge},
+	}
+
+	var s *ExpiryService
+	for _, tt := range tests {
+		if got := s.StorageZone(tt.location, tt.category); got != tt.want {
+			t.Errorf("StorageZone(%q, %q) = %q, want %q", tt.location, tt.category, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
+	"github.com/varel183/MakanSikScan/backend/internal/units"
 )
 
 type CreateFoodRequest struct {
@@ -16,7 +19,8 @@ type CreateFoodRequest struct {
 	Unit         string     `json:"unit" binding:"required"`
 	ImageURL     *string    `json:"image_url"`
 	PurchaseDate *time.Time `json:"purchase_date"`
-	ExpiryDate   *time.Time `json:"expiry_date"`
+	ExpiryDate   *time.Time `json:"expiry_date"` // predicted from category and location when empty
+	OpenedAt     *time.Time `json:"opened_at"`
 	Location     string     `json:"location" binding:"required"`
 	IsHalal      *bool      `json:"is_halal"`
 	Barcode      *string    `json:"barcode"`
@@ -41,6 +45,7 @@ type AddScannedFoodRequest struct {
 	Protein      *float64   `json:"protein"`
 	Carbs        *float64   `json:"carbs"`
 	Fat          *float64   `json:"fat"`
+	ScanID       *uuid.UUID `json:"scan_id"` // from the scan response, records the scan as saved
 }
 
 type UpdateFoodRequest struct {
@@ -54,15 +59,33 @@ type UpdateFoodRequest struct {
 	IsHalal    *bool      `json:"is_halal"`
 }
 
+// AddStockRequest adds a purchase of an existing food as a new lot
+type AddStockRequest struct {
+	Quantity     float64    `json:"quantity" binding:"required,gt=0"`
+	Unit         string     `json:"unit"`          // converted to the food's unit, defaults to it
+	PurchaseDate *time.Time `json:"purchase_date"` // defaults to now
+	ExpiryDate   *time.Time `json:"expiry_date"`   // predicted from category and location when empty
+}
+
+type OpenFoodRequest struct {
+	OpenedAt *time.Time `json:"opened_at"` // defaults to now
+}
+
+type MoveFoodRequest struct {
+	Location string `json:"location" binding:"required"`
+}
+
 type FoodResponse struct {
 	ID           uuid.UUID  `json:"id"`
 	Name         string     `json:"name"`
 	Category     string     `json:"category"`
-	Quantity     float64    `json:"quantity"`
+	Quantity     float64    `json:"quantity"` // total of all lots
 	Unit         string     `json:"unit"`
 	ImageURL     *string    `json:"image_url"`
+	ThumbnailURL *string    `json:"thumbnail_url"`
 	PurchaseDate *time.Time `json:"purchase_date"`
-	ExpiryDate   *time.Time `json:"expiry_date"`
+	ExpiryDate   *time.Time `json:"expiry_date"` // nearest expiry of the lots in stock
+	OpenedAt     *time.Time `json:"opened_at"`
 	Location     string     `json:"location"`
 	IsHalal      *bool      `json:"is_halal"`
 	Barcode      *string    `json:"barcode"`
@@ -75,37 +98,72 @@ type FoodResponse struct {
 	DaysUntilExp *int       `json:"days_until_expiry"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Lots []models.FoodLot `json:"lots,omitempty"` // in stock, expiring first; only on single food responses
 }
 
 type FoodService struct {
-	foodRepo   *repository.FoodRepository
-	rewardRepo *repository.RewardRepository
+	foodRepo            *repository.FoodRepository
+	rewardService       *RewardService
+	gamificationService *GamificationService
+	uploadService       *UploadService
+	expiryService       *ExpiryService
+	eventHub            *EventHub
 }
 
-func NewFoodService(foodRepo *repository.FoodRepository, rewardRepo *repository.RewardRepository) *FoodService {
+func NewFoodService(foodRepo *repository.FoodRepository, rewardService *RewardService, gamificationService *GamificationService, uploadService *UploadService, expiryService *ExpiryService, eventHub *EventHub) *FoodService {
 	return &FoodService{
-		foodRepo:   foodRepo,
-		rewardRepo: rewardRepo,
+		foodRepo:            foodRepo,
+		rewardService:       rewardService,
+		gamificationService: gamificationService,
+		uploadService:       uploadService,
+		expiryService:       expiryService,
+		eventHub:            eventHub,
 	}
 }
 
 // CreateFood creates a new food item
 func (s *FoodService) CreateFood(userID uuid.UUID, req *CreateFoodRequest) (*FoodResponse, error) {
+	unit, err := units.Normalize(req.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	category, _ := models.NormalizeFoodCategory(req.Category)
 	food := &models.Food{
 		UserID:          userID,
 		Name:            req.Name,
-		Category:        req.Category,
+		Category:        category,
 		Quantity:        req.Quantity,
 		InitialQuantity: req.Quantity, // Set initial quantity sama dengan quantity
-		Unit:            req.Unit,
+		Unit:            unit,
 		PurchaseDate:    req.PurchaseDate,
 		ExpiryDate:      req.ExpiryDate,
+		OpenedAt:        req.OpenedAt,
 		Location:        req.Location,
 		AddMethod:       req.AddMethod,
 	}
 
+	// Without a date from the package, predict it from the category and where the food is kept
+	if food.ExpiryDate == nil {
+		from := time.Now()
+		if food.PurchaseDate != nil {
+			from = *food.PurchaseDate
+		}
+		food.ExpiryDate = s.expiryService.PredictPtr(ExpiryInput{
+			Category: food.Category,
+			Location: food.Location,
+			From:     from,
+			OpenedAt: food.OpenedAt,
+		})
+	}
+
 	if req.ImageURL != nil {
-		food.ImageURL = *req.ImageURL
+		imageRef, err := s.uploadService.NormalizeImageRef(userID, *req.ImageURL)
+		if err != nil {
+			return nil, err
+		}
+		food.ImageURL = imageRef
 	}
 	if req.IsHalal != nil {
 		food.IsHalal = *req.IsHalal
@@ -131,45 +189,34 @@ func (s *FoodService) CreateFood(userID uuid.UUID, req *CreateFoodRequest) (*Foo
 	}
 
 	// Award points for saving food
-	s.awardPointsForFoodSave(userID, food.ID)
-
-	return s.toFoodResponse(food), nil
-}
-
-// awardPointsForFoodSave gives points when user saves food (async)
-func (s *FoodService) awardPointsForFoodSave(userID, foodID uuid.UUID) {
-	// Get or create user points
-	points, err := s.rewardRepo.GetOrCreateUserPoints(userID)
-	if err != nil {
-		return
+	s.awardPointsForFoodSave(userID, food)
+	s.gamificationService.RecordActivity(userID, models.ActivityFoodSaved, &food.ID)
+	if food.AddMethod == "scan" {
+		s.gamificationService.RecordActivity(userID, models.ActivityFoodScanned, &food.ID)
 	}
 
-	// Add 10 points for saving food
-	const pointsPerSave = 10
-	points.TotalPoints += pointsPerSave
-	points.AvailablePoints += pointsPerSave
+	response := s.toFoodResponse(food)
+	s.eventHub.Publish(userID, EventFoodCreated, response)
 
-	s.rewardRepo.UpdatePoints(points.ID, points.AvailablePoints, points.TotalPoints, points.UsedPoints)
+	return response, nil
+}
 
-	// Create transaction
-	transaction := &models.PointTransaction{
-		UserPointsID:  points.ID,
-		Type:          "earn",
-		Amount:        pointsPerSave,
-		Source:        "food_save",
-		Description:   fmt.Sprintf("Earned %d points for saving food", pointsPerSave),
-		ReferenceID:   &foodID,
-		ReferenceType: "food",
+// awardPointsForFoodSave gives points when user saves food
+func (s *FoodService) awardPointsForFoodSave(userID uuid.UUID, food *models.Food) {
+	if err := s.rewardService.AddPointsForFoodSave(userID, food); err != nil {
+		log.Printf("⚠️  Failed to award points for food %s: %v", food.ID, err)
 	}
-	s.rewardRepo.CreateTransaction(transaction)
 }
 
-// GetFood retrieves a food item by ID
+// GetFood retrieves a food item by ID with its lots
 func (s *FoodService) GetFood(id uuid.UUID) (*FoodResponse, error) {
 	food, err := s.foodRepo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if food.Lots, err = s.foodRepo.FindLots(id); err != nil {
+		return nil, err
+	}
 
 	return s.toFoodResponse(food), nil
 }
@@ -204,28 +251,50 @@ func (s *FoodService) CheckDuplicateFood(userID uuid.UUID, name string) ([]FoodR
 	return responses, nil
 }
 
-// UpdateFoodStock updates only the quantity (stock) of existing food
-func (s *FoodService) UpdateFoodStock(id uuid.UUID, additionalQuantity float64) (*FoodResponse, error) {
-	food, err := s.foodRepo.FindByID(id)
-	if err != nil {
-		return nil, err
+// UpdateFoodStock adds a purchase of an existing food as a new lot with its own expiry date.
+// Restocking earns no points, otherwise adding stock repeatedly would farm them.
+func (s *FoodService) UpdateFoodStock(id uuid.UUID, req *AddStockRequest) (*FoodResponse, error) {
+	purchaseDate := time.Now()
+	if req.PurchaseDate != nil {
+		purchaseDate = *req.PurchaseDate
 	}
 
-	// Add to existing quantity
-	food.Quantity += additionalQuantity
+	food, err := s.foodRepo.UpdateStock(id, func(food *models.Food) error {
+		quantity, err := toFoodUnit(food, req.Quantity, req.Unit)
+		if err != nil {
+			return err
+		}
 
-	if err := s.foodRepo.Update(food); err != nil {
+		expiryDate := req.ExpiryDate
+		if expiryDate == nil {
+			// A new purchase is sealed, whether or not the food in storage was opened
+			expiryDate = s.expiryService.PredictPtr(ExpiryInput{
+				Category: food.Category,
+				Location: food.Location,
+				From:     purchaseDate,
+			})
+		}
+		food.AddLot(models.FoodLot{
+			Quantity:     quantity,
+			PurchaseDate: &purchaseDate,
+			ExpiryDate:   expiryDate,
+			Source:       "restock",
+		})
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Award points for adding stock
-	s.awardPointsForFoodSave(food.UserID, food.ID)
+	response := s.toFoodResponse(food)
+	s.eventHub.Publish(food.UserID, EventFoodUpdated, response)
 
-	return s.toFoodResponse(food), nil
+	return response, nil
 }
 
 // GetFoodsByCategory retrieves food items by category
 func (s *FoodService) GetFoodsByCategory(userID uuid.UUID, category string, page, limit int) ([]FoodResponse, int64, error) {
+	category, _ = models.NormalizeFoodCategory(category)
 	foods, total, err := s.foodRepo.FindByCategory(userID, category, page, limit)
 	if err != nil {
 		return nil, 0, err
@@ -284,49 +353,235 @@ func (s *FoodService) GetFoodsByLocation(userID uuid.UUID, location string) ([]F
 	return responses, nil
 }
 
-// UpdateFood updates a food item
+// UpdateFood updates a food item. It works on the locked food like the stock updates,
+// so a concurrent donation or consumption isn't overwritten.
 func (s *FoodService) UpdateFood(id uuid.UUID, req *UpdateFoodRequest) (*FoodResponse, error) {
-	food, err := s.foodRepo.FindByID(id)
+	unit := ""
+	if req.Unit != nil {
+		normalized, err := units.Normalize(*req.Unit)
+		if err != nil {
+			return nil, err
+		}
+		unit = normalized
+	}
+
+	food, err := s.foodRepo.UpdateStock(id, func(food *models.Food) error {
+		// Update fields if provided
+		if req.Name != nil {
+			food.Name = *req.Name
+		}
+		if req.Category != nil {
+			food.Category, _ = models.NormalizeFoodCategory(*req.Category)
+		}
+		if req.Unit != nil {
+			// Before the quantity, which is given in the new unit
+			changeUnit(food, unit)
+		}
+		if req.Quantity != nil {
+			// Lowering the quantity uses up the lots expiring first, raising it adds a lot
+			if diff := *req.Quantity - food.Quantity; diff < 0 {
+				food.Consume(-diff)
+			} else if diff > 0 {
+				now := time.Now()
+				food.AddLot(models.FoodLot{
+					Quantity:     diff,
+					PurchaseDate: &now,
+					ExpiryDate:   food.ExpiryDate,
+					Source:       "adjustment",
+				})
+			}
+		}
+		if req.ImageURL != nil {
+			imageRef, err := s.uploadService.NormalizeImageRef(food.UserID, *req.ImageURL)
+			if err != nil {
+				return err
+			}
+			food.ImageURL = imageRef
+		}
+		if req.ExpiryDate != nil {
+			// The shown expiry date is the nearest lot's, so that lot is corrected
+			food.ExpiryDate = req.ExpiryDate
+			if lot := food.NearestLot(); lot != nil {
+				lot.ExpiryDate = req.ExpiryDate
+				food.RefreshExpiry()
+			}
+		}
+		if req.Location != nil {
+			food.Location = *req.Location
+		}
+		if req.IsHalal != nil {
+			food.IsHalal = *req.IsHalal
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Update fields if provided
-	if req.Name != nil {
-		food.Name = *req.Name
-	}
-	if req.Category != nil {
-		food.Category = *req.Category
+	response := s.toFoodResponse(food)
+	s.eventHub.Publish(food.UserID, EventFoodUpdated, response)
+
+	return response, nil
+}
+
+// OpenFood opens the package expiring first and shortens its expiry date to the opened shelf life.
+// The other packages stay sealed and keep their dates.
+func (s *FoodService) OpenFood(userID, id uuid.UUID, req *OpenFoodRequest) (*FoodResponse, error) {
+	if _, err := s.findUserFood(userID, id); err != nil {
+		return nil, err
 	}
-	if req.Quantity != nil {
-		food.Quantity = *req.Quantity
+
+	openedAt := time.Now()
+	if req.OpenedAt != nil {
+		if req.OpenedAt.After(openedAt) {
+			return nil, errors.New("opened_at cannot be in the future")
+		}
+		openedAt = *req.OpenedAt
+	}
+
+	return s.applyTransition(id, func(food *models.Food) (ExpiryChange, error) {
+		lot := food.NearestLot()
+		if lot == nil {
+			// Foods from before lots existed are opened as a whole
+			if food.OpenedAt != nil {
+				return ExpiryChange{}, errors.New("food is already opened")
+			}
+			change := s.expiryService.Open(food, openedAt)
+			food.OpenedAt = &openedAt
+			food.ExpiryDate = &change.Expiry
+			return change, nil
+		}
+
+		if lot.OpenedAt != nil {
+			return ExpiryChange{}, errors.New("food is already opened")
+		}
+		change := s.expiryService.Open(lotFood(food, lot), openedAt)
+		lot.OpenedAt = &openedAt
+		lot.ExpiryDate = &change.Expiry
+		return change, nil
+	})
+}
+
+// MoveFood moves a food to another location. Moving it into or out of the freezer,
+// or between pantry and fridge, recalculates the expiry date, unlike UpdateFood.
+func (s *FoodService) MoveFood(userID, id uuid.UUID, req *MoveFoodRequest) (*FoodResponse, error) {
+	food, err := s.findUserFood(userID, id)
+	if err != nil {
+		return nil, err
 	}
-	if req.Unit != nil {
-		food.Unit = *req.Unit
+	if food.Location == req.Location {
+		return s.toFoodResponse(food), nil
 	}
-	if req.ImageURL != nil {
-		food.ImageURL = *req.ImageURL
+
+	now := time.Now()
+	return s.applyTransition(id, func(food *models.Food) (ExpiryChange, error) {
+		// Every lot keeps its own shelf life, an opened package keeps less than the sealed ones.
+		// The event describes the lot expiring first, whose date the food shows.
+		change := s.expiryService.Move(food, req.Location, now)
+		nearest := food.NearestLot()
+		for i := range food.Lots {
+			lotChange := s.expiryService.Move(lotFood(food, &food.Lots[i]), req.Location, now)
+			food.Lots[i].ExpiryDate = &lotChange.Expiry
+			if &food.Lots[i] == nearest {
+				change = lotChange
+			}
+		}
+		if nearest == nil {
+			food.ExpiryDate = &change.Expiry
+		}
+		food.Location = req.Location
+		return change, nil
+	})
+}
+
+// GetFoodEvents returns the state transitions of a food, oldest first
+func (s *FoodService) GetFoodEvents(userID, id uuid.UUID) ([]models.FoodEvent, error) {
+	if _, err := s.findUserFood(userID, id); err != nil {
+		return nil, err
 	}
-	if req.ExpiryDate != nil {
-		food.ExpiryDate = req.ExpiryDate
+	return s.foodRepo.FindEvents(id)
+}
+
+// applyTransition runs a state transition on the locked food and its lots in stock
+// and records why the food's expiry changed
+func (s *FoodService) applyTransition(id uuid.UUID, transition func(food *models.Food) (ExpiryChange, error)) (*FoodResponse, error) {
+	food, err := s.foodRepo.UpdateStockWithEvent(id, func(food *models.Food) (*models.FoodEvent, error) {
+		event := &models.FoodEvent{
+			FoodID:         food.ID,
+			UserID:         food.UserID,
+			FromLocation:   food.Location,
+			PreviousExpiry: copyTime(food.ExpiryDate),
+		}
+
+		change, err := transition(food)
+		if err != nil {
+			return nil, err
+		}
+		food.RefreshExpiry()
+
+		event.Type = change.Type
+		event.ToLocation = food.Location
+		event.FromZone = change.FromZone
+		event.ToZone = change.ToZone
+		event.Reason = change.Reason
+		event.NewExpiry = copyTime(food.ExpiryDate)
+		return event, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if req.Location != nil {
-		food.Location = *req.Location
+
+	response := s.toFoodResponse(food)
+	s.eventHub.Publish(food.UserID, EventFoodUpdated, response)
+
+	return response, nil
+}
+
+// lotFood is the food as one of its lots sees it, for the expiry rules which work on foods
+func lotFood(food *models.Food, lot *models.FoodLot) *models.Food {
+	view := *food
+	view.Lots = nil
+	view.ExpiryDate = lot.ExpiryDate
+	view.OpenedAt = lot.OpenedAt
+	if lot.PurchaseDate != nil {
+		view.PurchaseDate = lot.PurchaseDate
 	}
-	if req.IsHalal != nil {
-		food.IsHalal = *req.IsHalal
+	return &view
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
 	}
+	copied := *t
+	return &copied
+}
 
-	if err := s.foodRepo.Update(food); err != nil {
+// findUserFood finds a food of the user, other users' foods are not found
+func (s *FoodService) findUserFood(userID, id uuid.UUID) (*models.Food, error) {
+	food, err := s.foodRepo.FindByID(id)
+	if err != nil {
 		return nil, err
 	}
-
-	return s.toFoodResponse(food), nil
+	if food.UserID != userID {
+		return nil, errors.New("food not found")
+	}
+	return food, nil
 }
 
 // DeleteFood deletes a food item
 func (s *FoodService) DeleteFood(id uuid.UUID) error {
-	return s.foodRepo.Delete(id)
+	food, err := s.foodRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.foodRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.eventHub.Publish(food.UserID, EventFoodDeleted, map[string]interface{}{"id": id})
+	return nil
 }
 
 // GetStatistics returns food statistics
@@ -351,12 +606,16 @@ func (s *FoodService) SearchFood(userID uuid.UUID, query string, page, limit int
 
 // toFoodResponse converts Food model to FoodResponse DTO
 func (s *FoodService) toFoodResponse(food *models.Food) *FoodResponse {
-	var imageURL, barcode *string
+	var imageURL, thumbnailURL, barcode *string
 	var isHalal *bool
 	var calories, protein, carbs, fat *float64
 
 	if food.ImageURL != "" {
-		imageURL = &food.ImageURL
+		url := s.uploadService.ResolveURL(food.ImageURL)
+		imageURL = &url
+		if thumb := s.uploadService.ResolveThumbnailURL(food.ImageURL); thumb != "" {
+			thumbnailURL = &thumb
+		}
 	}
 	if food.Barcode != "" {
 		barcode = &food.Barcode
@@ -384,8 +643,10 @@ func (s *FoodService) toFoodResponse(food *models.Food) *FoodResponse {
 		Quantity:     food.Quantity,
 		Unit:         food.Unit,
 		ImageURL:     imageURL,
+		ThumbnailURL: thumbnailURL,
 		PurchaseDate: food.PurchaseDate,
 		ExpiryDate:   food.ExpiryDate,
+		OpenedAt:     food.OpenedAt,
 		Location:     food.Location,
 		IsHalal:      isHalal,
 		Barcode:      barcode,
@@ -397,6 +658,7 @@ func (s *FoodService) toFoodResponse(food *models.Food) *FoodResponse {
 		IsExpired:    food.IsExpired(),
 		CreatedAt:    food.CreatedAt,
 		UpdatedAt:    food.UpdatedAt,
+		Lots:         food.Lots,
 	}
 
 	days := food.DaysUntilExpiry()
@@ -412,7 +674,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 	foods := []CreateFoodRequest{
 		{
 			Name:       "Nasi Goreng",
-			Category:   "Cooked Food",
+			Category:   models.CategoryOther,
 			Quantity:   5,
 			Unit:       "portions",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 2)}[0],
@@ -422,7 +684,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Ayam Goreng Crispy",
-			Category:   "Cooked Food",
+			Category:   models.CategoryOther,
 			Quantity:   8,
 			Unit:       "pieces",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 3)}[0],
@@ -432,7 +694,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Roti Tawar",
-			Category:   "Bakery",
+			Category:   models.CategoryGrain,
 			Quantity:   1,
 			Unit:       "loaf",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 5)}[0],
@@ -442,7 +704,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Susu UHT Coklat",
-			Category:   "Beverages",
+			Category:   models.CategoryBeverage,
 			Quantity:   6,
 			Unit:       "boxes",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 30)}[0],
@@ -452,7 +714,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Telur Ayam",
-			Category:   "Fresh Produce",
+			Category:   models.CategoryDairy,
 			Quantity:   12,
 			Unit:       "pieces",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 14)}[0],
@@ -462,7 +724,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Kentang",
-			Category:   "Vegetables",
+			Category:   models.CategoryVegetable,
 			Quantity:   2,
 			Unit:       "kg",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 10)}[0],
@@ -472,7 +734,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Wortel",
-			Category:   "Vegetables",
+			Category:   models.CategoryVegetable,
 			Quantity:   1.5,
 			Unit:       "kg",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 7)}[0],
@@ -482,7 +744,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Mie Instan",
-			Category:   "Packaged Food",
+			Category:   models.CategoryGrain,
 			Quantity:   10,
 			Unit:       "packs",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 6, 0)}[0],
@@ -492,7 +754,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Keju Cheddar",
-			Category:   "Dairy",
+			Category:   models.CategoryDairy,
 			Quantity:   1,
 			Unit:       "block",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 20)}[0],
@@ -502,7 +764,7 @@ func (s *FoodService) SeedDummyFoodsForUser(userID uuid.UUID) error {
 		},
 		{
 			Name:       "Apel Fuji",
-			Category:   "Fruits",
+			Category:   models.CategoryFruit,
 			Quantity:   8,
 			Unit:       "pieces",
 			ExpiryDate: &[]time.Time{now.AddDate(0, 0, 6)}[0],
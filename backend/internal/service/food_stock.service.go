@@ -1,22 +1,29 @@
 package service
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/units"
+)
 
-// ReduceFoodStock reduces food quantity when used in journal
-func (s *FoodService) ReduceFoodStock(foodID uuid.UUID, portionUsed float64) error {
-	food, err := s.foodRepo.FindByID(foodID)
+// ReduceFoodStock reduces food quantity when used in journal, from the lots expiring first.
+// The amount used is converted to the food's unit, an empty unit means the food's own.
+func (s *FoodService) ReduceFoodStock(foodID uuid.UUID, amountUsed float64, unit string) error {
+	food, err := s.foodRepo.UpdateStock(foodID, func(food *models.Food) error {
+		used, err := toFoodUnit(food, amountUsed, unit)
+		if err != nil {
+			return err
+		}
+		food.Consume(used)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	// Reduce quantity
-	newQuantity := food.Quantity - portionUsed
-	if newQuantity < 0 {
-		newQuantity = 0
-	}
-
-	food.Quantity = newQuantity
-	return s.foodRepo.Update(food)
+	s.gamificationService.RecordActivity(food.UserID, models.ActivityFoodConsumed, &food.ID)
+	s.eventHub.Publish(food.UserID, EventFoodUpdated, s.toFoodResponse(food))
+	return nil
 }
 
 // GetStockPercentage calculates remaining stock percentage
@@ -33,3 +40,34 @@ func (s *FoodService) GetStockPercentage(foodID uuid.UUID) (float64, error) {
 	percentage := (food.Quantity / food.InitialQuantity) * 100
 	return percentage, nil
 }
+
+// toFoodUnit converts a quantity to a food's unit, using the food's name for densities and piece weights
+func toFoodUnit(food *models.Food, quantity float64, unit string) (float64, error) {
+	if unit == "" {
+		return quantity, nil
+	}
+	return units.Convert(quantity, unit, food.Unit, food.Name)
+}
+
+// purchasedUnit returns the canonical name of a product's unit for a purchased food,
+// units the library doesn't know are kept rather than failing the purchase
+func purchasedUnit(unit string) string {
+	if name, err := units.Normalize(unit); err == nil {
+		return name
+	}
+	return unit
+}
+
+// changeUnit switches a food to another unit, converting its stock when the units convert.
+// Otherwise the unit was wrong and only the label changes.
+func changeUnit(food *models.Food, unit string) {
+	if factor, err := units.Convert(1, food.Unit, unit, food.Name); err == nil {
+		food.Quantity *= factor
+		food.InitialQuantity *= factor
+		for i := range food.Lots {
+			food.Lots[i].Quantity *= factor
+			food.Lots[i].InitialQuantity *= factor
+		}
+	}
+	food.Unit = unit
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+)
+
+// Leaderboard periods and scopes
+const (
+	LeaderboardWeekly    = "weekly"
+	LeaderboardMonthly   = "monthly"
+	LeaderboardGlobal    = "global"
+	LeaderboardHousehold = "household"
+)
+
+// AchievementRule unlocks a badge once Metric reaches Target.
+// Metric is an activity type (counted) or a streak kind (longest streak).
+type AchievementRule struct {
+	Code        string
+	Name        string
+	Description string
+	Badge       string // icon name in the app
+	BonusPoints int
+	Metric      string
+	Target      int
+}
+
+// achievementRules is the achievement catalog, in display order
+var achievementRules = []AchievementRule{
+	{"first_food_save", "Pantry Starter", "Save your first food to storage", "pantry", 5, models.ActivityFoodSaved, 1},
+	{"foods_saved_50", "Stock Keeper", "Save 50 foods to storage", "shelves", 50, models.ActivityFoodSaved, 50},
+	{"first_scan", "First Scan", "Add a food by scanning it", "camera", 5, models.ActivityFoodScanned, 1},
+	{"scans_50", "Scan Master", "Add 50 foods by scanning them", "scanner", 50, models.ActivityFoodScanned, 50},
+	{"first_donation", "Kind Heart", "Make your first donation", "heart", 20, models.ActivityDonation, 1},
+	{"donations_10", "Community Hero", "Make 10 donations", "hands", 100, models.ActivityDonation, 10},
+	{"consumed_25", "Clean Plate", "Cook with food from your storage 25 times", "plate", 30, models.ActivityFoodConsumed, 25},
+	{"daily_streak_7", "On a Roll", "Use MakanSikScan 7 days in a row", "flame", 30, models.StreakDaily, 7},
+	{"daily_streak_30", "Habit Formed", "Use MakanSikScan 30 days in a row", "calendar", 100, models.StreakDaily, 30},
+	{"no_waste_7", "No-Waste Week", "Let no food expire unused for 7 days in a row", "leaf", 50, models.StreakNoWaste, 7},
+	{"no_waste_30", "Zero Waste Month", "Let no food expire unused for 30 days in a row", "earth", 200, models.StreakNoWaste, 30},
+}
+
+type AchievementResponse struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Badge       string     `json:"badge"`
+	BonusPoints int        `json:"bonus_points"`
+	Target      int        `json:"target"`
+	Progress    int        `json:"progress"`
+	Unlocked    bool       `json:"unlocked"`
+	AwardedAt   *time.Time `json:"awarded_at"`
+}
+
+type StreakResponse struct {
+	Kind     string     `json:"kind"`
+	Current  int        `json:"current"`
+	Longest  int        `json:"longest"`
+	LastDate *time.Time `json:"last_date"`
+}
+
+type LeaderboardEntry struct {
+	Rank   int       `json:"rank"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Avatar string    `json:"avatar"`
+	Points int       `json:"points"`
+	IsMe   bool      `json:"is_me"`
+}
+
+type LeaderboardResponse struct {
+	Period  string             `json:"period"`
+	Scope   string             `json:"scope"`
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	Entries []LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardEntry  `json:"me"` // nil when the user opted out
+}
+
+type LeaderboardPrivacyRequest struct {
+	OptOut *bool `json:"opt_out" binding:"required"`
+}
+
+type GamificationService struct {
+	gamificationRepo *repository.GamificationRepository
+	householdRepo    *repository.HouseholdRepository
+	userRepo         *repository.UserRepository
+	rewardService    *RewardService
+	uploadService    *UploadService
+	eventHub         *EventHub
+}
+
+func NewGamificationService(
+	gamificationRepo *repository.GamificationRepository,
+	householdRepo *repository.HouseholdRepository,
+	userRepo *repository.UserRepository,
+	rewardService *RewardService,
+	uploadService *UploadService,
+	eventHub *EventHub,
+) *GamificationService {
+	return &GamificationService{
+		gamificationRepo: gamificationRepo,
+		householdRepo:    householdRepo,
+		userRepo:         userRepo,
+		rewardService:    rewardService,
+		uploadService:    uploadService,
+		eventHub:         eventHub,
+	}
+}
+
+// RecordActivity records a domain event, extends the daily streak and
+// awards the achievements it unlocks. Failures are logged, never returned,
+// so gamification can't break the action that triggered it.
+func (s *GamificationService) RecordActivity(userID uuid.UUID, activityType string, referenceID *uuid.UUID) {
+	if s == nil {
+		return
+	}
+
+	activity := &models.UserActivity{UserID: userID, Type: activityType, ReferenceID: referenceID}
+	if err := s.gamificationRepo.CreateActivity(activity); err != nil {
+		log.Printf("⚠️  Failed to record %s activity for user %s: %v", activityType, userID, err)
+		return
+	}
+
+	today := calendarDay(time.Now())
+	if _, err := s.gamificationRepo.UpdateStreak(userID, models.StreakDaily, func(streak *models.UserStreak) {
+		advanceStreak(streak, today)
+	}); err != nil {
+		log.Printf("⚠️  Failed to update daily streak for user %s: %v", userID, err)
+	}
+
+	s.evaluateAchievements(userID, activityType, models.StreakDaily)
+}
+
+// UpdateNoWasteStreaks extends the no-waste streak of every user who used up or donated
+// food on the given day, or resets it when some of their food expired unused that day.
+// A day without either leaves the streak alone, so the next counted day starts over.
+// Returns the number of users updated.
+func (s *GamificationService) UpdateNoWasteStreaks(ctx context.Context, day time.Time) (int, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	to := from.AddDate(0, 0, 1)
+
+	// An empty pantry wastes nothing, only days that used food count
+	userIDs, err := s.gamificationRepo.FindUsersWithActivity([]string{models.ActivityFoodConsumed, models.ActivityDonation}, from, to)
+	if err != nil {
+		return 0, err
+	}
+	wastedIDs, err := s.gamificationRepo.FindUsersWithWaste(from, to)
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		seen[id] = true
+	}
+	wasted := make(map[uuid.UUID]bool, len(wastedIDs))
+	for _, id := range wastedIDs {
+		wasted[id] = true
+		if !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	date := calendarDay(day)
+	updated := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return updated, ctx.Err()
+		}
+		_, err := s.gamificationRepo.UpdateStreak(userID, models.StreakNoWaste, func(streak *models.UserStreak) {
+			if wasted[userID] {
+				streak.Current = 0
+				streak.LastDate = &date
+				return
+			}
+			advanceStreak(streak, date)
+		})
+		if err != nil {
+			return updated, err
+		}
+		updated++
+
+		if !wasted[userID] {
+			s.evaluateAchievements(userID, models.StreakNoWaste)
+		}
+	}
+	return updated, nil
+}
+
+// evaluateAchievements awards the not yet unlocked achievements measured by the given metrics
+func (s *GamificationService) evaluateAchievements(userID uuid.UUID, metrics ...string) {
+	progress, err := s.loadProgress(userID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load achievement progress for user %s: %v", userID, err)
+		return
+	}
+
+	unlocked, err := s.gamificationRepo.GetAchievements(userID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load achievements of user %s: %v", userID, err)
+		return
+	}
+	has := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		has[achievement.Code] = true
+	}
+
+	for _, rule := range achievementRules {
+		if has[rule.Code] || !containsString(metrics, rule.Metric) || progress[rule.Metric] < rule.Target {
+			continue
+		}
+		if err := s.award(userID, rule); err != nil {
+			log.Printf("⚠️  Failed to award achievement %s to user %s: %v", rule.Code, userID, err)
+		}
+	}
+}
+
+// award stores the achievement and pays its bonus points once
+func (s *GamificationService) award(userID uuid.UUID, rule AchievementRule) error {
+	achievement := &models.UserAchievement{
+		UserID:      userID,
+		Code:        rule.Code,
+		BonusPoints: rule.BonusPoints,
+		AwardedAt:   time.Now(),
+	}
+	created, err := s.gamificationRepo.CreateAchievement(achievement)
+	if err != nil || !created {
+		return err
+	}
+
+	if err := s.rewardService.EarnPoints(userID, rule.BonusPoints, "achievement",
+		fmt.Sprintf("Unlocked achievement: %s", rule.Name), &achievement.ID, "achievement"); err != nil {
+		return err
+	}
+
+	s.eventHub.Publish(userID, EventAchievementUnlocked, toAchievementResponse(rule, rule.Target, achievement))
+	return nil
+}
+
+// loadProgress returns activity counts and longest streaks keyed by metric
+func (s *GamificationService) loadProgress(userID uuid.UUID) (map[string]int, error) {
+	progress, err := s.gamificationRepo.CountActivities(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	streaks, err := s.gamificationRepo.GetStreaks(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, streak := range streaks {
+		progress[streak.Kind] = streak.Longest
+	}
+	return progress, nil
+}
+
+// GetAchievements returns the achievement catalog with the user's progress
+func (s *GamificationService) GetAchievements(userID uuid.UUID) ([]AchievementResponse, error) {
+	progress, err := s.loadProgress(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked, err := s.gamificationRepo.GetAchievements(userID)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]*models.UserAchievement, len(unlocked))
+	for i := range unlocked {
+		byCode[unlocked[i].Code] = &unlocked[i]
+	}
+
+	responses := make([]AchievementResponse, 0, len(achievementRules))
+	for _, rule := range achievementRules {
+		responses = append(responses, toAchievementResponse(rule, progress[rule.Metric], byCode[rule.Code]))
+	}
+	return responses, nil
+}
+
+// GetStreaks returns the user's streaks, a streak that was not extended in time counts as 0
+func (s *GamificationService) GetStreaks(userID uuid.UUID) ([]StreakResponse, error) {
+	streaks, err := s.gamificationRepo.GetStreaks(userID)
+	if err != nil {
+		return nil, err
+	}
+	byKind := make(map[string]models.UserStreak, len(streaks))
+	for _, streak := range streaks {
+		byKind[streak.Kind] = streak
+	}
+
+	today := calendarDay(time.Now())
+	responses := make([]StreakResponse, 0, 2)
+	for _, kind := range []string{models.StreakDaily, models.StreakNoWaste} {
+		streak := byKind[kind]
+		response := StreakResponse{Kind: kind, Current: streak.Current, Longest: streak.Longest, LastDate: streak.LastDate}
+
+		// The daily streak survives until the end of today, the no-waste
+		// streak is updated for yesterday after midnight
+		lastValid := today.AddDate(0, 0, -1)
+		if kind == models.StreakNoWaste {
+			lastValid = today.AddDate(0, 0, -2)
+		}
+		if streak.LastDate == nil || streak.LastDate.Before(lastValid) {
+			response.Current = 0
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetLeaderboard ranks users by points earned this week or month, among everyone or within the user's household
+func (s *GamificationService) GetLeaderboard(userID uuid.UUID, period, scope string, limit int) (*LeaderboardResponse, error) {
+	now := time.Now()
+	var from, to time.Time
+	switch period {
+	case LeaderboardWeekly:
+		// Weeks start on Monday
+		offset := (int(now.Weekday()) + 6) % 7
+		from = time.Date(now.Year(), now.Month(), now.Day()-offset, 0, 0, 0, 0, now.Location())
+		to = from.AddDate(0, 0, 7)
+	case LeaderboardMonthly:
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		to = from.AddDate(0, 1, 0)
+	default:
+		return nil, errors.New("invalid period, use weekly or monthly")
+	}
+
+	var memberIDs []uuid.UUID
+	switch scope {
+	case LeaderboardGlobal:
+	case LeaderboardHousehold:
+		household, err := s.householdRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if household == nil {
+			return nil, errors.New("you are not in a household")
+		}
+		if memberIDs, err = s.householdRepo.GetMemberIDs(household.ID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("invalid scope, use global or household")
+	}
+
+	rows, err := s.gamificationRepo.GetLeaderboard(from, to, memberIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &LeaderboardResponse{
+		Period:  period,
+		Scope:   scope,
+		From:    from,
+		To:      to,
+		Entries: make([]LeaderboardEntry, 0, len(rows)),
+	}
+	for i, row := range rows {
+		// Equal points share a rank
+		rank := i + 1
+		if i > 0 && row.Points == rows[i-1].Points {
+			rank = response.Entries[i-1].Rank
+		}
+		response.Entries = append(response.Entries, LeaderboardEntry{
+			Rank:   rank,
+			UserID: row.UserID,
+			Name:   row.Name,
+			Avatar: s.uploadService.ResolveURL(row.Avatar),
+			Points: row.Points,
+			IsMe:   row.UserID == userID,
+		})
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.LeaderboardOptOut {
+		points, ahead, err := s.gamificationRepo.GetLeaderboardPosition(userID, from, to, memberIDs)
+		if err != nil {
+			return nil, err
+		}
+		response.Me = &LeaderboardEntry{
+			Rank:   ahead + 1,
+			UserID: user.ID,
+			Name:   user.Name,
+			Avatar: s.uploadService.ResolveURL(user.Avatar),
+			Points: points,
+			IsMe:   true,
+		}
+	}
+
+	return response, nil
+}
+
+// SetLeaderboardOptOut hides or shows the user on all leaderboards
+func (s *GamificationService) SetLeaderboardOptOut(userID uuid.UUID, optOut bool) error {
+	return s.userRepo.SetLeaderboardOptOut(userID, optOut)
+}
+
+func toAchievementResponse(rule AchievementRule, progress int, achievement *models.UserAchievement) AchievementResponse {
+	if progress > rule.Target {
+		progress = rule.Target
+	}
+	response := AchievementResponse{
+		Code:        rule.Code,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Badge:       rule.Badge,
+		BonusPoints: rule.BonusPoints,
+		Target:      rule.Target,
+		Progress:    progress,
+	}
+	if achievement != nil {
+		response.Unlocked = true
+		response.AwardedAt = &achievement.AwardedAt
+		response.Progress = rule.Target
+	}
+	return response
+}
+
+// calendarDay returns t's local date as midnight UTC, the way date columns are read back
+func calendarDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// advanceStreak counts day in the streak, continuing it when day follows the last counted day
+func advanceStreak(streak *models.UserStreak, day time.Time) {
+	if streak.LastDate != nil {
+		last := calendarDay(*streak.LastDate)
+		if !day.After(last) {
+			return // already counted
+		}
+		if last.AddDate(0, 0, 1).Equal(day) {
+			streak.Current++
+		} else {
+			streak.Current = 1
+		}
+	} else {
+		streak.Current = 1
+	}
+
+	streak.LastDate = &day
+	if streak.Current > streak.Longest {
+		streak.Longest = streak.Current
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
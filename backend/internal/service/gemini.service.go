@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,13 +12,21 @@ import (
 
 	"github.com/google/generative-ai-go/genai"
 	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/prompts"
 	"google.golang.org/api/option"
 )
 
 type GeminiService struct {
-	apiKey      string
-	client      *http.Client
-	genaiClient *genai.Client
+	apiKey          string
+	client          *http.Client
+	genaiClient     *genai.Client
+	breaker         *circuitBreaker
+	timeout         time.Duration
+	maxRetries      int
+	retryBaseDelay  time.Duration
+	breakerCooldown time.Duration
+	cache           *aiCache
 }
 
 type GeminiRequest struct {
@@ -61,6 +69,7 @@ type FoodScanResult struct {
 	IsHalal       bool    `json:"is_halal"`
 	ExpiryDays    int     `json:"expiry_days"`
 	StorageTips   string  `json:"storage_tips"`
+	PromptVersion string  `json:"prompt_version"`
 }
 
 // Daily nutrition analysis
@@ -78,6 +87,8 @@ type NutritionAnalysis struct {
 	MealDistribution map[string]float64 `json:"meal_distribution"`
 	HealthScore      int                `json:"health_score"`
 	Warnings         []string           `json:"warnings"`
+	Source           string             `json:"source"` // gemini or mock
+	PromptVersion    string             `json:"prompt_version,omitempty"`
 }
 
 // Recipe recommendation from Gemini
@@ -102,6 +113,7 @@ type RecipeRecommendation struct {
 	MatchPercentage float64           `json:"match_percentage"`
 	MissingItems    []string          `json:"missing_items"`
 	Tips            string            `json:"tips"`
+	PromptVersion   string            `json:"prompt_version,omitempty"`
 }
 
 func NewGeminiService(cfg *config.Config) *GeminiService {
@@ -115,13 +127,19 @@ func NewGeminiService(cfg *config.Config) *GeminiService {
 		apiKey:      cfg.API.GeminiKey,
 		genaiClient: client,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: cfg.API.GeminiTimeout,
 		},
+		breaker:         newCircuitBreaker(cfg.API.GeminiBreakerThreshold, cfg.API.GeminiBreakerCooldown),
+		timeout:         cfg.API.GeminiTimeout,
+		maxRetries:      cfg.API.GeminiMaxRetries,
+		retryBaseDelay:  cfg.API.GeminiRetryBaseDelay,
+		breakerCooldown: cfg.API.GeminiBreakerCooldown,
+		cache:           newAICache(cfg.API.GeminiCacheTTL, cfg.API.GeminiCacheSize),
 	}
 }
 
 // AnalyzeFoodImage - Scan dan analisis makanan dari gambar
-func (s *GeminiService) AnalyzeFoodImage(imageURL string) (*FoodScanResult, error) {
+func (s *GeminiService) AnalyzeFoodImage(ctx context.Context, locale, imageURL string) (*FoodScanResult, error) {
 	fmt.Printf("🔍 Starting food image analysis...\n")
 	fmt.Printf("📷 Image URL: %s\n", imageURL)
 	fmt.Printf("🔑 API Key present: %v\n", s.apiKey != "")
@@ -133,52 +151,18 @@ func (s *GeminiService) AnalyzeFoodImage(imageURL string) (*FoodScanResult, erro
 
 	// Download and convert image to base64
 	fmt.Println("⬇️  Downloading image...")
-	imageData, mimeType, err := s.downloadImageAsBase64(imageURL)
+	imageData, mimeType, err := s.downloadImageAsBase64(ctx, imageURL)
 	if err != nil {
 		fmt.Printf("Failed to download image: %v\n", err)
 		return nil, fmt.Errorf("failed to download image: %w", err)
 	}
 	fmt.Printf("Image downloaded successfully (type: %s, size: %d bytes)\n", mimeType, len(imageData))
 
-	prompt := `Analyze this food image and provide a detailed analysis in JSON format with the following information:
-{
-  "name": "food name in English",
-  "category": "one of: Vegetable, Fruit, Meat, Fish, Dairy, Grain, Frozen, Canned, Beverage, Snack, Other",
-  "confidence": confidence score 0-100,
-  "calories": estimated calories per 100g,
-  "protein": protein in grams per 100g,
-  "carbohydrates": carbs in grams per 100g,
-  "fat": fat in grams per 100g,
-  "is_halal": true/false based on ingredients,
-  "expiry_days": estimated days until expiry from now,
-  "storage_tips": storage recommendations in English
-}
-
-Only return the JSON, no additional text.`
-
-	fmt.Println("🤖 Calling Gemini Vision API...")
-	response, err := s.callGeminiVision(prompt, imageData, mimeType)
-	if err != nil {
-		fmt.Printf("Gemini API call failed: %v\n", err)
-		return nil, fmt.Errorf("gemini API call failed: %w", err)
-	}
-	fmt.Println("Received response from Gemini")
-
-	var result FoodScanResult
-	jsonStr := s.extractJSON(response)
-	fmt.Printf("📝 Extracted JSON: %s\n", jsonStr)
-
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		fmt.Printf("Failed to parse Gemini response: %v\n", err)
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	fmt.Printf("Successfully analyzed food with Gemini: %s (confidence: %.1f%%)\n", result.Name, result.Confidence)
-	return &result, nil
+	return s.analyzeFoodImage(ctx, locale, imageData, mimeType)
 }
 
 // AnalyzeFoodImageBase64 - Scan dan analisis makanan dari base64 image
-func (s *GeminiService) AnalyzeFoodImageBase64(base64Data string) (*FoodScanResult, error) {
+func (s *GeminiService) AnalyzeFoodImageBase64(ctx context.Context, locale, base64Data string) (*FoodScanResult, error) {
 	fmt.Printf("🔍 Starting food image analysis from base64...\n")
 	fmt.Printf("🔑 API Key present: %v\n", s.apiKey != "")
 	fmt.Printf("📊 Base64 data size: %d bytes\n", len(base64Data))
@@ -198,45 +182,52 @@ func (s *GeminiService) AnalyzeFoodImageBase64(base64Data string) (*FoodScanResu
 	}
 	fmt.Printf("📷 Detected mime type: %s\n", mimeType)
 
-	prompt := `Analyze this food image and provide a detailed analysis in JSON format with the following information:
-{
-  "name": "food name in English",
-  "category": "one of: Vegetable, Fruit, Meat, Fish, Dairy, Grain, Frozen, Canned, Beverage, Snack, Other",
-  "confidence": confidence score 0-100,
-  "calories": estimated calories per 100g,
-  "protein": protein in grams per 100g,
-  "carbohydrates": carbs in grams per 100g,
-  "fat": fat in grams per 100g,
-  "is_halal": true/false based on ingredients,
-  "expiry_days": estimated days until expiry from now,
-  "storage_tips": storage recommendations in English
+	return s.analyzeFoodImage(ctx, locale, base64Data, mimeType)
 }
 
-Only return the JSON, no additional text.`
+// analyzeFoodImage runs the food scan prompt on a base64 image
+func (s *GeminiService) analyzeFoodImage(ctx context.Context, locale, imageData, mimeType string) (*FoodScanResult, error) {
+	prompt, err := prompts.Render(prompts.FoodScan, locale, map[string]interface{}{
+		"Categories": models.FoodCategories,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Println("🤖 Calling Gemini Vision API with base64 image...")
-	response, err := s.callGeminiVision(prompt, base64Data, mimeType)
+	// Decode base64 image data
+	image, err := base64.StdEncoding.DecodeString(imageData)
 	if err != nil {
-		fmt.Printf("Gemini API call failed: %v\n", err)
-		return nil, fmt.Errorf("gemini API call failed: %w", err)
+		return nil, fmt.Errorf("gagal men-decode base64 image data: %w", err)
 	}
-	fmt.Println("Received response from Gemini")
 
-	var result FoodScanResult
-	jsonStr := s.extractJSON(response)
-	fmt.Printf("📝 Extracted JSON: %s\n", jsonStr)
+	// The same photo analysed by the same prompt gives the same answer
+	cacheKey := scanCacheKey(image, prompt.Version)
+	if !aiCacheBypassed(ctx) {
+		if cached, ok := s.cache.get(aiCacheScan, cacheKey, time.Now()); ok {
+			result := cached.(FoodScanResult)
+			fmt.Printf("Using cached analysis: %s (confidence: %.1f%%)\n", result.Name, result.Confidence)
+			return &result, nil
+		}
+	}
 
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		fmt.Printf("Failed to parse Gemini response: %v\n", err)
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	fmt.Printf("🤖 Calling Gemini Vision API (prompt %s)...\n", prompt.Version)
+	var result FoodScanResult
+	if err := s.callGeminiVision(ctx, foodScanSchema, &result, prompt.Text, image, mimeType); err != nil {
+		fmt.Printf("Gemini API call failed: %v\n", err)
+		return nil, fmt.Errorf("gemini API call failed: %w", err)
 	}
+	fmt.Println("Received response from Gemini")
 
+	result.PromptVersion = prompt.Version
+	s.cache.set(cacheKey, result, time.Now())
 	fmt.Printf("Successfully analyzed food with Gemini: %s (confidence: %.1f%%)\n", result.Name, result.Confidence)
 	return &result, nil
 }
 
 // AnalyzeDailyNutrition - Analisis intake nutrisi harian
 func (s *GeminiService) AnalyzeDailyNutrition(
+	ctx context.Context,
+	locale string,
 	totalCalories, totalProtein, totalCarbs, totalFat float64,
 	meals []string,
 	userAge int,
@@ -246,72 +237,51 @@ func (s *GeminiService) AnalyzeDailyNutrition(
 	activityLevel string,
 ) (*NutritionAnalysis, error) {
 	if s.apiKey == "" {
+		logFallback("nutrition analysis", AISourceMock, errors.New("API key not configured"))
 		return s.mockNutritionAnalysis(totalCalories, totalProtein, totalCarbs, totalFat), nil
 	}
 
-	prompt := fmt.Sprintf(`Analyze this person's daily nutrition intake:
-
-User Profile:
-- Age: %d years
-- Weight: %.1f kg
-- Height: %.1f cm
-- Gender: %s
-- Activity Level: %s
-
-Today's Intake:
-- Total Calories: %.1f kcal
-- Total Protein: %.1f g
-- Total Carbohydrates: %.1f g
-- Total Fat: %.1f g
-
-Meals consumed today:
-%s
-
-Please provide a comprehensive analysis in JSON format:
-{
-  "total_calories": current total,
-  "total_protein": current total,
-  "total_carbs": current total,
-  "total_fat": current total,
-  "calorie_goal": recommended daily calories based on profile,
-  "protein_goal": recommended daily protein,
-  "carbs_goal": recommended daily carbs,
-  "fat_goal": recommended daily fat,
-  "calorie_status": "deficit/balanced/surplus",
-  "recommendations": ["recommendation 1", "recommendation 2"],
-  "meal_distribution": {"breakfast": percentage, "lunch": percentage, "dinner": percentage, "snack": percentage},
-  "health_score": score 0-100,
-  "warnings": ["warning 1 if any"]
-}
-
-Provide recommendations in English. Only return JSON, no additional text.`,
-		userAge, userWeight, userHeight, userGender, activityLevel,
-		totalCalories, totalProtein, totalCarbs, totalFat,
-		strings.Join(meals, "\n"))
-
-	response, err := s.callGemini(prompt)
+	prompt, err := prompts.Render(prompts.NutritionAnalysis, locale, map[string]interface{}{
+		"Age":           userAge,
+		"Weight":        userWeight,
+		"Height":        userHeight,
+		"Gender":        userGender,
+		"ActivityLevel": activityLevel,
+		"Calories":      totalCalories,
+		"Protein":       totalProtein,
+		"Carbs":         totalCarbs,
+		"Fat":           totalFat,
+		"Meals":         meals,
+	})
 	if err != nil {
-		return s.mockNutritionAnalysis(totalCalories, totalProtein, totalCarbs, totalFat), nil
+		return nil, err
 	}
 
 	var result NutritionAnalysis
-	if err := json.Unmarshal([]byte(s.extractJSON(response)), &result); err != nil {
+	if err := s.generateJSON(ctx, nutritionAnalysisSchema, &result, genai.Text(prompt.Text)); err != nil {
+		logFallback("nutrition analysis", AISourceMock, err)
 		return s.mockNutritionAnalysis(totalCalories, totalProtein, totalCarbs, totalFat), nil
 	}
 
+	result.Source = AISourceGemini
+	result.PromptVersion = prompt.Version
 	return &result, nil
 }
 
-// GenerateRecipeRecommendations - Generate rekomendasi resep berdasarkan bahan yang tersedia
+// GenerateRecipeRecommendations - Generate rekomendasi resep berdasarkan bahan yang tersedia.
+// The returned source is AISourceMock when Gemini failed and sample recipes were returned.
 func (s *GeminiService) GenerateRecipeRecommendations(
+	ctx context.Context,
+	locale string,
 	availableIngredients []string,
 	dietaryPreferences map[string]bool, // halal, vegetarian, vegan
 	maxPrepTime int,
 	difficulty string,
 	numberOfRecipes int,
-) ([]RecipeRecommendation, error) {
+) ([]RecipeRecommendation, string, error) {
 	if s.apiKey == "" {
-		return s.mockRecipeRecommendations(availableIngredients), nil
+		logFallback("recipe recommendations", AISourceMock, errors.New("API key not configured"))
+		return s.mockRecipeRecommendations(availableIngredients), AISourceMock, nil
 	}
 
 	preferences := []string{}
@@ -325,110 +295,53 @@ func (s *GeminiService) GenerateRecipeRecommendations(
 		preferences = append(preferences, "vegan")
 	}
 
-	prompt := fmt.Sprintf(`Generate %d recipe recommendations based on available ingredients.
-
-Available Ingredients:
-%s
-
-Preferences:
-- Dietary: %s
-- Max Preparation Time: %d minutes
-- Difficulty: %s
-
-Please provide %d recipes in JSON array format:
-[
-  {
-    "title": "recipe name in English",
-    "description": "brief description in English",
-    "ingredients": {"ingredient1": "amount", "ingredient2": "amount"},
-    "instructions": ["step 1", "step 2"],
-    "prep_time": minutes,
-    "cook_time": minutes,
-    "servings": number,
-    "difficulty": "Easy/Medium/Hard",
-    "category": "Breakfast/Lunch/Dinner/Snack/Dessert",
-    "cuisine": "Indonesian/Western/etc",
-    "calories": per serving,
-    "protein": grams per serving,
-    "carbs": grams per serving,
-    "fat": grams per serving,
-    "is_halal": true/false,
-    "is_vegetarian": true/false,
-    "is_vegan": true/false,
-    "match_percentage": percentage of available ingredients,
-    "missing_items": ["item1", "item2"],
-    "tips": "cooking tips in English"
-  }
-]
-
-Prioritize recipes with highest match_percentage. Instructions in English. Only return JSON array.`,
-		numberOfRecipes,
-		strings.Join(availableIngredients, "\n- "),
-		strings.Join(preferences, ", "),
-		maxPrepTime,
-		difficulty,
-		numberOfRecipes)
-
-	response, err := s.callGemini(prompt)
+	prompt, err := prompts.Render(prompts.RecipeRecommendations, locale, map[string]interface{}{
+		"Count":       numberOfRecipes,
+		"Ingredients": availableIngredients,
+		"Dietary":     preferences,
+		"MaxPrepTime": maxPrepTime,
+		"Difficulty":  difficulty,
+	})
 	if err != nil {
-		return s.mockRecipeRecommendations(availableIngredients), nil
+		return nil, "", err
 	}
 
 	var results []RecipeRecommendation
-	jsonStr := s.extractJSON(response)
-	if err := json.Unmarshal([]byte(jsonStr), &results); err != nil {
-		return s.mockRecipeRecommendations(availableIngredients), nil
+	if err := s.generateJSON(ctx, recipeRecommendationSchema, &results, genai.Text(prompt.Text)); err != nil {
+		logFallback("recipe recommendations", AISourceMock, err)
+		return s.mockRecipeRecommendations(availableIngredients), AISourceMock, nil
 	}
 
-	return results, nil
+	for i := range results {
+		results[i].PromptVersion = prompt.Version
+	}
+	return results, AISourceGemini, nil
 }
 
 // callGemini - Helper untuk call Gemini API (text-only) menggunakan SDK
-func (s *GeminiService) callGemini(prompt string) (string, error) {
-	if s.genaiClient == nil {
-		return "", fmt.Errorf("Gemini client not initialized")
-	}
-
-	model := s.genaiClient.GenerativeModel("gemini-2.5-flash")
-
-	ctx := context.Background()
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		return "", fmt.Errorf("gemini API error: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no response from Gemini")
-	}
-
-	part := resp.Candidates[0].Content.Parts[0]
-	if txt, ok := part.(genai.Text); ok {
-		return string(txt), nil
-	}
-
-	return "", fmt.Errorf("response part bukanlah teks")
+func (s *GeminiService) callGemini(ctx context.Context, prompt string) (string, error) {
+	return s.generate(ctx, nil, genai.Text(prompt))
 }
 
 // GenerateContent is an alias for callGemini for external use
-func (s *GeminiService) GenerateContent(prompt string) (string, error) {
-	return s.callGemini(prompt)
+func (s *GeminiService) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	return s.callGemini(ctx, prompt)
 }
 
-// callGeminiVision - Helper untuk call Gemini Vision API dengan image menggunakan SDK
-func (s *GeminiService) callGeminiVision(prompt string, imageData string, mimeType string) (string, error) {
-	if s.genaiClient == nil {
-		return "", fmt.Errorf("Gemini client not initialized")
-	}
-
-	fmt.Printf("🔧 callGeminiVision called with mimeType: '%s'\n", mimeType)
+// CacheStats returns the size and hit/miss counters of the response cache
+func (s *GeminiService) CacheStats() AICacheStats {
+	return s.cache.stats()
+}
 
-	model := s.genaiClient.GenerativeModel("gemini-2.5-flash")
+// PurgeCache drops every cached response and returns how many there were
+func (s *GeminiService) PurgeCache() int {
+	return s.cache.purge()
+}
 
-	// Decode base64 image data
-	decodedData, err := base64.StdEncoding.DecodeString(imageData)
-	if err != nil {
-		return "", fmt.Errorf("gagal men-decode base64 image data: %w", err)
-	}
+// callGeminiVision - Helper untuk call Gemini Vision API dengan image menggunakan SDK,
+// the validated JSON response is decoded into dest
+func (s *GeminiService) callGeminiVision(ctx context.Context, schema *aiSchema, dest interface{}, prompt string, decodedData []byte, mimeType string) error {
+	fmt.Printf("🔧 callGeminiVision called with mimeType: '%s'\n", mimeType)
 
 	fmt.Printf("Decoded image data: %d bytes\n", len(decodedData))
 
@@ -440,29 +353,16 @@ func (s *GeminiService) callGeminiVision(prompt string, imageData string, mimeTy
 
 	fmt.Printf("🧹 Using Blob with MIMEType: 'image/jpeg'\n")
 
-	// Generate content
-	ctx := context.Background()
-	resp, err := model.GenerateContent(ctx, parts...)
-	if err != nil {
-		return "", fmt.Errorf("gemini API error: %w", err)
-	}
-
-	// Extract text from response
-	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no response content from Gemini")
-	}
-
-	part := resp.Candidates[0].Content.Parts[0]
-	if txt, ok := part.(genai.Text); ok {
-		return string(txt), nil
-	}
-
-	return "", fmt.Errorf("response part [0] bukanlah teks")
+	return s.generateJSON(ctx, schema, dest, parts...)
 }
 
 // downloadImageAsBase64 - Download image dari URL dan convert ke base64
-func (s *GeminiService) downloadImageAsBase64(imageURL string) (string, string, error) {
-	resp, err := s.client.Get(imageURL)
+func (s *GeminiService) downloadImageAsBase64(ctx context.Context, imageURL string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid image URL: %w", err)
+	}
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to download image: %w", err)
 	}
@@ -548,6 +448,7 @@ func (s *GeminiService) mockNutritionAnalysis(calories, protein, carbs, fat floa
 		},
 		HealthScore: 85,
 		Warnings:    []string{},
+		Source:      AISourceMock,
 	}
 }
 
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrAIUnavailable is returned when Gemini keeps failing or the circuit breaker is open
+var ErrAIUnavailable = errors.New("AI unavailable, please try again later")
+
+// Sources of AI-backed data, returned to clients so they know when data is not from the model
+const (
+	AISourceGemini   = "gemini"
+	AISourceMock     = "mock"
+	AISourceDatabase = "database"
+)
+
+const geminiModel = "gemini-2.5-flash"
+
+// circuitBreaker opens after threshold consecutive failures and fails fast until
+// the cooldown has passed. Then a single trial call decides whether it closes again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool // a trial call is in flight while half-open
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call may go through
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// success closes the circuit
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trial = false
+}
+
+// failure counts a failed call and returns true when it opened the circuit
+func (b *circuitBreaker) failure(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return false
+	}
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// abandon ends a call that tells nothing about the upstream, e.g. one canceled by the client
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// generate calls the model with a timeout per attempt. 429 and 5xx responses and
+// network errors are retried with exponential backoff, and calls that still fail
+// count towards the circuit breaker and return ErrAIUnavailable.
+// With a schema the model answers in JSON mode.
+func (s *GeminiService) generate(ctx context.Context, schema *aiSchema, parts ...genai.Part) (string, error) {
+	if s.genaiClient == nil {
+		return "", fmt.Errorf("Gemini client not initialized")
+	}
+	if !s.breaker.allow(time.Now()) {
+		return "", ErrAIUnavailable
+	}
+
+	model := s.genaiClient.GenerativeModel(geminiModel)
+	if schema != nil {
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = schema.responseSchema()
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var resp *genai.GenerateContentResponse
+		resp, err = s.generateOnce(ctx, model, parts)
+		if err == nil {
+			s.breaker.success()
+			return responseText(resp)
+		}
+		if ctx.Err() != nil {
+			s.breaker.abandon()
+			return "", ctx.Err()
+		}
+		if !isTransientAIError(err) {
+			// The API answered, it just rejected this request
+			s.breaker.success()
+			return "", fmt.Errorf("gemini API error: %w", err)
+		}
+		if attempt >= s.maxRetries {
+			break
+		}
+
+		delay := s.retryDelay(attempt)
+		log.Printf("⚠️  Gemini call failed (attempt %d of %d), retrying in %s: %v", attempt+1, s.maxRetries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			s.breaker.abandon()
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if s.breaker.failure(time.Now()) {
+		log.Printf("⚠️  Gemini circuit breaker opened for %s", s.breakerCooldown)
+	}
+	return "", fmt.Errorf("%w: %v", ErrAIUnavailable, err)
+}
+
+func (s *GeminiService) generateOnce(ctx context.Context, model *genai.GenerativeModel, parts []genai.Part) (*genai.GenerateContentResponse, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	return model.GenerateContent(ctx, parts...)
+}
+
+// retryDelay doubles the base delay on every attempt and adds up to 50% jitter
+func (s *GeminiService) retryDelay(attempt int) time.Duration {
+	delay := s.retryBaseDelay << attempt
+	if delay <= 0 {
+		return 0
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// isTransientAIError reports whether a call may succeed when retried
+func isTransientAIError(err error) bool {
+	var coder interface{ HTTPCode() int }
+	if errors.As(err, &coder) && coder.HTTPCode() > 0 {
+		return isTransientStatus(coder.HTTPCode())
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return isTransientStatus(apiErr.Code)
+	}
+	// Attempt timeouts and connection problems
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isTransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// responseText returns the text of the first candidate
+func responseText(resp *genai.GenerateContentResponse) (string, error) {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no response from Gemini")
+	}
+
+	part := resp.Candidates[0].Content.Parts[0]
+	if txt, ok := part.(genai.Text); ok {
+		return string(txt), nil
+	}
+
+	return "", fmt.Errorf("response part bukanlah teks")
+}
+
+// logFallback records that data came from somewhere other than the model
+func logFallback(operation, source string, err error) {
+	log.Printf("⚠️  Gemini %s unavailable, using %s data: %v", operation, source, err)
+}
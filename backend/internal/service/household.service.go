@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+)
+
+type CreateHouseholdRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type JoinHouseholdRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+}
+
+type HouseholdMemberResponse struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Name     string    `json:"name"`
+	Avatar   string    `json:"avatar"`
+	IsOwner  bool      `json:"is_owner"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type HouseholdResponse struct {
+	ID         uuid.UUID                 `json:"id"`
+	Name       string                    `json:"name"`
+	InviteCode string                    `json:"invite_code"`
+	OwnerID    uuid.UUID                 `json:"owner_id"`
+	Members    []HouseholdMemberResponse `json:"members"`
+	CreatedAt  time.Time                 `json:"created_at"`
+}
+
+type HouseholdService struct {
+	householdRepo *repository.HouseholdRepository
+	uploadService *UploadService
+}
+
+func NewHouseholdService(householdRepo *repository.HouseholdRepository, uploadService *UploadService) *HouseholdService {
+	return &HouseholdService{
+		householdRepo: householdRepo,
+		uploadService: uploadService,
+	}
+}
+
+// CreateHousehold creates a household owned by the user
+func (s *HouseholdService) CreateHousehold(userID uuid.UUID, req *CreateHouseholdRequest) (*HouseholdResponse, error) {
+	existing, err := s.householdRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("you are already in a household")
+	}
+
+	household := &models.Household{
+		Name:       strings.TrimSpace(req.Name),
+		InviteCode: generateInviteCode(),
+		OwnerID:    userID,
+	}
+	if err := s.householdRepo.Create(household); err != nil {
+		return nil, err
+	}
+
+	return s.GetMyHousehold(userID)
+}
+
+// JoinHousehold adds the user to the household with the invite code
+func (s *HouseholdService) JoinHousehold(userID uuid.UUID, req *JoinHouseholdRequest) (*HouseholdResponse, error) {
+	existing, err := s.householdRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("you are already in a household")
+	}
+
+	household, err := s.householdRepo.FindByInviteCode(strings.ToUpper(strings.TrimSpace(req.InviteCode)))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.householdRepo.AddMember(household.ID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetMyHousehold(userID)
+}
+
+// LeaveHousehold removes the user from their household
+func (s *HouseholdService) LeaveHousehold(userID uuid.UUID) error {
+	existing, err := s.householdRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.New("you are not in a household")
+	}
+	return s.householdRepo.RemoveMember(userID)
+}
+
+// GetMyHousehold returns the user's household with its members
+func (s *HouseholdService) GetMyHousehold(userID uuid.UUID) (*HouseholdResponse, error) {
+	household, err := s.householdRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if household == nil {
+		return nil, errors.New("you are not in a household")
+	}
+
+	response := &HouseholdResponse{
+		ID:         household.ID,
+		Name:       household.Name,
+		InviteCode: household.InviteCode,
+		OwnerID:    household.OwnerID,
+		Members:    make([]HouseholdMemberResponse, 0, len(household.Members)),
+		CreatedAt:  household.CreatedAt,
+	}
+	for _, member := range household.Members {
+		response.Members = append(response.Members, HouseholdMemberResponse{
+			UserID:   member.UserID,
+			Name:     member.User.Name,
+			Avatar:   s.uploadService.ResolveURL(member.User.Avatar),
+			IsOwner:  member.UserID == household.OwnerID,
+			JoinedAt: member.JoinedAt,
+		})
+	}
+	return response, nil
+}
+
+func generateInviteCode() string {
+	return strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")[:8])
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"math"
 	"strings"
 	"unicode"
 
+	"github.com/varel183/MakanSikScan/backend/internal/units"
 	"golang.org/x/text/runes"
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
@@ -142,39 +144,84 @@ func (s *IngredientMatcherService) MatchIngredient(recipeIngredient string, user
 	return 0
 }
 
-// MatchIngredientsWithFoods matches recipe ingredients with user's food storage
-// Returns: matchedCount, totalIngredients, matchedIngredients[]
+// PantryItem is a food in storage that recipe ingredients are matched against
+type PantryItem struct {
+	Name     string
+	Quantity float64
+	Unit     string
+}
+
+// MatchIngredientsWithFoods matches recipe ingredients with user's food storage.
+// Ingredients with an amount, e.g. "500 gr ayam", are compared with the stock converted
+// to the recipe's unit and only count as matched when there is enough. Those short on stock
+// are returned apart, so matchedCount is always len(matchedIngredients).
+// Returns: matchedCount, totalIngredients, matchedIngredients[], insufficientIngredients[]
 func (s *IngredientMatcherService) MatchIngredientsWithFoods(
 	recipeIngredients []string,
-	userFoods []string,
-) (int, int, []map[string]interface{}) {
+	userFoods []PantryItem,
+) (int, int, []map[string]interface{}, []map[string]interface{}) {
 
-	matchedCount := 0
 	matchedIngredients := make([]map[string]interface{}, 0)
+	insufficientIngredients := make([]map[string]interface{}, 0)
 
 	for _, ingredient := range recipeIngredients {
+		// Match on the name without the amount
+		required, hasAmount := units.ParseQuantity(ingredient)
+		name := ingredient
+		if hasAmount && required.Name != "" {
+			name = required.Name
+		}
+
 		bestMatch := ""
 		bestScore := 0
-
 		for _, food := range userFoods {
-			score := s.MatchIngredient(ingredient, food)
+			score := s.MatchIngredient(name, food.Name)
 			if score > bestScore {
 				bestScore = score
-				bestMatch = food
+				bestMatch = food.Name
+			}
+		}
+
+		if bestScore < 40 { // Minimum threshold for considering it a match
+			continue
+		}
+
+		match := map[string]interface{}{
+			"ingredient":   ingredient,
+			"matched_with": bestMatch,
+			"match_score":  bestScore,
+		}
+
+		// Stock of every food matching as well, e.g. two packs bought separately
+		enough := true
+		if hasAmount {
+			available, comparable := 0.0, false
+			for _, food := range userFoods {
+				if s.MatchIngredient(name, food.Name) != bestScore {
+					continue
+				}
+				if quantity, err := units.Convert(food.Quantity, food.Unit, required.Unit, name); err == nil {
+					available += quantity
+					comparable = true
+				}
+			}
+			if comparable {
+				enough = available >= required.Amount
+				match["required_quantity"] = required.Amount
+				match["available_quantity"] = math.Round(available*100) / 100
+				match["unit"] = required.Unit
+				match["enough"] = enough
 			}
 		}
 
-		if bestScore >= 40 { // Minimum threshold for considering it a match
-			matchedCount++
-			matchedIngredients = append(matchedIngredients, map[string]interface{}{
-				"ingredient":   ingredient,
-				"matched_with": bestMatch,
-				"match_score":  bestScore,
-			})
+		if enough {
+			matchedIngredients = append(matchedIngredients, match)
+		} else {
+			insufficientIngredients = append(insufficientIngredients, match)
 		}
 	}
 
-	return matchedCount, len(recipeIngredients), matchedIngredients
+	return len(matchedIngredients), len(recipeIngredients), matchedIngredients, insufficientIngredients
 }
 
 // CalculateMatchPercentage calculates match percentage
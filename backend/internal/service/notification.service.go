@@ -1,290 +1,437 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
 )
 
 type NotificationType string
 
 const (
-	NotificationTypeExpiringSoon NotificationType = "expiring_soon"
-	NotificationTypeExpired      NotificationType = "expired"
-	NotificationTypeLowStock     NotificationType = "low_stock"
+	NotificationTypeExpiringSoon    NotificationType = "expiring_soon"
+	NotificationTypeExpired         NotificationType = "expired"
+	NotificationTypeLowStock        NotificationType = "low_stock"
+	NotificationTypeOrderReady      NotificationType = "order_ready"
+	NotificationTypeVoucherExpiring NotificationType = "voucher_expiring"
+	NotificationTypeDailyDigest     NotificationType = "daily_digest"
 )
 
-type NotificationResponse struct {
-	ID          string           `json:"id"`
-	Type        NotificationType `json:"type"`
-	Title       string           `json:"title"`
-	Message     string           `json:"message"`
-	FoodID      uuid.UUID        `json:"food_id"`
-	FoodName    string           `json:"food_name"`
-	Quantity    float64          `json:"quantity"`
-	Unit        string           `json:"unit"`
-	ExpiryDate  *time.Time       `json:"expiry_date,omitempty"`
-	DaysUntilExp *int            `json:"days_until_expiry,omitempty"`
-	Severity    string           `json:"severity"` // info, warning, critical
-	CreatedAt   time.Time        `json:"created_at"`
-}
+const (
+	// Foods at or below this percentage of their initial quantity are low on stock
+	lowStockPercentage = 20
+	// Upper bound for expiry thresholds, also the horizon of the expiring query
+	maxExpiryThresholdDays = 90
+	// Redemptions expiring within this many days trigger a reminder
+	voucherExpiringDays = 3
+)
 
 type NotificationService struct {
-	foodRepo     *repository.FoodRepository
-	notifReadRepo *repository.NotificationReadRepository
+	notificationRepo *repository.NotificationRepository
+	foodRepo         *repository.FoodRepository
+	orderRepo        *repository.OrderRepository
+	voucherRepo      *repository.VoucherRepository
+	notifReadRepo    *repository.NotificationReadRepository
+	prefRepo         *repository.NotificationPreferenceRepository
+	pushService      *PushService
+	eventHub         *EventHub
 }
 
-func NewNotificationService(foodRepo *repository.FoodRepository, notifReadRepo *repository.NotificationReadRepository) *NotificationService {
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	foodRepo *repository.FoodRepository,
+	orderRepo *repository.OrderRepository,
+	voucherRepo *repository.VoucherRepository,
+	notifReadRepo *repository.NotificationReadRepository,
+	prefRepo *repository.NotificationPreferenceRepository,
+	pushService *PushService,
+	eventHub *EventHub,
+) *NotificationService {
 	return &NotificationService{
-		foodRepo:     foodRepo,
-		notifReadRepo: notifReadRepo,
+		notificationRepo: notificationRepo,
+		foodRepo:         foodRepo,
+		orderRepo:        orderRepo,
+		voucherRepo:      voucherRepo,
+		notifReadRepo:    notifReadRepo,
+		prefRepo:         prefRepo,
+		pushService:      pushService,
+		eventHub:         eventHub,
 	}
 }
 
-// GetUserNotifications retrieves all UNREAD notifications for a user based on their food storage
-func (s *NotificationService) GetUserNotifications(userID uuid.UUID) ([]NotificationResponse, error) {
-	// Get read notifications
-	readNotifs, err := s.notifReadRepo.GetReadNotifications(userID)
+// GetUserNotifications retrieves the user's inbox with pagination
+func (s *NotificationService) GetUserNotifications(userID uuid.UUID, status string, page, limit int) ([]models.Notification, int64, error) {
+	return s.notificationRepo.FindByUser(userID, status, nil, page, limit)
+}
+
+// GetExpiringNotifications retrieves only UNREAD expiring soon and expired notifications
+func (s *NotificationService) GetExpiringNotifications(userID uuid.UUID, page, limit int) ([]models.Notification, int64, error) {
+	types := []string{string(NotificationTypeExpiringSoon), string(NotificationTypeExpired)}
+	return s.notificationRepo.FindByUser(userID, "unread", types, page, limit)
+}
+
+// GetUnreadCount returns the number of unread notifications
+func (s *NotificationService) GetUnreadCount(userID uuid.UUID) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkNotificationAsRead marks a notification as read.
+// Non-UUID IDs come from older app versions and are recorded in the legacy read table.
+func (s *NotificationService) MarkNotificationAsRead(userID uuid.UUID, notificationID string) error {
+	id, err := uuid.Parse(notificationID)
 	if err != nil {
-		readNotifs = make(map[string]bool) // Continue even if error
+		return s.notifReadRepo.MarkAsRead(userID, notificationID)
 	}
+	return s.notificationRepo.SetRead(userID, id, true)
+}
 
-	notifications := make([]NotificationResponse, 0)
-
-	// Get foods expiring within 30 days and categorize them
-	expiringSoon, err := s.foodRepo.FindExpiringSoon(userID, 30)
-	if err == nil {
-		for _, food := range expiringSoon {
-			days := food.DaysUntilExpiry()
-
-			// Determine notification type, severity, and title based on days
-			var notifType NotificationType
-			var severity string
-			var title string
-			var notifID string
-
-			// Generate unique ID based on current days remaining
-			// This ensures notification changes as expiry approaches
-			if days <= 1 {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_1day_%s_%d", food.ID.String(), days)
-				severity = "critical"
-				if days == 0 {
-					title = "Food Expiring Today!"
-				} else {
-					title = "Food Expiring Tomorrow!"
-				}
-			} else if days <= 3 {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_3days_%s_%d", food.ID.String(), days)
-				severity = "critical"
-				title = "Food Expiring in 3 Days!"
-			} else if days <= 7 {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_1week_%s_%d", food.ID.String(), days)
-				severity = "warning"
-				title = "Food Expiring This Week"
-			} else {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_1month_%s_%d", food.ID.String(), days)
-				severity = "info"
-				title = "Food Expiring This Month"
-			}
+// MarkNotificationAsUnread marks a notification as unread
+func (s *NotificationService) MarkNotificationAsUnread(userID, notificationID uuid.UUID) error {
+	return s.notificationRepo.SetRead(userID, notificationID, false)
+}
 
-			// Skip if already read
-			if readNotifs[notifID] {
-				continue
-			}
+// MarkAllAsRead marks all notifications of a user as read
+func (s *NotificationService) MarkAllAsRead(userID uuid.UUID) (int64, error) {
+	return s.notificationRepo.MarkAllAsRead(userID)
+}
+
+// DismissNotification removes a notification from the inbox
+func (s *NotificationService) DismissNotification(userID, notificationID uuid.UUID) error {
+	return s.notificationRepo.Dismiss(userID, notificationID)
+}
 
-			notifications = append(notifications, NotificationResponse{
-				ID:          notifID,
-				Type:        notifType,
-				Title:       title,
-				Message:     generateExpiringMessage(food.Name, days),
-				FoodID:      food.ID,
-				FoodName:    food.Name,
-				Quantity:    food.Quantity,
-				Unit:        food.Unit,
-				ExpiryDate:  food.ExpiryDate,
-				DaysUntilExp: &days,
-				Severity:    severity,
-				CreatedAt:   time.Now(),
-			})
+// GenerateNotifications scans every user's data and persists new notifications.
+// Each event has a dedup key so repeated runs don't create duplicates.
+func (s *NotificationService) GenerateNotifications(ctx context.Context) (int, error) {
+	created := 0
+	prefs := newPreferenceCache(s.prefRepo)
+
+	generators := []struct {
+		name string
+		fn   func(*preferenceCache) (int, error)
+	}{
+		{"expiring", s.generateExpiringNotifications},
+		{"expired", s.generateExpiredNotifications},
+		{"low_stock", s.generateLowStockNotifications},
+		{"order_ready", s.generateOrderReadyNotifications},
+		{"voucher_expiring", s.generateVoucherExpiringNotifications},
+		{"daily_digest", s.generateDigestNotifications},
+	}
+
+	for _, g := range generators {
+		if ctx.Err() != nil {
+			return created, ctx.Err()
+		}
+		count, err := g.fn(prefs)
+		if err != nil {
+			log.Printf("❌ Failed to generate %s notifications: %v", g.name, err)
+			continue
 		}
+		created += count
+	}
+
+	return created, nil
+}
+
+func (s *NotificationService) generateExpiringNotifications(prefs *preferenceCache) (int, error) {
+	foods, err := s.foodRepo.FindAllExpiringSoon(maxExpiryThresholdDays)
+	if err != nil {
+		return 0, err
 	}
 
-	// Get expired foods (critical)
-	expiredFoods, err := s.foodRepo.FindExpired(userID)
-	if err == nil {
-		for _, food := range expiredFoods {
-			// Generate unique notification ID
-			notifID := fmt.Sprintf("expired_%s", food.ID.String())
+	reasons := s.expiryReasons(foods)
 
-			// Skip if already read
-			if readNotifs[notifID] {
-				continue
+	created := 0
+	for _, food := range foods {
+		pref := prefs.get(food.UserID)
+		days := food.DaysUntilExpiry()
+		if !pref.ExpiringSoonEnabled || days > thresholdForCategory(pref, food.Category) {
+			continue
+		}
+		window, severity, title := expiryWindow(days, pref)
+
+		foodID := food.ID
+		notification := &models.Notification{
+			UserID:        food.UserID,
+			Type:          string(NotificationTypeExpiringSoon),
+			Title:         title,
+			Message:       generateExpiringMessage(food.Name, days),
+			Severity:      severity,
+			ReferenceID:   &foodID,
+			ReferenceType: "food",
+			DedupKey:      fmt.Sprintf("expiring_%s_%s_%s", window, food.ID, food.ExpiryDate.Format("20060102")),
+			FoodName:      food.Name,
+			Quantity:      food.Quantity,
+			Unit:          food.Unit,
+			ExpiryDate:    food.ExpiryDate,
+			DaysUntilExp:  &days,
+			ExpiryReason:  reasons[food.ID],
+		}
+		if ok, err := s.createNotification(notification); err != nil {
+			return created, err
+		} else if ok {
+			created++
+			if severity == "critical" {
+				s.pushService.NotifyFoodExpiry(food.UserID, food.ID, notification.Title, notification.Message)
 			}
+		}
+	}
+
+	return created, nil
+}
+
+func (s *NotificationService) generateExpiredNotifications(prefs *preferenceCache) (int, error) {
+	foods, err := s.foodRepo.FindAllExpired()
+	if err != nil {
+		return 0, err
+	}
 
-			notifications = append(notifications, NotificationResponse{
-				ID:        notifID,
-				Type:      NotificationTypeExpired,
-				Title:     "Food Expired",
-				Message:   generateExpiredMessage(food.Name),
-				FoodID:    food.ID,
-				FoodName:  food.Name,
-				Quantity:  food.Quantity,
-				Unit:      food.Unit,
-				ExpiryDate: food.ExpiryDate,
-				Severity:  "critical",
-				CreatedAt: time.Now(),
-			})
+	reasons := s.expiryReasons(foods)
+
+	created := 0
+	for _, food := range foods {
+		if !prefs.get(food.UserID).ExpiredEnabled {
+			continue
+		}
+		foodID := food.ID
+		notification := &models.Notification{
+			UserID:        food.UserID,
+			Type:          string(NotificationTypeExpired),
+			Title:         "Food Expired",
+			Message:       generateExpiredMessage(food.Name),
+			Severity:      "critical",
+			ReferenceID:   &foodID,
+			ReferenceType: "food",
+			DedupKey:      fmt.Sprintf("expired_%s_%s", food.ID, food.ExpiryDate.Format("20060102")),
+			FoodName:      food.Name,
+			Quantity:      food.Quantity,
+			Unit:          food.Unit,
+			ExpiryDate:    food.ExpiryDate,
+			ExpiryReason:  reasons[food.ID],
+		}
+		if ok, err := s.createNotification(notification); err != nil {
+			return created, err
+		} else if ok {
+			created++
+			s.pushService.NotifyFoodExpiry(food.UserID, food.ID, notification.Title, notification.Message)
 		}
 	}
 
-	// Get low stock items (info)
-	lowStockFoods, _, err := s.foodRepo.FindByUser(userID, 1, 1000)
-	if err == nil {
-		for _, food := range lowStockFoods {
-			// Consider low stock if quantity is less than 20% of initial quantity
-			if food.InitialQuantity > 0 && food.Quantity > 0 {
-				percentage := (food.Quantity / food.InitialQuantity) * 100
-				if percentage <= 20 {
-					// Generate unique notification ID
-					notifID := fmt.Sprintf("lowstock_%s", food.ID.String())
-
-					// Skip if already read
-					if readNotifs[notifID] {
-						continue
-					}
-
-					notifications = append(notifications, NotificationResponse{
-						ID:        notifID,
-						Type:      NotificationTypeLowStock,
-						Title:     "Low Stock",
-						Message:   generateLowStockMessage(food.Name, food.Quantity, food.Unit),
-						FoodID:    food.ID,
-						FoodName:  food.Name,
-						Quantity:  food.Quantity,
-						Unit:      food.Unit,
-						Severity:  "info",
-						CreatedAt: time.Now(),
-					})
-				}
-			}
+	return created, nil
+}
+
+func (s *NotificationService) generateLowStockNotifications(prefs *preferenceCache) (int, error) {
+	// Query with the loosest percentage any user configured, then filter per user
+	percentage, err := s.prefRepo.MaxLowStockPercentage()
+	if err != nil {
+		return 0, err
+	}
+	if percentage < lowStockPercentage {
+		percentage = lowStockPercentage
+	}
+
+	foods, err := s.foodRepo.FindAllLowStock(percentage)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, food := range foods {
+		pref := prefs.get(food.UserID)
+		if !pref.LowStockEnabled || food.Quantity*100 > food.InitialQuantity*pref.LowStockPercentage {
+			continue
+		}
+		foodID := food.ID
+		notification := &models.Notification{
+			UserID:        food.UserID,
+			Type:          string(NotificationTypeLowStock),
+			Title:         "Low Stock",
+			Message:       generateLowStockMessage(food.Name, food.Quantity, food.Unit),
+			Severity:      "info",
+			ReferenceID:   &foodID,
+			ReferenceType: "food",
+			// One reminder per food and initial stock level
+			DedupKey: fmt.Sprintf("lowstock_%s_%g", food.ID, food.InitialQuantity),
+			FoodName: food.Name,
+			Quantity: food.Quantity,
+			Unit:     food.Unit,
+		}
+		if ok, err := s.createNotification(notification); err != nil {
+			return created, err
+		} else if ok {
+			created++
 		}
 	}
 
-	return notifications, nil
+	return created, nil
 }
 
-// GetExpiringNotifications retrieves only UNREAD expiring soon and expired notifications
-func (s *NotificationService) GetExpiringNotifications(userID uuid.UUID) ([]NotificationResponse, error) {
-	// Get read notifications
-	readNotifs, err := s.notifReadRepo.GetReadNotifications(userID)
+func (s *NotificationService) generateOrderReadyNotifications(prefs *preferenceCache) (int, error) {
+	orders, err := s.orderRepo.GetOrdersByStatus("pending_pickup")
 	if err != nil {
-		readNotifs = make(map[string]bool) // Continue even if error
+		return 0, err
 	}
 
-	notifications := make([]NotificationResponse, 0)
-
-	// Get foods expiring within 30 days and categorize them
-	expiringSoon, err := s.foodRepo.FindExpiringSoon(userID, 30)
-	if err == nil {
-		for _, food := range expiringSoon {
-			days := food.DaysUntilExpiry()
-
-			// Determine notification type, severity, and title based on days
-			var notifType NotificationType
-			var severity string
-			var title string
-			var notifID string
-
-			// Generate unique ID based on current days remaining
-			// This ensures notification changes as expiry approaches
-			if days <= 1 {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_1day_%s_%d", food.ID.String(), days)
-				severity = "critical"
-				if days == 0 {
-					title = "Food Expiring Today!"
-				} else {
-					title = "Food Expiring Tomorrow!"
-				}
-			} else if days <= 3 {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_3days_%s_%d", food.ID.String(), days)
-				severity = "critical"
-				title = "Food Expiring in 3 Days!"
-			} else if days <= 7 {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_1week_%s_%d", food.ID.String(), days)
-				severity = "warning"
-				title = "Food Expiring This Week"
-			} else {
-				notifType = NotificationTypeExpiringSoon
-				notifID = fmt.Sprintf("expiring_1month_%s_%d", food.ID.String(), days)
-				severity = "info"
-				title = "Food Expiring This Month"
-			}
+	created := 0
+	for _, order := range orders {
+		if !prefs.get(order.UserID).OrderReadyEnabled {
+			continue
+		}
+		orderID := order.ID
+		notification := &models.Notification{
+			UserID:        order.UserID,
+			Type:          string(NotificationTypeOrderReady),
+			Title:         "Order Ready for Pickup",
+			Message:       fmt.Sprintf("Your order %s is ready for pickup at %s", order.OrderNumber, order.SupermarketName),
+			Severity:      "info",
+			ReferenceID:   &orderID,
+			ReferenceType: "order",
+			DedupKey:      fmt.Sprintf("order_ready_%s", order.ID),
+		}
+		if ok, err := s.createNotification(notification); err != nil {
+			return created, err
+		} else if ok {
+			created++
+		}
+	}
 
-			// Skip if already read
-			if readNotifs[notifID] {
-				continue
-			}
+	return created, nil
+}
 
-			notifications = append(notifications, NotificationResponse{
-				ID:          notifID,
-				Type:        notifType,
-				Title:       title,
-				Message:     generateExpiringMessage(food.Name, days),
-				FoodID:      food.ID,
-				FoodName:    food.Name,
-				Quantity:    food.Quantity,
-				Unit:        food.Unit,
-				ExpiryDate:  food.ExpiryDate,
-				DaysUntilExp: &days,
-				Severity:    severity,
-				CreatedAt:   time.Now(),
-			})
+func (s *NotificationService) generateVoucherExpiringNotifications(prefs *preferenceCache) (int, error) {
+	redemptions, err := s.voucherRepo.FindRedemptionsExpiringBefore(time.Now().AddDate(0, 0, voucherExpiringDays))
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, redemption := range redemptions {
+		if !prefs.get(redemption.UserID).VoucherExpiringEnabled {
+			continue
+		}
+		redemptionID := redemption.ID
+		notification := &models.Notification{
+			UserID:        redemption.UserID,
+			Type:          string(NotificationTypeVoucherExpiring),
+			Title:         "Voucher Expiring Soon",
+			Message:       fmt.Sprintf("Your voucher %s expires on %s", redemption.Voucher.Title, redemption.ExpiresAt.Format("02 Jan 2006")),
+			Severity:      "warning",
+			ReferenceID:   &redemptionID,
+			ReferenceType: "redemption",
+			DedupKey:      fmt.Sprintf("voucher_expiring_%s", redemption.ID),
+		}
+		if ok, err := s.createNotification(notification); err != nil {
+			return created, err
+		} else if ok {
+			created++
 		}
 	}
 
-	// Get expired foods
-	expiredFoods, err := s.foodRepo.FindExpired(userID)
-	if err == nil {
-		for _, food := range expiredFoods {
-			// Generate unique notification ID
-			notifID := fmt.Sprintf("expired_%s", food.ID.String())
+	return created, nil
+}
 
-			// Skip if already read
-			if readNotifs[notifID] {
-				continue
-			}
+// generateDigestNotifications sends one summary a day to users who enabled the digest,
+// once their local time has passed the configured digest time
+func (s *NotificationService) generateDigestNotifications(_ *preferenceCache) (int, error) {
+	prefs, err := s.prefRepo.FindDigestEnabled()
+	if err != nil {
+		return 0, err
+	}
 
-			notifications = append(notifications, NotificationResponse{
-				ID:        notifID,
-				Type:      NotificationTypeExpired,
-				Title:     "Food Expired",
-				Message:   generateExpiredMessage(food.Name),
-				FoodID:    food.ID,
-				FoodName:  food.Name,
-				Quantity:  food.Quantity,
-				Unit:      food.Unit,
-				ExpiryDate: food.ExpiryDate,
-				Severity:  "critical",
-				CreatedAt: time.Now(),
-			})
+	created := 0
+	for _, pref := range prefs {
+		loc, err := time.LoadLocation(pref.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		now := time.Now().In(loc)
+		if now.Format("15:04") < pref.DigestTime {
+			continue
+		}
+
+		expiring, err := s.foodRepo.FindExpiringSoon(pref.UserID, pref.WarningDays)
+		if err != nil {
+			return created, err
+		}
+		expired, err := s.foodRepo.FindExpired(pref.UserID)
+		if err != nil {
+			return created, err
+		}
+		if len(expiring) == 0 && len(expired) == 0 {
+			continue
+		}
+
+		notification := &models.Notification{
+			UserID:   pref.UserID,
+			Type:     string(NotificationTypeDailyDigest),
+			Title:    "Your Daily Pantry Summary",
+			Message:  generateDigestMessage(len(expiring), len(expired), pref.WarningDays),
+			Severity: "info",
+			DedupKey: fmt.Sprintf("digest_%s", now.Format("20060102")),
+		}
+		if ok, err := s.createNotification(notification); err != nil {
+			return created, err
+		} else if ok {
+			created++
 		}
 	}
 
-	return notifications, nil
+	return created, nil
 }
 
-// MarkNotificationAsRead marks a notification as read
-func (s *NotificationService) MarkNotificationAsRead(userID uuid.UUID, notificationID string) error {
-	return s.notifReadRepo.MarkAsRead(userID, notificationID)
+// expiryReasons returns why the expiry date of each food last changed, e.g. it was opened or thawed.
+// Notifications are still sent without reasons when the history can't be loaded.
+func (s *NotificationService) expiryReasons(foods []models.Food) map[uuid.UUID]string {
+	reasons := make(map[uuid.UUID]string)
+	ids := make([]uuid.UUID, len(foods))
+	for i, food := range foods {
+		ids[i] = food.ID
+	}
+
+	events, err := s.foodRepo.FindLatestEvents(ids)
+	if err != nil {
+		log.Printf("⚠️  Failed to load food history for notifications: %v", err)
+		return reasons
+	}
+	for foodID, event := range events {
+		reasons[foodID] = event.Reason
+	}
+	return reasons
+}
+
+// createNotification stores a notification unless its dedup key exists
+// and announces new ones on the user's open streams
+func (s *NotificationService) createNotification(notification *models.Notification) (bool, error) {
+	ok, err := s.notificationRepo.CreateIfNotExists(notification)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	s.eventHub.Publish(notification.UserID, EventNotificationNew, notification)
+	return true, nil
+}
+
+// expiryWindow maps days until expiry to a dedup window, severity and title
+// using the user's critical and warning thresholds
+func expiryWindow(days int, pref *models.NotificationPreference) (window, severity, title string) {
+	switch {
+	case days <= 1:
+		if days == 0 {
+			return "1day", "critical", "Food Expiring Today!"
+		}
+		return "1day", "critical", "Food Expiring Tomorrow!"
+	case days <= pref.CriticalDays:
+		return fmt.Sprintf("%ddays", pref.CriticalDays), "critical", fmt.Sprintf("Food Expiring in %d Days!", pref.CriticalDays)
+	case days <= pref.WarningDays:
+		return fmt.Sprintf("%ddays", pref.WarningDays), "warning", fmt.Sprintf("Food Expiring Within %d Days", pref.WarningDays)
+	default:
+		return "later", "info", "Food Expiring Soon"
+	}
 }
 
 // Helper functions to generate messages
@@ -302,6 +449,10 @@ func generateExpiredMessage(foodName string) string {
 	return fmt.Sprintf("%s has expired. Please check or discard it.", foodName)
 }
 
+func generateDigestMessage(expiring, expired, days int) string {
+	return fmt.Sprintf("%d item(s) expire within %d days and %d item(s) have expired", expiring, days, expired)
+}
+
 func generateLowStockMessage(foodName string, quantity float64, unit string) string {
 	return fmt.Sprintf("%s is running low. Only %.1f %s left", foodName, quantity, unit)
 }
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+)
+
+// NotificationPreferencesResponse is the preference payload returned to clients
+type NotificationPreferencesResponse struct {
+	ExpiringSoonEnabled    bool           `json:"expiring_soon_enabled"`
+	ExpiredEnabled         bool           `json:"expired_enabled"`
+	LowStockEnabled        bool           `json:"low_stock_enabled"`
+	OrderReadyEnabled      bool           `json:"order_ready_enabled"`
+	VoucherExpiringEnabled bool           `json:"voucher_expiring_enabled"`
+	DefaultThresholdDays   int            `json:"default_threshold_days"`
+	CriticalDays           int            `json:"critical_days"`
+	WarningDays            int            `json:"warning_days"`
+	CategoryThresholds     map[string]int `json:"category_thresholds"`
+	LowStockPercentage     float64        `json:"low_stock_percentage"`
+	DigestEnabled          bool           `json:"digest_enabled"`
+	DigestTime             string         `json:"digest_time"`
+	Timezone               string         `json:"timezone"`
+}
+
+// UpdateNotificationPreferencesRequest only changes the fields that are sent
+type UpdateNotificationPreferencesRequest struct {
+	ExpiringSoonEnabled    *bool          `json:"expiring_soon_enabled"`
+	ExpiredEnabled         *bool          `json:"expired_enabled"`
+	LowStockEnabled        *bool          `json:"low_stock_enabled"`
+	OrderReadyEnabled      *bool          `json:"order_ready_enabled"`
+	VoucherExpiringEnabled *bool          `json:"voucher_expiring_enabled"`
+	DefaultThresholdDays   *int           `json:"default_threshold_days"`
+	CriticalDays           *int           `json:"critical_days"`
+	WarningDays            *int           `json:"warning_days"`
+	CategoryThresholds     map[string]int `json:"category_thresholds"` // replaces all category thresholds, e.g. {"fish": 1, "canned": 14}
+	LowStockPercentage     *float64       `json:"low_stock_percentage"`
+	DigestEnabled          *bool          `json:"digest_enabled"`
+	DigestTime             *string        `json:"digest_time"` // HH:MM
+	Timezone               *string        `json:"timezone"`
+}
+
+// GetPreferences retrieves the user's notification preferences, defaults when none were saved
+func (s *NotificationService) GetPreferences(userID uuid.UUID) (*NotificationPreferencesResponse, error) {
+	pref, err := s.loadPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toPreferencesResponse(pref), nil
+}
+
+// UpdatePreferences validates and saves the user's notification preferences
+func (s *NotificationService) UpdatePreferences(userID uuid.UUID, req *UpdateNotificationPreferencesRequest) (*NotificationPreferencesResponse, error) {
+	pref, err := s.loadPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiringSoonEnabled != nil {
+		pref.ExpiringSoonEnabled = *req.ExpiringSoonEnabled
+	}
+	if req.ExpiredEnabled != nil {
+		pref.ExpiredEnabled = *req.ExpiredEnabled
+	}
+	if req.LowStockEnabled != nil {
+		pref.LowStockEnabled = *req.LowStockEnabled
+	}
+	if req.OrderReadyEnabled != nil {
+		pref.OrderReadyEnabled = *req.OrderReadyEnabled
+	}
+	if req.VoucherExpiringEnabled != nil {
+		pref.VoucherExpiringEnabled = *req.VoucherExpiringEnabled
+	}
+	if req.DefaultThresholdDays != nil {
+		pref.DefaultThresholdDays = *req.DefaultThresholdDays
+	}
+	if req.CriticalDays != nil {
+		pref.CriticalDays = *req.CriticalDays
+	}
+	if req.WarningDays != nil {
+		pref.WarningDays = *req.WarningDays
+	}
+	if req.LowStockPercentage != nil {
+		pref.LowStockPercentage = *req.LowStockPercentage
+	}
+	if req.DigestEnabled != nil {
+		pref.DigestEnabled = *req.DigestEnabled
+	}
+	if req.DigestTime != nil {
+		pref.DigestTime = *req.DigestTime
+	}
+	if req.Timezone != nil {
+		pref.Timezone = *req.Timezone
+	}
+
+	if err := validateThresholdDays("default_threshold_days", pref.DefaultThresholdDays); err != nil {
+		return nil, err
+	}
+	if err := validateThresholdDays("critical_days", pref.CriticalDays); err != nil {
+		return nil, err
+	}
+	if err := validateThresholdDays("warning_days", pref.WarningDays); err != nil {
+		return nil, err
+	}
+	if pref.CriticalDays > pref.WarningDays {
+		return nil, errors.New("critical_days must not be greater than warning_days")
+	}
+	if pref.LowStockPercentage < 0 || pref.LowStockPercentage > 100 {
+		return nil, errors.New("low_stock_percentage must be between 0 and 100")
+	}
+	digestTime, err := time.Parse("15:04", pref.DigestTime)
+	if err != nil {
+		return nil, errors.New("digest_time must be in HH:MM format")
+	}
+	pref.DigestTime = digestTime.Format("15:04")
+	if _, err := time.LoadLocation(pref.Timezone); err != nil {
+		return nil, errors.New("invalid timezone")
+	}
+
+	if req.CategoryThresholds != nil {
+		thresholds := make(map[string]int, len(req.CategoryThresholds))
+		for category, days := range req.CategoryThresholds {
+			category = thresholdKey(category)
+			if category == "" {
+				return nil, errors.New("category name is required")
+			}
+			if err := validateThresholdDays(category, days); err != nil {
+				return nil, err
+			}
+			thresholds[category] = days
+		}
+		encoded, err := json.Marshal(thresholds)
+		if err != nil {
+			return nil, err
+		}
+		pref.CategoryThresholds = string(encoded)
+	}
+
+	if err := s.prefRepo.Save(pref); err != nil {
+		return nil, err
+	}
+
+	return toPreferencesResponse(pref), nil
+}
+
+func (s *NotificationService) loadPreferences(userID uuid.UUID) (*models.NotificationPreference, error) {
+	pref, err := s.prefRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pref == nil {
+		pref = models.DefaultNotificationPreference(userID)
+	}
+	return pref, nil
+}
+
+func validateThresholdDays(field string, days int) error {
+	if days < 0 || days > maxExpiryThresholdDays {
+		return fmt.Errorf("%s must be between 0 and %d days", field, maxExpiryThresholdDays)
+	}
+	return nil
+}
+
+// preferenceCache loads each user's preferences once per generator run
+type preferenceCache struct {
+	prefRepo *repository.NotificationPreferenceRepository
+	prefs    map[uuid.UUID]*models.NotificationPreference
+}
+
+func newPreferenceCache(prefRepo *repository.NotificationPreferenceRepository) *preferenceCache {
+	return &preferenceCache{
+		prefRepo: prefRepo,
+		prefs:    make(map[uuid.UUID]*models.NotificationPreference),
+	}
+}
+
+// get returns the user's preferences, falling back to defaults when missing or unreadable
+func (c *preferenceCache) get(userID uuid.UUID) *models.NotificationPreference {
+	if pref, ok := c.prefs[userID]; ok {
+		return pref
+	}
+	pref, err := c.prefRepo.FindByUser(userID)
+	if err != nil || pref == nil {
+		pref = models.DefaultNotificationPreference(userID)
+	}
+	c.prefs[userID] = pref
+	return pref
+}
+
+// thresholdForCategory returns how many days before expiry a food of this category is notified
+func thresholdForCategory(pref *models.NotificationPreference, category string) int {
+	thresholds := parseCategoryThresholds(pref.CategoryThresholds)
+	if days, ok := thresholds[strings.ToLower(category)]; ok {
+		return days
+	}
+	return pref.DefaultThresholdDays
+}
+
+func parseCategoryThresholds(raw string) map[string]int {
+	thresholds := make(map[string]int)
+	if raw == "" {
+		return thresholds
+	}
+	var stored map[string]int
+	_ = json.Unmarshal([]byte(raw), &stored)
+	for category, days := range stored {
+		thresholds[thresholdKey(category)] = days
+	}
+	return thresholds
+}
+
+// thresholdKey is the lowercase canonical category, so "Vegetables" and "sayur" both set the Vegetable threshold
+func thresholdKey(category string) string {
+	category = strings.TrimSpace(category)
+	if canonical, ok := models.NormalizeFoodCategory(category); ok {
+		category = canonical
+	}
+	return strings.ToLower(category)
+}
+
+func toPreferencesResponse(pref *models.NotificationPreference) *NotificationPreferencesResponse {
+	return &NotificationPreferencesResponse{
+		ExpiringSoonEnabled:    pref.ExpiringSoonEnabled,
+		ExpiredEnabled:         pref.ExpiredEnabled,
+		LowStockEnabled:        pref.LowStockEnabled,
+		OrderReadyEnabled:      pref.OrderReadyEnabled,
+		VoucherExpiringEnabled: pref.VoucherExpiringEnabled,
+		DefaultThresholdDays:   pref.DefaultThresholdDays,
+		CriticalDays:           pref.CriticalDays,
+		WarningDays:            pref.WarningDays,
+		CategoryThresholds:     parseCategoryThresholds(pref.CategoryThresholds),
+		LowStockPercentage:     pref.LowStockPercentage,
+		DigestEnabled:          pref.DigestEnabled,
+		DigestTime:             pref.DigestTime,
+		Timezone:               pref.Timezone,
+	}
+}
@@ -11,20 +11,35 @@ import (
 )
 
 type OrderService struct {
-	orderRepo   *repository.OrderRepository
-	voucherRepo *repository.VoucherRepository
-	foodRepo    *repository.FoodRepository
+	orderRepo       *repository.OrderRepository
+	voucherRepo     *repository.VoucherRepository
+	foodRepo        *repository.FoodRepository
+	supermarketRepo *repository.SupermarketRepository
+	foodService     *FoodService
+	pushService     *PushService
+	expiryService   *ExpiryService
+	eventHub        *EventHub
 }
 
 func NewOrderService(
 	orderRepo *repository.OrderRepository,
 	voucherRepo *repository.VoucherRepository,
 	foodRepo *repository.FoodRepository,
+	supermarketRepo *repository.SupermarketRepository,
+	foodService *FoodService,
+	pushService *PushService,
+	expiryService *ExpiryService,
+	eventHub *EventHub,
 ) *OrderService {
 	return &OrderService{
-		orderRepo:   orderRepo,
-		voucherRepo: voucherRepo,
-		foodRepo:    foodRepo,
+		orderRepo:       orderRepo,
+		voucherRepo:     voucherRepo,
+		foodRepo:        foodRepo,
+		supermarketRepo: supermarketRepo,
+		foodService:     foodService,
+		pushService:     pushService,
+		expiryService:   expiryService,
+		eventHub:        eventHub,
 	}
 }
 
@@ -102,6 +117,11 @@ func (s *OrderService) CreateOrder(userID uuid.UUID, req CreateOrderRequest) (*m
 	}
 
 	log.Printf("✅ Order created: %s with %d items", order.OrderNumber, len(order.Items))
+
+	// Push the status change without blocking the request on retries
+	go s.pushService.NotifyOrderStatus(order)
+	s.publishStatusChanged(order)
+
 	return order, nil
 }
 
@@ -135,25 +155,46 @@ func (s *OrderService) ConfirmPickup(userID, orderID uuid.UUID) error {
 
 	// Add items to food storage
 	for _, item := range order.Items {
-		// Calculate expiry date (default 7 days for purchased items)
-		expiryDate := time.Now().AddDate(0, 0, 7)
+		// The product gives the category and its shelf life, it may have been removed since
+		category, hintDays := models.CategoryOther, 0
+		if product, err := s.supermarketRepo.GetProductByID(item.ProductID); err == nil {
+			category, _ = models.NormalizeFoodCategory(product.Category)
+			hintDays = product.ExpiryDays
+		}
+		expiryDate := s.expiryService.Predict(ExpiryInput{
+			Category: category,
+			Location: order.SupermarketName,
+			From:     time.Now(),
+			HintDays: hintDays,
+		})
 
 		food := &models.Food{
 			UserID:       userID,
 			Name:         item.ProductName,
-			Category:     "purchased", // You might want to get actual category from product
+			Category:     category,
 			Quantity:     float64(item.Quantity),
-			Unit:         item.Unit,
+			Unit:         purchasedUnit(item.Unit),
 			Location:     order.SupermarketName,
 			ExpiryDate:   &expiryDate,
 			PurchaseDate: &order.CreatedAt,
 			AddMethod:    "purchase",
+			Lots: []models.FoodLot{{
+				UserID:          userID,
+				Quantity:        float64(item.Quantity),
+				InitialQuantity: float64(item.Quantity),
+				PurchaseDate:    &order.CreatedAt,
+				ExpiryDate:      &expiryDate,
+				Source:          "order",
+				SourceID:        &order.ID,
+			}},
 		}
 
 		if err := s.foodRepo.Create(food); err != nil {
 			log.Printf("Error adding food to storage: %v", err)
 			// Continue with other items even if one fails
+			continue
 		}
+		s.eventHub.Publish(userID, EventFoodCreated, s.foodService.toFoodResponse(food))
 	}
 
 	// Update order status
@@ -175,5 +216,18 @@ func (s *OrderService) ConfirmPickup(userID, orderID uuid.UUID) error {
 	}
 
 	log.Printf("✅ Order %s picked up and %d items added to storage", order.OrderNumber, len(order.Items))
+
+	go s.pushService.NotifyOrderStatus(order)
+	s.publishStatusChanged(order)
+
 	return nil
 }
+
+// publishStatusChanged notifies the user's open streams about the order's current status
+func (s *OrderService) publishStatusChanged(order *models.Order) {
+	s.eventHub.Publish(order.UserID, EventOrderStatusChanged, map[string]interface{}{
+		"order_id":     order.ID,
+		"order_number": order.OrderNumber,
+		"status":       order.Status,
+	})
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+// Actions point rules can reward, used as the transaction source
+const (
+	ActionFoodSave     = "food_save"
+	ActionDonation     = "donation"
+	ActionJournalEntry = "journal_entry"
+)
+
+var pointActions = []string{ActionFoodSave, ActionDonation, ActionJournalEntry}
+
+// PointsContext describes the rewarded action for rule conditions
+type PointsContext struct {
+	Category   string
+	Quantity   int // units for per-unit rules, 1 when 0
+	ExpiryDate *time.Time
+}
+
+// PointsAward is the outcome of the rules for one action
+type PointsAward struct {
+	Action string
+	Points int
+	Rule   *models.PointRule // base rule, nil when no rule matched
+	Audit  string
+
+	uncapped int    // points before the daily cap
+	steps    string // audit before the daily cap
+}
+
+// applyDailyCap limits the points to what is left of the base rule's daily cap
+// after the given points earned today
+func (a *PointsAward) applyDailyCap(earned int) {
+	a.Points = a.uncapped
+	audit := a.steps
+	if cap := a.Rule.DailyCap; cap > 0 {
+		if remaining := cap - earned; a.Points > remaining {
+			if remaining < 0 {
+				remaining = 0
+			}
+			a.Points = remaining
+			audit += fmt.Sprintf(" capped at %d/day (%d earned today)", cap, earned)
+		}
+	}
+	a.Audit = fmt.Sprintf("%s = %d", audit, a.Points)
+}
+
+type PointRuleRequest struct {
+	Name           string     `json:"name" binding:"required,max=100"`
+	Action         string     `json:"action" binding:"required"`
+	Type           string     `json:"type" binding:"required,oneof=base multiplier"`
+	Points         int        `json:"points" binding:"min=0"`
+	PerUnit        bool       `json:"per_unit"`
+	DailyCap       int        `json:"daily_cap" binding:"min=0"`
+	Multiplier     float64    `json:"multiplier" binding:"min=0"`
+	Category       string     `json:"category"`
+	NearExpiryDays *int       `json:"near_expiry_days" binding:"omitempty,min=0"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	Priority       int        `json:"priority"`
+	IsActive       *bool      `json:"is_active"`
+}
+
+// CalculatePoints applies the active rules of an action: the matching base rule with
+// the highest priority, every matching multiplier, then the base rule's daily cap
+func (s *RewardService) CalculatePoints(userID uuid.UUID, action string, pctx PointsContext) (*PointsAward, error) {
+	now := time.Now()
+	rules, err := s.pointRuleRepo.FindActive(action, now)
+	if err != nil {
+		return nil, err
+	}
+
+	award := &PointsAward{Action: action}
+	for i := range rules {
+		if rules[i].Type == models.PointRuleBase && ruleMatches(&rules[i], pctx, now) {
+			award.Rule = &rules[i]
+			break
+		}
+	}
+	if award.Rule == nil {
+		award.Audit = "no matching rule"
+		return award, nil
+	}
+
+	base := award.Rule
+	units := 1
+	if base.PerUnit && pctx.Quantity > 1 {
+		units = pctx.Quantity
+	}
+
+	points := float64(base.Points * units)
+	audit := []string{fmt.Sprintf("%q %d", base.Name, base.Points)}
+	if units > 1 {
+		audit[0] += fmt.Sprintf(" x %d units", units)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Type != models.PointRuleMultiplier || !ruleMatches(rule, pctx, now) {
+			continue
+		}
+		points *= rule.Multiplier
+		audit = append(audit, fmt.Sprintf("x%g %q", rule.Multiplier, rule.Name))
+	}
+	award.uncapped = int(math.Round(points))
+	award.steps = strings.Join(audit, " ")
+
+	// The cap is checked again when the award is credited, this is only an estimate
+	earned := 0
+	if base.DailyCap > 0 {
+		earned, err = s.rewardRepo.SumEarnedSince(userID, action, startOfDay(now))
+		if err != nil {
+			return nil, err
+		}
+	}
+	award.applyDailyCap(earned)
+	return award, nil
+}
+
+// EarnAward credits a calculated award, recording the rule that produced it.
+// Under a daily cap the points earned today are counted again while the balance is locked,
+// so concurrent awards can't exceed the cap; award.Points is updated to what was credited.
+func (s *RewardService) EarnAward(userID uuid.UUID, award *PointsAward, description string, referenceID *uuid.UUID, referenceType string) error {
+	newTransaction := func() *models.PointTransaction {
+		transaction := &models.PointTransaction{
+			Amount:        award.Points,
+			Source:        award.Action,
+			Description:   fmt.Sprintf("%s (+%d points)", description, award.Points),
+			ReferenceID:   referenceID,
+			ReferenceType: referenceType,
+			RuleAudit:     award.Audit,
+		}
+		if award.Rule != nil {
+			transaction.PointRuleID = &award.Rule.ID
+		}
+		return transaction
+	}
+
+	if award.Rule == nil || award.Rule.DailyCap <= 0 {
+		return s.earn(userID, newTransaction())
+	}
+	return s.rewardRepo.EarnPointsCapped(userID, award.Action, startOfDay(time.Now()), func(earned int) *models.PointTransaction {
+		award.applyDailyCap(earned)
+		return s.withExpiry(newTransaction())
+	})
+}
+
+// AwardAction calculates and credits the points for an action
+func (s *RewardService) AwardAction(userID uuid.UUID, action string, pctx PointsContext, description string, referenceID *uuid.UUID, referenceType string) (*PointsAward, error) {
+	award, err := s.CalculatePoints(userID, action, pctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.EarnAward(userID, award, description, referenceID, referenceType); err != nil {
+		return nil, err
+	}
+	return award, nil
+}
+
+// ruleMatches checks the category and near-expiry conditions, the campaign window is filtered by the query
+func ruleMatches(rule *models.PointRule, pctx PointsContext, now time.Time) bool {
+	if rule.Category != "" && !strings.EqualFold(rule.Category, pctx.Category) {
+		return false
+	}
+	if rule.NearExpiryDays != nil {
+		if pctx.ExpiryDate == nil || pctx.ExpiryDate.Before(now) {
+			return false
+		}
+		if pctx.ExpiryDate.Sub(now) > time.Duration(*rule.NearExpiryDays)*24*time.Hour {
+			return false
+		}
+	}
+	return true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// GetPointRules lists point rules, optionally of one action
+func (s *RewardService) GetPointRules(action string) ([]models.PointRule, error) {
+	return s.pointRuleRepo.FindAll(action)
+}
+
+// CreatePointRule creates a point rule
+func (s *RewardService) CreatePointRule(req *PointRuleRequest) (*models.PointRule, error) {
+	rule := &models.PointRule{}
+	if err := applyPointRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.pointRuleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdatePointRule replaces a point rule's settings
+func (s *RewardService) UpdatePointRule(id uuid.UUID, req *PointRuleRequest) (*models.PointRule, error) {
+	rule, err := s.pointRuleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPointRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.pointRuleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeletePointRule deletes a point rule, transactions keep its ID and audit text
+func (s *RewardService) DeletePointRule(id uuid.UUID) error {
+	if _, err := s.pointRuleRepo.FindByID(id); err != nil {
+		return err
+	}
+	return s.pointRuleRepo.Delete(id)
+}
+
+func applyPointRuleRequest(rule *models.PointRule, req *PointRuleRequest) error {
+	if !containsString(pointActions, req.Action) {
+		return fmt.Errorf("invalid action, use one of: %s", strings.Join(pointActions, ", "))
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	multiplier := req.Multiplier
+	if req.Type == models.PointRuleMultiplier {
+		if multiplier <= 0 {
+			return errors.New("multiplier rules need a multiplier above 0")
+		}
+	} else {
+		multiplier = 1
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Action = req.Action
+	rule.Type = req.Type
+	rule.Points = req.Points
+	rule.PerUnit = req.PerUnit
+	rule.DailyCap = req.DailyCap
+	rule.Multiplier = multiplier
+	rule.Category = ""
+	if category := strings.TrimSpace(req.Category); category != "" {
+		canonical, ok := models.NormalizeFoodCategory(category)
+		if !ok {
+			return fmt.Errorf("invalid category, use one of: %s", strings.Join(models.FoodCategories, ", "))
+		}
+		rule.Category = canonical
+	}
+	rule.NearExpiryDays = req.NearExpiryDays
+	rule.StartsAt = req.StartsAt
+	rule.EndsAt = req.EndsAt
+	rule.Priority = req.Priority
+	rule.IsActive = true
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+func TestApplyDailyCap(t *testing.T) {
+	tests := []struct {
+		name      string
+		cap       int
+		uncapped  int
+		earned    int
+		want      int
+		wantAudit string
+	}{
+		{name: "no cap", cap: 0, uncapped: 30, earned: 100, want: 30, wantAudit: `"Save" 10 x 3 units = 30`},
+		{name: "under the cap", cap: 50, uncapped: 30, earned: 10, want: 30, wantAudit: `"Save" 10 x 3 units = 30`},
+		{name: "reaches the cap", cap: 50, uncapped: 30, earned: 20, want: 30, wantAudit: `"Save" 10 x 3 units = 30`},
+		{name: "trimmed to the cap", cap: 50, uncapped: 30, earned: 40, want: 10, wantAudit: `"Save" 10 x 3 units capped at 50/day (40 earned today) = 10`},
+		{name: "cap used up", cap: 50, uncapped: 30, earned: 50, want: 0, wantAudit: `"Save" 10 x 3 units capped at 50/day (50 earned today) = 0`},
+		{name: "cap lowered below earned", cap: 20, uncapped: 30, earned: 50, want: 0, wantAudit: `"Save" 10 x 3 units capped at 20/day (50 earned today) = 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			award := &PointsAward{
+				Rule:     &models.PointRule{DailyCap: tt.cap},
+				uncapped: tt.uncapped,
+				steps:    `"Save" 10 x 3 units`,
+			}
+
+			// Applied twice, as the estimate and again when credited
+			award.applyDailyCap(0)
+			award.applyDailyCap(tt.earned)
+
+			if award.Points != tt.want {
+				t.Errorf("points = %d, want %d", award.Points, tt.want)
+			}
+			if award.Audit != tt.wantAudit {
+				t.Errorf("audit = %q, want %q", award.Audit, tt.wantAudit)
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+)
+
+// PushMessage is a single push notification addressed to one device token
+type PushMessage struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushResult reports the outcome for a single token
+type PushResult struct {
+	Token string
+	// Unregistered is true when the provider says the token is no longer valid
+	Unregistered bool
+	Err          error
+}
+
+// Notifier delivers push messages to a push provider
+type Notifier interface {
+	Send(messages []PushMessage) ([]PushResult, error)
+}
+
+// ErrPushRetryable marks provider errors that are worth retrying (network, 429, 5xx)
+var ErrPushRetryable = errors.New("push provider temporarily unavailable")
+
+// ExpoNotifier sends messages through the Expo push service, which forwards them to FCM and APNs
+type ExpoNotifier struct {
+	endpoint    string
+	accessToken string
+	client      *http.Client
+}
+
+func NewExpoNotifier(endpoint, accessToken string) *ExpoNotifier {
+	return &ExpoNotifier{
+		endpoint:    endpoint,
+		accessToken: accessToken,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type expoPushMessage struct {
+	To       string            `json:"to"`
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Data     map[string]string `json:"data,omitempty"`
+	Sound    string            `json:"sound"`
+	Priority string            `json:"priority"`
+}
+
+type expoPushResponse struct {
+	Data []struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details struct {
+			Error string `json:"error"`
+		} `json:"details"`
+	} `json:"data"`
+}
+
+// Send posts all messages in a single request to the Expo push endpoint
+func (n *ExpoNotifier) Send(messages []PushMessage) ([]PushResult, error) {
+	payload := make([]expoPushMessage, len(messages))
+	for i, msg := range messages {
+		payload[i] = expoPushMessage{
+			To:       msg.Token,
+			Title:    msg.Title,
+			Body:     msg.Body,
+			Data:     msg.Data,
+			Sound:    "default",
+			Priority: "high",
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if n.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+n.accessToken)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPushRetryable, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPushRetryable, err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("%w: status %d", ErrPushRetryable, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("push provider returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var expoResp expoPushResponse
+	if err := json.Unmarshal(respBody, &expoResp); err != nil {
+		return nil, fmt.Errorf("failed to parse push response: %w", err)
+	}
+
+	results := make([]PushResult, len(messages))
+	for i, msg := range messages {
+		results[i] = PushResult{Token: msg.Token}
+		if i >= len(expoResp.Data) {
+			results[i].Err = errors.New("missing push ticket")
+			continue
+		}
+		ticket := expoResp.Data[i]
+		if ticket.Status != "ok" {
+			results[i].Err = errors.New(ticket.Message)
+			results[i].Unregistered = ticket.Details.Error == "DeviceNotRegistered"
+		}
+	}
+
+	return results, nil
+}
+
+// LogNotifier only logs messages instead of contacting a provider, for local development
+type LogNotifier struct{}
+
+// Send logs the messages and reports every one as delivered
+func (LogNotifier) Send(messages []PushMessage) ([]PushResult, error) {
+	results := make([]PushResult, len(messages))
+	for i, msg := range messages {
+		log.Printf("📲 [log push] to=%s title=%q", msg.Token, msg.Title)
+		results[i] = PushResult{Token: msg.Token}
+	}
+	return results, nil
+}
+
+// NewNotifier builds the notifier selected in config
+func NewNotifier(cfg *config.PushConfig) Notifier {
+	switch cfg.Provider {
+	case "expo":
+		return NewExpoNotifier(cfg.Endpoint, cfg.AccessToken)
+	default:
+		return LogNotifier{}
+	}
+}
+
+type SetQuietHoursRequest struct {
+	Enabled   bool   `json:"enabled"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+	Timezone  string `json:"timezone"`
+}
+
+type PushService struct {
+	deviceRepo *repository.DeviceRepository
+	notifier   Notifier
+	maxRetries int
+	retryDelay time.Duration
+}
+
+func NewPushService(deviceRepo *repository.DeviceRepository, notifier Notifier, cfg *config.PushConfig) *PushService {
+	maxRetries := cfg.MaxRetries
+	if maxRetries < 1 {
+		maxRetries = 3
+	}
+	return &PushService{
+		deviceRepo: deviceRepo,
+		notifier:   notifier,
+		maxRetries: maxRetries,
+		retryDelay: time.Second,
+	}
+}
+
+// RegisterDevice registers or refreshes a device token for a user
+func (s *PushService) RegisterDevice(userID uuid.UUID, platform, token string) (*models.Device, error) {
+	device := &models.Device{
+		UserID:   userID,
+		Platform: platform,
+		Token:    token,
+	}
+	if err := s.deviceRepo.Upsert(device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// GetDevices lists devices registered by a user
+func (s *PushService) GetDevices(userID uuid.UUID) ([]models.Device, error) {
+	return s.deviceRepo.FindByUser(userID)
+}
+
+// UnregisterDevice removes a device of a user
+func (s *PushService) UnregisterDevice(userID, deviceID uuid.UUID) error {
+	return s.deviceRepo.Delete(userID, deviceID)
+}
+
+// GetQuietHours returns the user's quiet hours, with defaults when not configured
+func (s *PushService) GetQuietHours(userID uuid.UUID) (*models.QuietHours, error) {
+	quietHours, err := s.deviceRepo.GetQuietHours(userID)
+	if err != nil {
+		return nil, err
+	}
+	if quietHours == nil {
+		quietHours = &models.QuietHours{
+			UserID:    userID,
+			Enabled:   false,
+			StartTime: "22:00",
+			EndTime:   "07:00",
+			Timezone:  "Asia/Jakarta",
+		}
+	}
+	return quietHours, nil
+}
+
+// SetQuietHours stores the user's quiet hours
+func (s *PushService) SetQuietHours(userID uuid.UUID, req *SetQuietHoursRequest) (*models.QuietHours, error) {
+	if _, err := time.Parse("15:04", req.StartTime); err != nil {
+		return nil, errors.New("invalid start_time, expected HH:MM")
+	}
+	if _, err := time.Parse("15:04", req.EndTime); err != nil {
+		return nil, errors.New("invalid end_time, expected HH:MM")
+	}
+	if req.Timezone == "" {
+		req.Timezone = "Asia/Jakarta"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return nil, errors.New("invalid timezone")
+	}
+
+	quietHours := &models.QuietHours{
+		UserID:    userID,
+		Enabled:   req.Enabled,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Timezone:  req.Timezone,
+	}
+	if err := s.deviceRepo.SaveQuietHours(quietHours); err != nil {
+		return nil, err
+	}
+	return s.GetQuietHours(userID)
+}
+
+// NotifyFoodExpiry pushes a critical expiry event; a food is pushed about at most once per day
+func (s *PushService) NotifyFoodExpiry(userID, foodID uuid.UUID, title, body string) {
+	dedupKey := fmt.Sprintf("food_%s_%s", foodID, time.Now().Format("20060102"))
+	s.Push(userID, dedupKey, title, body, map[string]string{
+		"type":    "food_expiry",
+		"food_id": foodID.String(),
+	})
+}
+
+// NotifyOrderStatus pushes an order status change; each status is pushed once per order
+func (s *PushService) NotifyOrderStatus(order *models.Order) {
+	var title, body string
+	switch order.Status {
+	case "pending_pickup":
+		title = "Order Ready for Pickup"
+		body = fmt.Sprintf("Your order %s is ready for pickup at %s", order.OrderNumber, order.SupermarketName)
+	case "completed":
+		title = "Order Picked Up"
+		body = fmt.Sprintf("Order %s has been picked up and added to your storage", order.OrderNumber)
+	case "cancelled":
+		title = "Order Cancelled"
+		body = fmt.Sprintf("Order %s has been cancelled", order.OrderNumber)
+	default:
+		return
+	}
+
+	dedupKey := fmt.Sprintf("order_%s_%s", order.ID, order.Status)
+	s.Push(order.UserID, dedupKey, title, body, map[string]string{
+		"type":     "order_status",
+		"order_id": order.ID.String(),
+		"status":   order.Status,
+	})
+}
+
+// Push records a delivery for the dedup key and sends it unless it was sent before.
+// It tries once, failures and deliveries deferred by the user's quiet hours are retried
+// in the background by RetryPending.
+func (s *PushService) Push(userID uuid.UUID, dedupKey, title, body string, data map[string]string) {
+	dataJSON, _ := json.Marshal(data)
+	delivery := &models.PushDelivery{
+		UserID:   userID,
+		DedupKey: dedupKey,
+		Title:    title,
+		Body:     body,
+		Data:     string(dataJSON),
+		Status:   "pending",
+	}
+
+	created, err := s.deviceRepo.CreateDelivery(delivery)
+	if err != nil {
+		log.Printf("❌ Failed to record push delivery: %v", err)
+		return
+	}
+	if !created {
+		return
+	}
+
+	s.deliver(context.Background(), delivery, 1)
+}
+
+// RetryPending resends deferred deliveries and failed ones that still have attempts left.
+// It stops when ctx is cancelled, e.g. on shutdown.
+func (s *PushService) RetryPending(ctx context.Context) {
+	// Pushes older than a day are no longer relevant
+	deliveries, err := s.deviceRepo.FindRetryableDeliveries(s.maxRetries, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		log.Printf("❌ Failed to load pending push deliveries: %v", err)
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		s.deliver(ctx, &deliveries[i], s.maxRetries)
+	}
+}
+
+// deliver sends a delivery to the user's devices, trying at most tries times
+func (s *PushService) deliver(ctx context.Context, delivery *models.PushDelivery, tries int) {
+	quietHours, err := s.deviceRepo.GetQuietHours(delivery.UserID)
+	if err == nil && quietHours.IsActiveAt(time.Now()) {
+		delivery.Status = "deferred"
+		s.saveDelivery(delivery)
+		return
+	}
+
+	devices, err := s.deviceRepo.FindByUser(delivery.UserID)
+	if err != nil {
+		delivery.Status = "failed"
+		delivery.LastError = err.Error()
+		s.saveDelivery(delivery)
+		return
+	}
+	if len(devices) == 0 {
+		// Nothing to send to; keep the record so the event is not pushed later
+		delivery.Status = "sent"
+		delivery.LastError = "no registered devices"
+		s.saveDelivery(delivery)
+		return
+	}
+
+	var data map[string]string
+	_ = json.Unmarshal([]byte(delivery.Data), &data)
+
+	messages := make([]PushMessage, len(devices))
+	for i, device := range devices {
+		messages[i] = PushMessage{
+			Token: device.Token,
+			Title: delivery.Title,
+			Body:  delivery.Body,
+			Data:  data,
+		}
+	}
+
+	results, err := s.sendWithRetry(ctx, delivery, messages, tries)
+	if err != nil {
+		delivery.Status = failureStatus(err)
+		delivery.LastError = err.Error()
+		s.saveDelivery(delivery)
+		log.Printf("❌ Push %s failed after %d attempts: %v", delivery.DedupKey, delivery.Attempts, err)
+		return
+	}
+
+	for _, result := range results {
+		if result.Unregistered {
+			if err := s.deviceRepo.DeleteByToken(result.Token); err != nil {
+				log.Printf("❌ Failed to remove unregistered device: %v", err)
+			}
+		}
+	}
+
+	now := time.Now()
+	delivery.Status = "sent"
+	delivery.LastError = ""
+	delivery.SentAt = &now
+	s.saveDelivery(delivery)
+}
+
+// sendWithRetry tries up to tries times within the delivery's remaining attempts, backing off
+// exponentially after retryable provider errors. Waiting stops when ctx is cancelled.
+func (s *PushService) sendWithRetry(ctx context.Context, delivery *models.PushDelivery, messages []PushMessage, tries int) ([]PushResult, error) {
+	delay := s.retryDelay
+	lastErr := errors.New("no push attempts left")
+
+	for ; tries > 0 && delivery.Attempts < s.maxRetries; tries-- {
+		delivery.Attempts++
+
+		results, err := s.notifier.Send(messages)
+		if err == nil {
+			return results, nil
+		}
+		lastErr = err
+		if !errors.Is(err, ErrPushRetryable) {
+			break
+		}
+
+		if tries > 1 && delivery.Attempts < s.maxRetries {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, lastErr
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+	}
+
+	return nil, lastErr
+}
+
+// failureStatus is "failed" for errors worth retrying later and "rejected" for permanent ones,
+// e.g. a malformed payload, which the retry worker leaves alone
+func failureStatus(err error) string {
+	if errors.Is(err, ErrPushRetryable) {
+		return "failed"
+	}
+	return "rejected"
+}
+
+func (s *PushService) saveDelivery(delivery *models.PushDelivery) {
+	if err := s.deviceRepo.UpdateDelivery(delivery); err != nil {
+		log.Printf("❌ Failed to update push delivery: %v", err)
+	}
+}
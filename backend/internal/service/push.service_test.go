@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+// fakeNotifier returns the scripted errors in order, then succeeds
+type fakeNotifier struct {
+	errs  []error
+	calls int
+}
+
+func (n *fakeNotifier) Send(messages []PushMessage) ([]PushResult, error) {
+	n.calls++
+	if n.calls <= len(n.errs) {
+		return nil, n.errs[n.calls-1]
+	}
+	results := make([]PushResult, len(messages))
+	for i, msg := range messages {
+		results[i] = PushResult{Token: msg.Token}
+	}
+	return results, nil
+}
+
+func TestSendWithRetry(t *testing.T) {
+	retryable := fmt.Errorf("%w: status 503", ErrPushRetryable)
+	permanent := errors.New("push provider returned status 400")
+
+	tests := []struct {
+		name         string
+		errs         []error
+		tries        int
+		prevAttempts int
+		wantCalls    int
+		wantAttempts int
+		wantErr      bool
+		wantStatus   string
+	}{
+		{name: "first attempt succeeds", tries: 3, wantCalls: 1, wantAttempts: 1},
+		{name: "retryable errors then success", errs: []error{retryable, retryable}, tries: 3, wantCalls: 3, wantAttempts: 3},
+		{name: "retryable errors use up the attempts", errs: []error{retryable, retryable, retryable}, tries: 3, wantCalls: 3, wantAttempts: 3, wantErr: true, wantStatus: "failed"},
+		{name: "permanent error is not retried", errs: []error{permanent}, tries: 3, wantCalls: 1, wantAttempts: 1, wantErr: true, wantStatus: "rejected"},
+		{name: "single try leaves the retry to the worker", errs: []error{retryable}, tries: 1, wantCalls: 1, wantAttempts: 1, wantErr: true, wantStatus: "failed"},
+		{name: "attempts from earlier runs count", errs: []error{retryable, retryable}, tries: 3, prevAttempts: 2, wantCalls: 1, wantAttempts: 3, wantErr: true, wantStatus: "failed"},
+		{name: "no attempts left", tries: 3, prevAttempts: 3, wantCalls: 0, wantAttempts: 3, wantErr: true, wantStatus: "rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{errs: tt.errs}
+			s := &PushService{notifier: notifier, maxRetries: 3, retryDelay: time.Millisecond}
+			delivery := &models.PushDelivery{Attempts: tt.prevAttempts}
+
+			results, err := s.sendWithRetry(context.Background(), delivery, []PushMessage{{Token: "token"}}, tt.tries)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(results) != 1 {
+				t.Errorf("got %d results, want 1", len(results))
+			}
+			if notifier.calls != tt.wantCalls {
+				t.Errorf("notifier called %d times, want %d", notifier.calls, tt.wantCalls)
+			}
+			if delivery.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", delivery.Attempts, tt.wantAttempts)
+			}
+			// The worker only picks up "failed" deliveries again
+			if err != nil && failureStatus(err) != tt.wantStatus {
+				t.Errorf("status = %q, want %q", failureStatus(err), tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSendWithRetryStopsWaitingWhenCancelled(t *testing.T) {
+	retryable := fmt.Errorf("%w: timeout", ErrPushRetryable)
+	notifier := &fakeNotifier{errs: []error{retryable, retryable, retryable}}
+	s := &PushService{notifier: notifier, maxRetries: 3, retryDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	_, err := s.sendWithRetry(ctx, &models.PushDelivery{}, []PushMessage{{Token: "token"}}, 3)
+	if !errors.Is(err, ErrPushRetryable) {
+		t.Fatalf("err = %v, want retryable error", err)
+	}
+	if notifier.calls != 1 {
+		t.Errorf("notifier called %d times, want 1", notifier.calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v after cancellation", elapsed)
+	}
+}
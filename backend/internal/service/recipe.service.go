@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -9,47 +10,51 @@ import (
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/config"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/prompts"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
 )
 
 type RecipeResponse struct {
-	ID           uuid.UUID              `json:"id"`
-	Title        string                 `json:"title"`
-	Description  string                 `json:"description"`
-	ImageURL     *string                `json:"image_url"`
-	PrepTime     int                    `json:"prep_time"`
-	CookTime     int                    `json:"cook_time"`
-	Servings     int                    `json:"servings"`
-	Difficulty   string                 `json:"difficulty"`
-	Category     string                 `json:"category"`
-	Cuisine      *string                `json:"cuisine"`
-	Ingredients  map[string]interface{} `json:"ingredients"`
-	Instructions string                 `json:"instructions"`
-	Calories     *float64               `json:"calories"`
-	Protein      *float64               `json:"protein"`
-	Carbs        *float64               `json:"carbs"`
-	Fat          *float64               `json:"fat"`
-	IsHalal      bool                   `json:"is_halal"`
-	IsVegetarian bool                   `json:"is_vegetarian"`
-	IsVegan      bool                   `json:"is_vegan"`
-	ExternalID   *string                `json:"external_id"`
-	Source       *string                `json:"source"`
-	CreatedAt    time.Time              `json:"created_at"`
+	ID            uuid.UUID              `json:"id"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	ImageURL      *string                `json:"image_url"`
+	PrepTime      int                    `json:"prep_time"`
+	CookTime      int                    `json:"cook_time"`
+	Servings      int                    `json:"servings"`
+	Difficulty    string                 `json:"difficulty"`
+	Category      string                 `json:"category"`
+	Cuisine       *string                `json:"cuisine"`
+	Ingredients   map[string]interface{} `json:"ingredients"`
+	Instructions  string                 `json:"instructions"`
+	Calories      *float64               `json:"calories"`
+	Protein       *float64               `json:"protein"`
+	Carbs         *float64               `json:"carbs"`
+	Fat           *float64               `json:"fat"`
+	IsHalal       bool                   `json:"is_halal"`
+	IsVegetarian  bool                   `json:"is_vegetarian"`
+	IsVegan       bool                   `json:"is_vegan"`
+	ExternalID    *string                `json:"external_id"`
+	Source        *string                `json:"source"`
+	PromptVersion *string                `json:"prompt_version,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
 }
 
 type RecipeService struct {
 	recipeRepo    *repository.RecipeRepository
 	foodRepo      *repository.FoodRepository
+	userRepo      *repository.UserRepository
 	geminiService *GeminiService
 	yummyService  *YummyService
 	config        *config.Config
 }
 
-func NewRecipeService(recipeRepo *repository.RecipeRepository, foodRepo *repository.FoodRepository, yummyService *YummyService, cfg *config.Config) *RecipeService {
+func NewRecipeService(recipeRepo *repository.RecipeRepository, foodRepo *repository.FoodRepository, userRepo *repository.UserRepository, geminiService *GeminiService, yummyService *YummyService, cfg *config.Config) *RecipeService {
 	return &RecipeService{
 		recipeRepo:    recipeRepo,
 		foodRepo:      foodRepo,
-		geminiService: NewGeminiService(cfg),
+		userRepo:      userRepo,
+		geminiService: geminiService,
 		yummyService:  yummyService,
 		config:        cfg,
 	}
@@ -125,8 +130,10 @@ func (s *RecipeService) GetRecipesByDietary(isHalal, isVegetarian, isVegan *bool
 	return responses, total, nil
 }
 
-// GetRecommendedRecipes recommends recipes based on available ingredients using Gemini AI
+// GetRecommendedRecipes recommends recipes based on available ingredients using Gemini AI.
+// Sample recipes returned while Gemini is down have source "mock" and are not saved.
 func (s *RecipeService) GetRecommendedRecipes(
+	ctx context.Context,
 	userID uuid.UUID,
 	isHalal, isVegetarian, isVegan *bool,
 	maxPrepTime int,
@@ -160,8 +167,15 @@ func (s *RecipeService) GetRecommendedRecipes(
 		difficulty = "Easy"
 	}
 
-	// Get AI-generated recipes from Gemini
-	geminiRecipes, err := s.geminiService.GenerateRecipeRecommendations(
+	// Get AI-generated recipes from Gemini, written in the user's language
+	locale := prompts.DefaultLocale
+	if user, err := s.userRepo.FindByID(userID); err == nil {
+		locale = user.Locale
+	}
+
+	geminiRecipes, source, err := s.geminiService.GenerateRecipeRecommendations(
+		ctx,
+		locale,
 		availableIngredients,
 		dietaryPreferences,
 		maxPrepTime,
@@ -170,6 +184,7 @@ func (s *RecipeService) GetRecommendedRecipes(
 	)
 	if err != nil {
 		// Fallback to database recipes
+		logFallback("recipe recommendations", AISourceDatabase, err)
 		return s.GetAllRecipes(page, limit)
 	}
 
@@ -182,6 +197,27 @@ func (s *RecipeService) GetRecommendedRecipes(
 		// Create unique external ID based on title (to avoid duplicates)
 		externalID := fmt.Sprintf("gemini-%s", strings.ToLower(strings.ReplaceAll(geminiRecipe.Title, " ", "-")))
 
+		if source == AISourceMock {
+			responses = append(responses, *s.toRecipeResponse(&models.Recipe{
+				ID:           uuid.New(),
+				Title:        geminiRecipe.Title,
+				Description:  geminiRecipe.Description,
+				PrepTime:     geminiRecipe.PrepTime,
+				CookTime:     geminiRecipe.CookTime,
+				Servings:     geminiRecipe.Servings,
+				Difficulty:   geminiRecipe.Difficulty,
+				Category:     geminiRecipe.Category,
+				Cuisine:      geminiRecipe.Cuisine,
+				Ingredients:  string(ingredientsJSON),
+				Instructions: joinInstructions(geminiRecipe.Instructions),
+				IsHalal:      geminiRecipe.IsHalal,
+				IsVegetarian: geminiRecipe.IsVegetarian,
+				IsVegan:      geminiRecipe.IsVegan,
+				Source:       AISourceMock,
+			}))
+			continue
+		}
+
 		// Check if recipe already exists
 		existingRecipe, _ := s.recipeRepo.FindByExternalID(externalID)
 		if existingRecipe != nil {
@@ -192,27 +228,28 @@ func (s *RecipeService) GetRecommendedRecipes(
 
 		// Save to database for caching
 		recipe := &models.Recipe{
-			ID:           uuid.New(),
-			Title:        geminiRecipe.Title,
-			Description:  geminiRecipe.Description,
-			ImageURL:     "",
-			PrepTime:     geminiRecipe.PrepTime,
-			CookTime:     geminiRecipe.CookTime,
-			Servings:     geminiRecipe.Servings,
-			Difficulty:   geminiRecipe.Difficulty,
-			Category:     geminiRecipe.Category,
-			Cuisine:      geminiRecipe.Cuisine,
-			Ingredients:  string(ingredientsJSON),
-			Instructions: joinInstructions(geminiRecipe.Instructions),
-			Calories:     geminiRecipe.Calories,
-			Protein:      geminiRecipe.Protein,
-			Carbs:        geminiRecipe.Carbs,
-			Fat:          geminiRecipe.Fat,
-			IsHalal:      geminiRecipe.IsHalal,
-			IsVegetarian: geminiRecipe.IsVegetarian,
-			IsVegan:      geminiRecipe.IsVegan,
-			ExternalID:   externalID,
-			Source:       "gemini",
+			ID:            uuid.New(),
+			Title:         geminiRecipe.Title,
+			Description:   geminiRecipe.Description,
+			ImageURL:      "",
+			PrepTime:      geminiRecipe.PrepTime,
+			CookTime:      geminiRecipe.CookTime,
+			Servings:      geminiRecipe.Servings,
+			Difficulty:    geminiRecipe.Difficulty,
+			Category:      geminiRecipe.Category,
+			Cuisine:       geminiRecipe.Cuisine,
+			Ingredients:   string(ingredientsJSON),
+			Instructions:  joinInstructions(geminiRecipe.Instructions),
+			Calories:      geminiRecipe.Calories,
+			Protein:       geminiRecipe.Protein,
+			Carbs:         geminiRecipe.Carbs,
+			Fat:           geminiRecipe.Fat,
+			IsHalal:       geminiRecipe.IsHalal,
+			IsVegetarian:  geminiRecipe.IsVegetarian,
+			IsVegan:       geminiRecipe.IsVegan,
+			ExternalID:    externalID,
+			Source:        "gemini",
+			PromptVersion: geminiRecipe.PromptVersion,
 		}
 
 		// Try to save (ignore errors for now)
@@ -235,7 +272,7 @@ func joinInstructions(instructions []string) string {
 
 // toRecipeResponse converts Recipe model to RecipeResponse DTO
 func (s *RecipeService) toRecipeResponse(recipe *models.Recipe) *RecipeResponse {
-	var imageURL, cuisine, externalID, source *string
+	var imageURL, cuisine, externalID, source, promptVersion *string
 	var calories, protein, carbs, fat *float64
 
 	if recipe.ImageURL != "" {
@@ -250,6 +287,9 @@ func (s *RecipeService) toRecipeResponse(recipe *models.Recipe) *RecipeResponse
 	if recipe.Source != "" {
 		source = &recipe.Source
 	}
+	if recipe.PromptVersion != "" {
+		promptVersion = &recipe.PromptVersion
+	}
 
 	if recipe.Calories > 0 {
 		calories = &recipe.Calories
@@ -269,28 +309,29 @@ func (s *RecipeService) toRecipeResponse(recipe *models.Recipe) *RecipeResponse
 	ingredients := make(map[string]interface{})
 
 	return &RecipeResponse{
-		ID:           recipe.ID,
-		Title:        recipe.Title,
-		Description:  recipe.Description,
-		ImageURL:     imageURL,
-		PrepTime:     recipe.PrepTime,
-		CookTime:     recipe.CookTime,
-		Servings:     recipe.Servings,
-		Difficulty:   recipe.Difficulty,
-		Category:     recipe.Category,
-		Cuisine:      cuisine,
-		Ingredients:  ingredients,
-		Instructions: recipe.Instructions,
-		Calories:     calories,
-		Protein:      protein,
-		Carbs:        carbs,
-		Fat:          fat,
-		IsHalal:      recipe.IsHalal,
-		IsVegetarian: recipe.IsVegetarian,
-		IsVegan:      recipe.IsVegan,
-		ExternalID:   externalID,
-		Source:       source,
-		CreatedAt:    recipe.CreatedAt,
+		ID:            recipe.ID,
+		Title:         recipe.Title,
+		Description:   recipe.Description,
+		ImageURL:      imageURL,
+		PrepTime:      recipe.PrepTime,
+		CookTime:      recipe.CookTime,
+		Servings:      recipe.Servings,
+		Difficulty:    recipe.Difficulty,
+		Category:      recipe.Category,
+		Cuisine:       cuisine,
+		Ingredients:   ingredients,
+		Instructions:  recipe.Instructions,
+		Calories:      calories,
+		Protein:       protein,
+		Carbs:         carbs,
+		Fat:           fat,
+		IsHalal:       recipe.IsHalal,
+		IsVegetarian:  recipe.IsVegetarian,
+		IsVegan:       recipe.IsVegan,
+		ExternalID:    externalID,
+		Source:        source,
+		PromptVersion: promptVersion,
+		CreatedAt:     recipe.CreatedAt,
 	}
 }
 
@@ -302,17 +343,17 @@ func (s *RecipeService) GetRecommendedRecipesFromYummy(userID uuid.UUID, limit i
 		return nil, fmt.Errorf("failed to get user foods: %w", err)
 	}
 
-	// Extract food names for matching
-	foodNames := make([]string, 0)
+	// Foods in stock with their quantities for matching
+	pantry := make([]PantryItem, 0)
 	for _, food := range foods {
 		if food.Quantity > 0 { // Only include foods in stock
-			foodNames = append(foodNames, food.Name)
+			pantry = append(pantry, PantryItem{Name: food.Name, Quantity: food.Quantity, Unit: food.Unit})
 		}
 	}
 
 	// Use YummyService's optimized ingredient matching
 	// This will return top 5 recipes based on best match or random if no match
-	recipes, err := s.yummyService.FetchRecipesWithIngredientMatch(5, foodNames)
+	recipes, err := s.yummyService.FetchRecipesWithIngredientMatch(5, pantry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch recipes from Yummy: %w", err)
 	}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+)
+
+// ImportRecipeRequest is one recipe in an import file
+type ImportRecipeRequest struct {
+	ExternalID   string   `json:"external_id"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	ImageURL     string   `json:"image_url"`
+	PrepTime     int      `json:"prep_time"`
+	CookTime     int      `json:"cook_time"`
+	Servings     int      `json:"servings"`
+	Difficulty   string   `json:"difficulty"`
+	Category     string   `json:"category"`
+	Cuisine      string   `json:"cuisine"`
+	Ingredients  []string `json:"ingredients"`
+	Instructions []string `json:"instructions"`
+	Calories     float64  `json:"calories"`
+	Protein      float64  `json:"protein"`
+	Carbs        float64  `json:"carbs"`
+	Fat          float64  `json:"fat"`
+	IsHalal      bool     `json:"is_halal"`
+	IsVegetarian bool     `json:"is_vegetarian"`
+	IsVegan      bool     `json:"is_vegan"`
+	Source       string   `json:"source"`
+	SourceURL    string   `json:"source_url"`
+}
+
+// ImportRecipesResult summarises an import
+type ImportRecipesResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped []string `json:"skipped"` // reason per skipped recipe
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ImportRecipes creates or updates recipes, matched by external ID.
+// Recipes without an external ID get one derived from the title so re-imports don't duplicate them.
+func (s *RecipeService) ImportRecipes(recipes []ImportRecipeRequest) (*ImportRecipesResult, error) {
+	result := &ImportRecipesResult{}
+
+	for i, req := range recipes {
+		title := strings.TrimSpace(req.Title)
+		if title == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("#%d: title is required", i+1))
+			continue
+		}
+		if len(req.Ingredients) == 0 {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: ingredients are required", title))
+			continue
+		}
+
+		externalID := strings.TrimSpace(req.ExternalID)
+		if externalID == "" {
+			externalID = "import-" + strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+		}
+
+		ingredientsJSON, err := json.Marshal(req.Ingredients)
+		if err != nil {
+			return result, err
+		}
+
+		source := req.Source
+		if source == "" {
+			source = "manual"
+		}
+		servings := req.Servings
+		if servings <= 0 {
+			servings = 1
+		}
+
+		recipe, err := s.recipeRepo.FindByExternalID(externalID)
+		if err != nil {
+			return result, err
+		}
+		isNew := recipe == nil
+		if isNew {
+			recipe = &models.Recipe{}
+		}
+
+		recipe.Title = title
+		recipe.Description = req.Description
+		recipe.ImageURL = req.ImageURL
+		recipe.PrepTime = req.PrepTime
+		recipe.CookTime = req.CookTime
+		recipe.Servings = servings
+		recipe.Difficulty = strings.ToLower(req.Difficulty)
+		recipe.Category = strings.ToLower(req.Category)
+		recipe.Cuisine = req.Cuisine
+		recipe.Ingredients = string(ingredientsJSON)
+		recipe.Instructions = joinInstructions(req.Instructions)
+		recipe.Calories = req.Calories
+		recipe.Protein = req.Protein
+		recipe.Carbs = req.Carbs
+		recipe.Fat = req.Fat
+		recipe.IsHalal = req.IsHalal
+		recipe.IsVegetarian = req.IsVegetarian
+		recipe.IsVegan = req.IsVegan
+		recipe.ExternalID = externalID
+		recipe.Source = source
+		recipe.SourceURL = req.SourceURL
+
+		if isNew {
+			if err := s.recipeRepo.Create(recipe); err != nil {
+				return result, fmt.Errorf("failed to create %s: %w", title, err)
+			}
+			result.Created++
+		} else {
+			if err := s.recipeRepo.Update(recipe); err != nil {
+				return result, fmt.Errorf("failed to update %s: %w", title, err)
+			}
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
@@ -1,91 +1,109 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
 )
 
-const (
-	PointsPerFoodSave   = 10
-	PointsPerJournalLog = 5
-	PointsPerDayStreak  = 20
-)
+// PointsResponse is the user's balance with the points that expire soon
+type PointsResponse struct {
+	*models.UserPoints
+	ExpiringSoon ExpiringPoints `json:"expiring_soon"`
+}
+
+// ExpiringPoints are unspent points that expire within WithinDays
+type ExpiringPoints struct {
+	Points       int        `json:"points"`
+	NextExpiryAt *time.Time `json:"next_expiry_at"`
+	WithinDays   int        `json:"within_days"`
+}
 
 type RewardService struct {
-	rewardRepo *repository.RewardRepository
+	rewardRepo    *repository.RewardRepository
+	pointRuleRepo *repository.PointRuleRepository
+	config        *config.RewardConfig
 }
 
-func NewRewardService(rewardRepo *repository.RewardRepository) *RewardService {
+func NewRewardService(rewardRepo *repository.RewardRepository, pointRuleRepo *repository.PointRuleRepository, cfg *config.RewardConfig) *RewardService {
 	return &RewardService{
-		rewardRepo: rewardRepo,
+		rewardRepo:    rewardRepo,
+		pointRuleRepo: pointRuleRepo,
+		config:        cfg,
 	}
 }
 
-// AddPointsForFoodSave adds points when user saves food
-func (s *RewardService) AddPointsForFoodSave(userID, foodID uuid.UUID) error {
-	points, err := s.rewardRepo.GetOrCreateUserPoints(userID)
-	if err != nil {
-		return err
-	}
-
-	// Add points
-	points.TotalPoints += PointsPerFoodSave
-	points.AvailablePoints += PointsPerFoodSave
+// EarnPoints credits points that expire after the configured number of months
+func (s *RewardService) EarnPoints(userID uuid.UUID, points int, source, description string, referenceID *uuid.UUID, referenceType string) error {
+	return s.earn(userID, &models.PointTransaction{
+		Amount:        points,
+		Source:        source,
+		Description:   description,
+		ReferenceID:   referenceID,
+		ReferenceType: referenceType,
+	})
+}
 
-	if err := s.rewardRepo.UpdatePoints(points.ID, points.AvailablePoints, points.TotalPoints, points.UsedPoints); err != nil {
-		return err
+func (s *RewardService) earn(userID uuid.UUID, transaction *models.PointTransaction) error {
+	if transaction.Amount <= 0 {
+		return nil
 	}
+	return s.rewardRepo.EarnPoints(userID, s.withExpiry(transaction))
+}
 
-	// Create transaction
-	transaction := &models.PointTransaction{
-		UserPointsID:  points.ID,
-		Type:          "earn",
-		Amount:        PointsPerFoodSave,
-		Source:        "food_save",
-		Description:   fmt.Sprintf("Earned %d points for saving food to storage", PointsPerFoodSave),
-		ReferenceID:   &foodID,
-		ReferenceType: "food",
+// withExpiry sets when the points of a new lot expire
+func (s *RewardService) withExpiry(transaction *models.PointTransaction) *models.PointTransaction {
+	if s.config.PointsExpiryMonths > 0 {
+		expiry := time.Now().AddDate(0, s.config.PointsExpiryMonths, 0)
+		transaction.ExpiresAt = &expiry
 	}
+	return transaction
+}
 
-	return s.rewardRepo.CreateTransaction(transaction)
+// AddPointsForFoodSave adds points when user saves food
+func (s *RewardService) AddPointsForFoodSave(userID uuid.UUID, food *models.Food) error {
+	_, err := s.AwardAction(userID, ActionFoodSave, PointsContext{Category: food.Category, ExpiryDate: food.ExpiryDate},
+		fmt.Sprintf("Saved %s to storage", food.Name), &food.ID, "food")
+	return err
 }
 
 // AddPointsForJournalEntry adds points when user logs food journal
 func (s *RewardService) AddPointsForJournalEntry(userID, journalID uuid.UUID) error {
+	_, err := s.AwardAction(userID, ActionJournalEntry, PointsContext{}, "Logged a meal", &journalID, "journal")
+	return err
+}
+
+// GetUserPoints retrieves user points and the points expiring soon
+func (s *RewardService) GetUserPoints(userID uuid.UUID) (*PointsResponse, error) {
 	points, err := s.rewardRepo.GetOrCreateUserPoints(userID)
 	if err != nil {
-		return err
-	}
-
-	// Add points
-	points.TotalPoints += PointsPerJournalLog
-	points.AvailablePoints += PointsPerJournalLog
-
-	if err := s.rewardRepo.UpdatePoints(points.ID, points.AvailablePoints, points.TotalPoints, points.UsedPoints); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Create transaction
-	transaction := &models.PointTransaction{
-		UserPointsID:  points.ID,
-		Type:          "earn",
-		Amount:        PointsPerJournalLog,
-		Source:        "journal_entry",
-		Description:   fmt.Sprintf("Earned %d points for logging meal", PointsPerJournalLog),
-		ReferenceID:   &journalID,
-		ReferenceType: "journal",
+	window := s.config.ExpiringSoonWindow
+	expiring, nextExpiry, err := s.rewardRepo.GetExpiringPoints(points.ID, time.Now().Add(window))
+	if err != nil {
+		return nil, err
 	}
 
-	return s.rewardRepo.CreateTransaction(transaction)
+	return &PointsResponse{
+		UserPoints: points,
+		ExpiringSoon: ExpiringPoints{
+			Points:       expiring,
+			NextExpiryAt: nextExpiry,
+			WithinDays:   int(window.Hours() / 24),
+		},
+	}, nil
 }
 
-// GetUserPoints retrieves user points
-func (s *RewardService) GetUserPoints(userID uuid.UUID) (*models.UserPoints, error) {
-	return s.rewardRepo.GetOrCreateUserPoints(userID)
+// ExpirePoints expires every lot past its expiry date, returns the number of lots and points
+func (s *RewardService) ExpirePoints(ctx context.Context) (int, int, error) {
+	return s.rewardRepo.ExpireLots(ctx, time.Now())
 }
 
 // GetPointHistory retrieves point transaction history
@@ -136,26 +154,10 @@ func (s *RewardService) RedeemVoucher(userID, voucherID uuid.UUID) (*models.Vouc
 		return nil, fmt.Errorf("insufficient points: need %d, have %d", voucher.PointsRequired, points.AvailablePoints)
 	}
 
-	// Deduct points
-	points.AvailablePoints -= voucher.PointsRequired
-	points.UsedPoints += voucher.PointsRequired
-
-	if err := s.rewardRepo.UpdatePoints(points.ID, points.AvailablePoints, points.TotalPoints, points.UsedPoints); err != nil {
-		return nil, err
-	}
-
-	// Create transaction
+	// Deduct points, oldest lots first
 	voucherIDCopy := voucherID
-	transaction := &models.PointTransaction{
-		UserPointsID:  points.ID,
-		Type:          "spend",
-		Amount:        voucher.PointsRequired,
-		Source:        "voucher_redeem",
-		Description:   fmt.Sprintf("Redeemed voucher: %s", voucher.Title),
-		ReferenceID:   &voucherIDCopy,
-		ReferenceType: "voucher",
-	}
-	if err := s.rewardRepo.CreateTransaction(transaction); err != nil {
+	if err := s.rewardRepo.DeductPoints(userID, voucher.PointsRequired, "voucher_redeem",
+		fmt.Sprintf("Redeemed voucher: %s", voucher.Title), &voucherIDCopy, "voucher"); err != nil {
 		return nil, err
 	}
 
@@ -199,3 +201,49 @@ func (s *RewardService) GetActiveRedemptions(userID uuid.UUID) ([]models.Voucher
 func generateRedemptionCode() string {
 	return fmt.Sprintf("MSKS-%s", uuid.New().String()[:8])
 }
+
+// PointsCorrection describes a balance that didn't match the user's transaction history
+type PointsCorrection struct {
+	UserID   uuid.UUID
+	Before   models.UserPoints
+	After    models.UserPoints
+	Repaired bool
+}
+
+// RecomputePoints rebuilds every balance from the point transactions.
+// With dryRun the mismatches are only reported.
+func (s *RewardService) RecomputePoints(dryRun bool) ([]PointsCorrection, error) {
+	allPoints, err := s.rewardRepo.FindAllUserPoints()
+	if err != nil {
+		return nil, err
+	}
+
+	var corrections []PointsCorrection
+	for _, points := range allPoints {
+		totals, err := s.rewardRepo.SumTransactionsByType(points.ID)
+		if err != nil {
+			return corrections, err
+		}
+
+		after := points
+		after.TotalPoints = totals["earn"]
+		after.UsedPoints = totals["spend"]
+		after.AvailablePoints = totals["earn"] - totals["spend"] - totals["expired"]
+		if after.TotalPoints == points.TotalPoints &&
+			after.UsedPoints == points.UsedPoints &&
+			after.AvailablePoints == points.AvailablePoints {
+			continue
+		}
+
+		correction := PointsCorrection{UserID: points.UserID, Before: points, After: after}
+		if !dryRun {
+			if err := s.rewardRepo.UpdatePoints(points.ID, after.AvailablePoints, after.TotalPoints, after.UsedPoints); err != nil {
+				return corrections, err
+			}
+			correction.Repaired = true
+		}
+		corrections = append(corrections, correction)
+	}
+
+	return corrections, nil
+}
@@ -1,23 +1,33 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/prompts"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
 )
 
 type ScanFoodRequest struct {
 	ImageURL    string `json:"image_url"`
 	ImageBase64 string `json:"image_base64"`
 	Location    string `json:"location" binding:"required"`
+	NoCache     bool   `json:"no_cache"` // analyse again instead of reusing the result of the same photo
 }
 
 type ScanFoodResponse struct {
 	Name         string     `json:"name"`
 	Category     string     `json:"category"`
 	ImageURL     string     `json:"image_url"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty"`
 	PurchaseDate time.Time  `json:"purchase_date"`
 	ExpiryDate   *time.Time `json:"expiry_date"`
 	Location     string     `json:"location"`
@@ -27,22 +37,54 @@ type ScanFoodResponse struct {
 	Carbs        *float64   `json:"carbs"`
 	Fat          *float64   `json:"fat"`
 	Confidence   float64    `json:"confidence"`
+	Source       string     `json:"source"`
+	// Prompt template the result came from, e.g. food_scan.v1.id
+	PromptVersion string `json:"prompt_version"`
+	// Send back as scan_id when adding the food, so corrections are learned
+	ScanID       *uuid.UUID `json:"scan_id,omitempty"`
+	AliasApplied bool       `json:"alias_applied"` // name and category come from an earlier correction
+}
+
+// ScanAccuracyRow is a group of scans with its rates, 0-1
+type ScanAccuracyRow struct {
+	repository.ScanAccuracyStat
+	SaveRate           float64 `json:"save_rate"`
+	ModelAccuracy      float64 `json:"model_accuracy"`
+	SuggestionAccuracy float64 `json:"suggestion_accuracy"`
+}
+
+// ScanAccuracyReport shows how often scans were saved without corrections
+type ScanAccuracyReport struct {
+	Query           *AnalyticsQuery             `json:"query"`
+	Summary         ScanAccuracyRow             `json:"summary"`
+	ByPeriod        []ScanAccuracyRow           `json:"by_period"`
+	ByPromptVersion []ScanAccuracyRow           `json:"by_prompt_version"`
+	ByConfidence    []ScanAccuracyRow           `json:"by_confidence"`
+	TopCorrections  []repository.ScanCorrection `json:"top_corrections"`
 }
 
 type ScannerService struct {
 	config        *config.Config
+	userRepo      *repository.UserRepository
+	scanRepo      *repository.ScanRepository
 	geminiService *GeminiService
+	uploadService *UploadService
+	expiryService *ExpiryService
 }
 
-func NewScannerService(cfg *config.Config) *ScannerService {
+func NewScannerService(cfg *config.Config, userRepo *repository.UserRepository, scanRepo *repository.ScanRepository, geminiService *GeminiService, uploadService *UploadService, expiryService *ExpiryService) *ScannerService {
 	return &ScannerService{
 		config:        cfg,
-		geminiService: NewGeminiService(cfg),
+		userRepo:      userRepo,
+		scanRepo:      scanRepo,
+		geminiService: geminiService,
+		uploadService: uploadService,
+		expiryService: expiryService,
 	}
 }
 
 // ScanFood processes image using Gemini AI
-func (s *ScannerService) ScanFood(req *ScanFoodRequest) (*ScanFoodResponse, error) {
+func (s *ScannerService) ScanFood(ctx context.Context, userID uuid.UUID, req *ScanFoodRequest) (*ScanFoodResponse, error) {
 	fmt.Println("🔍 ScanFood called")
 
 	// Validate that either ImageURL or ImageBase64 is provided
@@ -50,50 +92,238 @@ func (s *ScannerService) ScanFood(req *ScanFoodRequest) (*ScanFoodResponse, erro
 		return nil, errors.New("either image_url or image_base64 must be provided")
 	}
 
+	// Names and storage tips come back in the user's language
+	locale := prompts.DefaultLocale
+	if user, err := s.userRepo.FindByID(userID); err == nil {
+		locale = user.Locale
+	}
+
+	if req.NoCache {
+		ctx = WithoutAICache(ctx)
+	}
+
 	var geminiResult *FoodScanResult
 	var err error
 
 	if req.ImageBase64 != "" {
 		fmt.Println("📷 Using base64 image from mobile app")
 		// Use base64 image directly
-		geminiResult, err = s.geminiService.AnalyzeFoodImageBase64(req.ImageBase64)
+		geminiResult, err = s.geminiService.AnalyzeFoodImageBase64(ctx, locale, req.ImageBase64)
 	} else {
 		fmt.Println("🌐 Using image URL")
 		// Use image URL (for web uploads)
-		geminiResult, err = s.geminiService.AnalyzeFoodImage(req.ImageURL)
+		geminiResult, err = s.geminiService.AnalyzeFoodImage(ctx, locale, req.ImageURL)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze food: %w", err)
 	}
 
-	if geminiResult.Confidence < 50.0 {
-		return nil, errors.New("low confidence in food identification, please try again with better image")
+	// Keep the photo of base64 scans so the saved food can show it.
+	// The signed URL is sent back when the food is added and stored as an upload reference.
+	imageURL, thumbnailURL, imageRef := req.ImageURL, "", req.ImageURL
+	if imageURL == "" {
+		upload, err := s.uploadService.UploadBase64(ctx, userID, UploadPurposeScan, req.ImageBase64)
+		if err != nil {
+			log.Printf("⚠️  Failed to store scanned image: %v", err)
+		} else {
+			imageURL, thumbnailURL, imageRef = upload.URL, upload.ThumbnailURL, upload.Ref
+		}
 	}
 
-	// Calculate expiry date from predicted days
-	expiryDate := time.Now().AddDate(0, 0, geminiResult.ExpiryDays)
+	// Rejected scans are recorded too, they count against the accuracy
+	lowConfidence := geminiResult.Confidence < 50.0
 
-	// Use placeholder image URL if base64 was provided
-	imageURL := req.ImageURL
-	if imageURL == "" {
-		imageURL = "data:image/jpeg;base64," + req.ImageBase64[:50] + "..." // Truncated for storage
+	// Apply what the user corrected this food to before
+	name, category := geminiResult.Name, geminiResult.Category
+	var alias *models.ScanAlias
+	if !lowConfidence {
+		alias = s.findAlias(userID, geminiResult.Name)
+		if alias != nil {
+			name, category = alias.Name, alias.Category
+		}
+	}
+
+	record := s.recordScan(userID, imageRef, geminiResult, name, category, alias != nil)
+
+	if lowConfidence {
+		return nil, errors.New("low confidence in food identification, please try again with better image")
 	}
 
+	// The model's guess assumes the usual storage, the shelf-life table adjusts it to the chosen location
+	expiryDate := s.expiryService.Predict(ExpiryInput{
+		Category: category,
+		Location: req.Location,
+		From:     time.Now(),
+		HintDays: geminiResult.ExpiryDays,
+	})
+
 	response := &ScanFoodResponse{
-		Name:         geminiResult.Name,
-		Category:     geminiResult.Category,
-		ImageURL:     imageURL,
-		PurchaseDate: time.Now(),
-		ExpiryDate:   &expiryDate,
-		Location:     req.Location,
-		IsHalal:      &geminiResult.IsHalal,
-		Calories:     &geminiResult.Calories,
-		Protein:      &geminiResult.Protein,
-		Carbs:        &geminiResult.Carbohydrates,
-		Fat:          &geminiResult.Fat,
-		Confidence:   geminiResult.Confidence / 100.0, // Convert to 0-1 scale
+		Name:          name,
+		Category:      category,
+		ImageURL:      imageURL,
+		ThumbnailURL:  thumbnailURL,
+		PurchaseDate:  time.Now(),
+		ExpiryDate:    &expiryDate,
+		Location:      req.Location,
+		IsHalal:       &geminiResult.IsHalal,
+		Calories:      &geminiResult.Calories,
+		Protein:       &geminiResult.Protein,
+		Carbs:         &geminiResult.Carbohydrates,
+		Fat:           &geminiResult.Fat,
+		Confidence:    geminiResult.Confidence / 100.0, // Convert to 0-1 scale
+		Source:        AISourceGemini,
+		PromptVersion: geminiResult.PromptVersion,
+		AliasApplied:  alias != nil,
+	}
+	if record != nil {
+		response.ScanID = &record.ID
 	}
 
 	return response, nil
 }
+
+// findAlias returns the user's alias of a recognised name, or nil
+func (s *ScannerService) findAlias(userID uuid.UUID, recognizedName string) *models.ScanAlias {
+	alias, err := s.scanRepo.FindAlias(userID, aliasKey(recognizedName))
+	if err != nil {
+		log.Printf("⚠️  Failed to load scan alias: %v", err)
+		return nil
+	}
+	if alias != nil {
+		if err := s.scanRepo.IncrementAliasUses(alias.ID); err != nil {
+			log.Printf("⚠️  Failed to count scan alias use: %v", err)
+		}
+	}
+	return alias
+}
+
+// recordScan stores a scan. Failures are logged and return nil, they never fail the scan.
+func (s *ScannerService) recordScan(userID uuid.UUID, imageRef string, result *FoodScanResult, name, category string, aliasApplied bool) *models.ScanRecord {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("⚠️  Failed to encode scan result: %v", err)
+		return nil
+	}
+
+	record := &models.ScanRecord{
+		UserID:             userID,
+		ImageRef:           imageRef,
+		PromptVersion:      result.PromptVersion,
+		RawResult:          string(raw),
+		RecognizedName:     result.Name,
+		RecognizedCategory: result.Category,
+		Confidence:         result.Confidence / 100.0,
+		SuggestedName:      name,
+		SuggestedCategory:  category,
+		AliasApplied:       aliasApplied,
+	}
+	if err := s.scanRepo.CreateRecord(record); err != nil {
+		log.Printf("⚠️  Failed to record scan: %v", err)
+		return nil
+	}
+	return record
+}
+
+// RecordSavedFood stores what a scan was finally saved as and learns from corrections:
+// a name or category the user changed becomes an alias for their later scans, and
+// saving what the model recognised drops an alias that no longer applies.
+func (s *ScannerService) RecordSavedFood(userID, scanID uuid.UUID, food *FoodResponse) error {
+	record, err := s.scanRepo.FindRecord(userID, scanID)
+	if err != nil {
+		return err
+	}
+	if record.SavedAt != nil {
+		// Only the first save of a scan counts
+		return nil
+	}
+
+	now := time.Now()
+	record.FoodID = &food.ID
+	record.SavedName = strings.TrimSpace(food.Name)
+	record.SavedCategory = food.Category
+	record.SavedAt = &now
+	if err := s.scanRepo.UpdateRecord(record); err != nil {
+		return err
+	}
+
+	key := aliasKey(record.RecognizedName)
+	if strings.EqualFold(record.SavedName, strings.TrimSpace(record.RecognizedName)) && strings.EqualFold(record.SavedCategory, record.RecognizedCategory) {
+		return s.scanRepo.DeleteAliasByName(userID, key)
+	}
+	return s.scanRepo.UpsertAlias(&models.ScanAlias{
+		UserID:         userID,
+		RecognizedName: key,
+		Name:           record.SavedName,
+		Category:       record.SavedCategory,
+	})
+}
+
+// GetAliases returns the names the user taught the scanner
+func (s *ScannerService) GetAliases(userID uuid.UUID) ([]models.ScanAlias, error) {
+	return s.scanRepo.FindAliases(userID)
+}
+
+// DeleteAlias stops applying a learned alias
+func (s *ScannerService) DeleteAlias(userID, aliasID uuid.UUID) error {
+	return s.scanRepo.DeleteAlias(userID, aliasID)
+}
+
+// GetAccuracyReport reports how often scans were saved as recognised, for one user or all users (nil)
+func (s *ScannerService) GetAccuracyReport(userID *uuid.UUID, q *AnalyticsQuery) (*ScanAccuracyReport, error) {
+	report := &ScanAccuracyReport{Query: q}
+	var summary []ScanAccuracyRow
+
+	groups := []struct {
+		dest *[]ScanAccuracyRow
+		expr string
+		args []interface{}
+	}{
+		{&summary, "'all'", nil},
+		{&report.ByPeriod, "to_char(date_trunc(?, created_at), 'YYYY-MM-DD')", []interface{}{q.Bucket}},
+		{&report.ByPromptVersion, "COALESCE(NULLIF(prompt_version, ''), 'unknown')", nil},
+		{&report.ByConfidence, `CASE WHEN confidence >= 0.9 THEN '0.9-1.0' WHEN confidence >= 0.7 THEN '0.7-0.9'
+			WHEN confidence >= 0.5 THEN '0.5-0.7' ELSE '0.0-0.5' END`, nil},
+	}
+	for _, group := range groups {
+		stats, err := s.scanRepo.AccuracyBy(group.expr, userID, q.From, q.To, group.args...)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]ScanAccuracyRow, len(stats))
+		for i, stat := range stats {
+			rows[i] = toScanAccuracyRow(stat)
+		}
+		*group.dest = rows
+	}
+	if len(summary) > 0 {
+		report.Summary = summary[0]
+	} else {
+		report.Summary = ScanAccuracyRow{ScanAccuracyStat: repository.ScanAccuracyStat{Group: "all"}}
+	}
+
+	corrections, err := s.scanRepo.TopCorrections(userID, q.From, q.To, 10)
+	if err != nil {
+		return nil, err
+	}
+	report.TopCorrections = corrections
+
+	return report, nil
+}
+
+func toScanAccuracyRow(stat repository.ScanAccuracyStat) ScanAccuracyRow {
+	row := ScanAccuracyRow{ScanAccuracyStat: stat}
+	if stat.Scans > 0 {
+		row.SaveRate = float64(stat.Saved) / float64(stat.Scans)
+	}
+	if stat.Saved > 0 {
+		row.ModelAccuracy = float64(stat.ModelCorrect) / float64(stat.Saved)
+		row.SuggestionAccuracy = float64(stat.SuggestionCorrect) / float64(stat.Saved)
+	}
+	return row
+}
+
+// aliasKey is the lookup key of a recognised name
+func aliasKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+	"github.com/varel183/MakanSikScan/backend/internal/utils"
+)
+
+// JobFunc does the work of a scheduled job and returns a short summary for the run history
+type JobFunc func(ctx context.Context) (string, error)
+
+type scheduledJob struct {
+	name     string
+	spec     string
+	schedule utils.Schedule
+	fn       JobFunc
+}
+
+// Scheduler runs registered jobs on cron-like schedules.
+// Each run is claimed through a lease in Postgres, so with several API instances
+// every scheduled slot of a job runs on exactly one of them.
+type Scheduler struct {
+	jobRepo  *repository.JobRepository
+	instance string
+	leaseTTL time.Duration
+	jobs     []*scheduledJob
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(jobRepo *repository.JobRepository, cfg *config.SchedulerConfig) *Scheduler {
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	leaseTTL := cfg.LeaseTTL
+	if leaseTTL <= 0 {
+		leaseTTL = 10 * time.Minute
+	}
+
+	return &Scheduler{
+		jobRepo:  jobRepo,
+		instance: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		leaseTTL: leaseTTL,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register adds a job. Must be called before Start.
+// Interval schedules (@every) also run once at start-up, as the old tickers did.
+func (s *Scheduler) Register(name, spec string, fn JobFunc) error {
+	schedule, err := utils.ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("schedule %q of job %s never fires", spec, name)
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{name: name, spec: spec, schedule: schedule, fn: fn})
+	return nil
+}
+
+// MustRegister is Register for schedules known at compile time or validated config
+func (s *Scheduler) MustRegister(name, spec string, fn JobFunc) {
+	if err := s.Register(name, spec, fn); err != nil {
+		log.Fatalf("Failed to register job %s: %v", name, err)
+	}
+}
+
+// Start launches one goroutine per job
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+	log.Printf("⏱️  Job scheduler started with %d jobs (instance: %s)", len(s.jobs), s.instance)
+}
+
+// Stop cancels the jobs' context and waits for running jobs to return
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	log.Println("⏱️  Job scheduler stopped")
+}
+
+func (s *Scheduler) loop(job *scheduledJob) {
+	defer s.wg.Done()
+
+	if interval, ok := job.schedule.(utils.IntervalSchedule); ok {
+		s.execute(job, time.Now().Truncate(interval.Interval))
+	}
+
+	for {
+		next := job.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.execute(job, next)
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// execute runs one slot of a job if this instance wins the lease
+func (s *Scheduler) execute(job *scheduledJob, scheduledFor time.Time) {
+	acquired, err := s.jobRepo.AcquireLease(job.name, s.instance, scheduledFor, s.leaseTTL)
+	if err != nil {
+		log.Printf("❌ Job %s: failed to acquire lease: %v", job.name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		if err := s.jobRepo.ReleaseLease(job.name, s.instance); err != nil {
+			log.Printf("⚠️  Job %s: failed to release lease: %v", job.name, err)
+		}
+	}()
+
+	run := &models.JobRun{
+		JobName:      job.name,
+		Instance:     s.instance,
+		ScheduledFor: scheduledFor,
+		StartedAt:    time.Now(),
+		Status:       models.JobRunRunning,
+	}
+	if err := s.jobRepo.CreateRun(run); err != nil {
+		log.Printf("⚠️  Job %s: failed to record run: %v", job.name, err)
+	}
+
+	// The lease runs out after leaseTTL and another instance may take the slot,
+	// so the run is cancelled by then as well as on shutdown
+	ctx, cancel := context.WithTimeout(s.ctx, s.leaseTTL)
+	defer cancel()
+
+	result, err := s.call(ctx, job)
+
+	status, errMessage := models.JobRunSucceeded, ""
+	if err != nil {
+		status, errMessage = models.JobRunFailed, err.Error()
+		log.Printf("❌ Job %s failed after %s: %v", job.name, time.Since(run.StartedAt).Round(time.Millisecond), err)
+	} else if result != "" {
+		log.Printf("⏱️  Job %s: %s", job.name, result)
+	}
+
+	if run.ID != uuid.Nil {
+		if err := s.jobRepo.FinishRun(run.ID, status, result, errMessage); err != nil {
+			log.Printf("⚠️  Job %s: failed to record result: %v", job.name, err)
+		}
+	}
+}
+
+// call runs the job function, turning a panic into a failed run
+func (s *Scheduler) call(ctx context.Context, job *scheduledJob) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.fn(ctx)
+}
@@ -13,17 +13,20 @@ type SupermarketService struct {
 	supermarketRepo *repository.SupermarketRepository
 	transactionRepo *repository.TransactionRepository
 	foodRepo        *repository.FoodRepository
+	expiryService   *ExpiryService
 }
 
 func NewSupermarketService(
 	supermarketRepo *repository.SupermarketRepository,
 	transactionRepo *repository.TransactionRepository,
 	foodRepo *repository.FoodRepository,
+	expiryService *ExpiryService,
 ) *SupermarketService {
 	return &SupermarketService{
 		supermarketRepo: supermarketRepo,
 		transactionRepo: transactionRepo,
 		foodRepo:        foodRepo,
+		expiryService:   expiryService,
 	}
 }
 
@@ -66,8 +69,9 @@ func (s *SupermarketService) ProcessPurchase(userID uuid.UUID, req PurchaseReque
 		return nil, errors.New("supermarket not found")
 	}
 
-	// Create transaction
+	// Create transaction, its ID is known upfront so the food lots can reference it
 	transaction := &models.Transaction{
+		ID:            uuid.New(),
 		UserID:        userID,
 		SupermarketID: req.SupermarketID,
 		Status:        "completed",
@@ -113,17 +117,31 @@ func (s *SupermarketService) ProcessPurchase(userID uuid.UUID, req PurchaseReque
 
 		items = append(items, transactionItem)
 
-		// Add to user's food storage
-		expiryDate := time.Now().AddDate(0, 0, product.ExpiryDays)
+		// Add to user's food storage, the product's shelf life adjusted to the category's usual storage
+		expiryDate := s.expiryService.Predict(ExpiryInput{
+			Category: product.Category,
+			Location: supermarket.Name,
+			From:     time.Now(),
+			HintDays: product.ExpiryDays,
+		})
+		category, _ := models.NormalizeFoodCategory(product.Category)
 		food := &models.Food{
 			UserID:          userID,
 			Name:            product.Name,
-			Category:        product.Category,
+			Category:        category,
 			Quantity:        item.Quantity,
 			InitialQuantity: item.Quantity,
-			Unit:            product.Unit,
+			Unit:            purchasedUnit(product.Unit),
 			Location:        supermarket.Name,
 			ExpiryDate:      &expiryDate,
+			Lots: []models.FoodLot{{
+				UserID:          userID,
+				Quantity:        item.Quantity,
+				InitialQuantity: item.Quantity,
+				ExpiryDate:      &expiryDate,
+				Source:          "transaction",
+				SourceID:        &transaction.ID,
+			}},
 		}
 
 		if err := s.foodRepo.Create(food); err != nil {
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"strings"
+	"time"
+
+	// Register the decoders of the accepted formats
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/google/uuid"
+	"github.com/varel183/MakanSikScan/backend/internal/config"
+	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/repository"
+)
+
+// UploadRefPrefix marks image fields that point at an upload, e.g. "upload://uploads/<user>/<id>.jpg".
+// References are stored instead of URLs because signed URLs expire.
+const UploadRefPrefix = "upload://"
+
+// Upload purposes
+const (
+	UploadPurposeFood   = "food"
+	UploadPurposeAvatar = "avatar"
+	UploadPurposeScan   = "scan"
+)
+
+var uploadPurposes = []string{UploadPurposeFood, UploadPurposeAvatar, UploadPurposeScan}
+
+// uploadExtensions are the accepted content types and the extension they are stored with
+var uploadExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// maxImagePixels rejects images that would take too much memory to decode
+const maxImagePixels = 40_000_000
+
+type UploadResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Ref          string    `json:"ref"` // value to send as image_url or avatar
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	Purpose      string    `json:"purpose"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UploadService struct {
+	uploadRepo *repository.UploadRepository
+	store      BlobStore
+	config     *config.StorageConfig
+}
+
+func NewUploadService(uploadRepo *repository.UploadRepository, store BlobStore, cfg *config.StorageConfig) *UploadService {
+	return &UploadService{
+		uploadRepo: uploadRepo,
+		store:      store,
+		config:     cfg,
+	}
+}
+
+// Upload validates an image, stores it with a JPEG thumbnail and records the upload
+func (s *UploadService) Upload(ctx context.Context, userID uuid.UUID, purpose string, data []byte) (*UploadResponse, error) {
+	if purpose == "" {
+		purpose = UploadPurposeFood
+	}
+	if !containsString(uploadPurposes, purpose) {
+		return nil, fmt.Errorf("invalid purpose, use one of: %s", strings.Join(uploadPurposes, ", "))
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if int64(len(data)) > s.config.MaxUploadSize {
+		return nil, fmt.Errorf("file is too large, the limit is %d KB", s.config.MaxUploadSize/1024)
+	}
+
+	// Trust the bytes, not the file name or the client's content type
+	contentType := http.DetectContentType(data)
+	ext, ok := uploadExtensions[contentType]
+	if !ok {
+		return nil, errors.New("unsupported image type, use JPEG, PNG or GIF")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, errors.New("image dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, thumbnail(img, s.config.ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail: %w", err)
+	}
+
+	upload := &models.Upload{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Purpose:     purpose,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+	upload.Key = fmt.Sprintf("uploads/%s/%s.%s", userID, upload.ID, ext)
+	upload.ThumbnailKey = fmt.Sprintf("uploads/%s/%s_thumb.jpg", userID, upload.ID)
+
+	if err := s.store.Put(ctx, upload.Key, data, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store image: %w", err)
+	}
+	if err := s.store.Put(ctx, upload.ThumbnailKey, thumb.Bytes(), "image/jpeg"); err != nil {
+		s.store.Delete(ctx, upload.Key)
+		return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+
+	if err := s.uploadRepo.Create(upload); err != nil {
+		s.store.Delete(ctx, upload.Key)
+		s.store.Delete(ctx, upload.ThumbnailKey)
+		return nil, err
+	}
+
+	return s.toUploadResponse(upload), nil
+}
+
+// UploadBase64 stores a base64 image, with or without a data URI prefix
+func (s *UploadService) UploadBase64(ctx context.Context, userID uuid.UUID, purpose, encoded string) (*UploadResponse, error) {
+	if i := strings.Index(encoded, ";base64,"); strings.HasPrefix(encoded, "data:") && i >= 0 {
+		encoded = encoded[i+len(";base64,"):]
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("invalid base64 image")
+	}
+	return s.Upload(ctx, userID, purpose, data)
+}
+
+// ResolveURL turns an upload reference into a signed URL, other values are returned unchanged
+func (s *UploadService) ResolveURL(ref string) string {
+	key, ok := uploadKey(ref)
+	if !ok || s == nil {
+		return ref
+	}
+	return s.signedURL(key)
+}
+
+// ResolveThumbnailURL returns the signed thumbnail URL of an upload reference, or "" for other values
+func (s *UploadService) ResolveThumbnailURL(ref string) string {
+	key, ok := uploadKey(ref)
+	if !ok || s == nil {
+		return ""
+	}
+	return s.signedURL(thumbnailKey(key))
+}
+
+// NormalizeImageRef prepares an image URL sent by a client for storage.
+// Signed URLs of our own store become upload references, references must belong
+// to the user, and anything else must be an http(s) URL.
+func (s *UploadService) NormalizeImageRef(userID uuid.UUID, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || s == nil {
+		return value, nil
+	}
+
+	key, ok := uploadKey(value)
+	if !ok {
+		key, ok = s.store.KeyFromURL(value)
+	}
+	if !ok {
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+			return value, nil
+		}
+		return "", errors.New("image must be an uploaded image or an http(s) URL")
+	}
+
+	upload, err := s.uploadRepo.FindByKey(key)
+	if err != nil || upload.UserID != userID {
+		return "", errors.New("upload not found")
+	}
+	return UploadRefPrefix + upload.Key, nil
+}
+
+// DeleteUserFiles removes the stored files of a user's uploads, the records go with the account purge
+func (s *UploadService) DeleteUserFiles(ctx context.Context, userID uuid.UUID) error {
+	uploads, err := s.uploadRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, upload := range uploads {
+		for _, key := range []string{upload.Key, upload.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, ErrBlobNotFound) {
+				return fmt.Errorf("failed to delete %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// signedURL signs a key until the end of the next hour after the TTL,
+// so the same image gets the same URL for a while and clients can cache it
+func (s *UploadService) signedURL(key string) string {
+	expiresAt := time.Now().Add(s.config.SignedURLTTL).Truncate(time.Hour).Add(time.Hour)
+	url, err := s.store.SignedURL(key, expiresAt)
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+func (s *UploadService) toUploadResponse(upload *models.Upload) *UploadResponse {
+	ref := UploadRefPrefix + upload.Key
+	return &UploadResponse{
+		ID:           upload.ID,
+		Ref:          ref,
+		URL:          s.ResolveURL(ref),
+		ThumbnailURL: s.ResolveThumbnailURL(ref),
+		Purpose:      upload.Purpose,
+		ContentType:  upload.ContentType,
+		Size:         upload.Size,
+		Width:        upload.Width,
+		Height:       upload.Height,
+		CreatedAt:    upload.CreatedAt,
+	}
+}
+
+func uploadKey(ref string) (string, bool) {
+	if !strings.HasPrefix(ref, UploadRefPrefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(ref, UploadRefPrefix)
+	return key, key != ""
+}
+
+// thumbnailKey derives the thumbnail key from the key of the original
+func thumbnailKey(key string) string {
+	if i := strings.LastIndex(key, "."); i > strings.LastIndex(key, "/") {
+		key = key[:i]
+	}
+	return key + "_thumb.jpg"
+}
+
+// thumbnail scales an image down so its longest side is at most size pixels,
+// averaging the source pixels behind each thumbnail pixel
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if size <= 0 || (w <= size && h <= size) {
+		size = max(w, h)
+	}
+
+	tw, th := size, size
+	if w > h {
+		th = max(1, h*size/w)
+	} else {
+		tw = max(1, w*size/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := bounds.Min.Y + y*h/th
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*h/th)
+		for x := 0; x < tw; x++ {
+			x0 := bounds.Min.X + x*w/tw
+			x1 := max(x0+1, bounds.Min.X+(x+1)*w/tw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			// JPEG has no alpha, so transparent pixels are blended onto white
+			white := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{uint16(r/n + white), uint16(g/n + white), uint16(b/n + white), 0xffff})
+		}
+	}
+	return dst
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -266,3 +267,13 @@ func (s *VoucherService) toRedemptionResponse(r *models.VoucherRedemption) Redem
 func generateVoucherRedemptionCode() string {
 	return fmt.Sprintf("RDM-%d-%s", time.Now().Unix(), uuid.New().String()[:8])
 }
+
+// ExpireStaleRedemptions marks active redemptions that passed their expiry date as expired
+func (s *VoucherService) ExpireStaleRedemptions(ctx context.Context) (int64, error) {
+	return s.voucherRepo.ExpireStaleRedemptions(ctx, time.Now())
+}
+
+// DeactivateExpiredVouchers turns off vouchers past their ValidUntil
+func (s *VoucherService) DeactivateExpiredVouchers(ctx context.Context) (int64, error) {
+	return s.voucherRepo.DeactivateExpiredVouchers(ctx, time.Now())
+}
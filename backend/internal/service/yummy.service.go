@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/varel183/MakanSikScan/backend/internal/config"
 	"github.com/varel183/MakanSikScan/backend/internal/models"
+	"github.com/varel183/MakanSikScan/backend/internal/prompts"
 	"github.com/varel183/MakanSikScan/backend/internal/repository"
 )
 
@@ -212,18 +214,20 @@ func (s *YummyService) FetchRecipeDetail(slug string) (*YummyRecipeDetailRespons
 }
 
 // TranslateToEnglish translates Indonesian text to English using Gemini
-func (s *YummyService) TranslateToEnglish(text string) (string, error) {
+func (s *YummyService) TranslateToEnglish(ctx context.Context, text string) (string, error) {
 	if text == "" {
 		return "", nil
 	}
 
-	prompt := fmt.Sprintf(`Translate the following Indonesian text to English. Only return the translated text, nothing else:
-
-%s`, text)
+	prompt, err := prompts.Render(prompts.TranslateToEnglish, prompts.LocaleEnglish, map[string]interface{}{"Text": text})
+	if err != nil {
+		return "", err
+	}
 
-	translated, err := s.geminiService.GenerateContent(prompt)
+	translated, err := s.geminiService.GenerateContent(ctx, prompt.Text)
 	if err != nil {
 		// If translation fails, return original text
+		logFallback("translation", "untranslated", err)
 		return text, nil
 	}
 
@@ -231,7 +235,7 @@ func (s *YummyService) TranslateToEnglish(text string) (string, error) {
 }
 
 // ImportRecipeFromYummy imports a recipe from Yummy and translates it to English
-func (s *YummyService) ImportRecipeFromYummy(slug string) (*models.Recipe, error) {
+func (s *YummyService) ImportRecipeFromYummy(ctx context.Context, slug string) (*models.Recipe, error) {
 	// Check if recipe already exists
 	existingRecipe, err := s.recipeRepo.FindByExternalID(slug)
 	if err == nil && existingRecipe != nil {
@@ -247,12 +251,12 @@ func (s *YummyService) ImportRecipeFromYummy(slug string) (*models.Recipe, error
 	data := yummyRecipe.Data
 
 	// Translate title and description
-	translatedTitle, err := s.TranslateToEnglish(data.Title)
+	translatedTitle, err := s.TranslateToEnglish(ctx, data.Title)
 	if err != nil {
 		translatedTitle = data.Title
 	}
 
-	translatedDesc, err := s.TranslateToEnglish(data.Description)
+	translatedDesc, err := s.TranslateToEnglish(ctx, data.Description)
 	if err != nil {
 		translatedDesc = data.Description
 	}
@@ -260,10 +264,10 @@ func (s *YummyService) ImportRecipeFromYummy(slug string) (*models.Recipe, error
 	// Build ingredients JSON
 	ingredientsMap := make(map[string][]string)
 	for _, section := range data.IngredientType {
-		translatedSectionName, _ := s.TranslateToEnglish(section.Name)
+		translatedSectionName, _ := s.TranslateToEnglish(ctx, section.Name)
 		ingredients := make([]string, 0)
 		for _, ing := range section.Ingredients {
-			translatedIng, _ := s.TranslateToEnglish(ing.Description)
+			translatedIng, _ := s.TranslateToEnglish(ctx, ing.Description)
 			ingredients = append(ingredients, translatedIng)
 		}
 		ingredientsMap[translatedSectionName] = ingredients
@@ -277,8 +281,8 @@ func (s *YummyService) ImportRecipeFromYummy(slug string) (*models.Recipe, error
 	// Build instructions
 	var instructionsBuilder strings.Builder
 	for _, step := range data.CookingStep {
-		translatedStepTitle, _ := s.TranslateToEnglish(step.Title)
-		translatedStepText, _ := s.TranslateToEnglish(step.Text)
+		translatedStepTitle, _ := s.TranslateToEnglish(ctx, step.Title)
+		translatedStepText, _ := s.TranslateToEnglish(ctx, step.Text)
 		instructionsBuilder.WriteString(fmt.Sprintf("%s: %s\n\n", translatedStepTitle, translatedStepText))
 	}
 
@@ -336,7 +340,7 @@ func (s *YummyService) ImportRecipeFromYummy(slug string) (*models.Recipe, error
 }
 
 // ImportMultipleRecipes imports multiple recipes from Yummy
-func (s *YummyService) ImportMultipleRecipes(limit int) ([]models.Recipe, error) {
+func (s *YummyService) ImportMultipleRecipes(ctx context.Context, limit int) ([]models.Recipe, error) {
 	// Fetch recipe list
 	recipes, err := s.FetchRecipes(limit)
 	if err != nil {
@@ -351,7 +355,7 @@ func (s *YummyService) ImportMultipleRecipes(limit int) ([]models.Recipe, error)
 			continue
 		}
 
-		recipe, err := s.ImportRecipeFromYummy(slug)
+		recipe, err := s.ImportRecipeFromYummy(ctx, slug)
 		if err != nil {
 			// Log error but continue with next recipe
 			fmt.Printf("Failed to import recipe %s: %v\n", slug, err)
@@ -360,8 +364,12 @@ func (s *YummyService) ImportMultipleRecipes(limit int) ([]models.Recipe, error)
 
 		importedRecipes = append(importedRecipes, *recipe)
 
-		// Add delay to avoid rate limiting
-		time.Sleep(2 * time.Second)
+		// Add delay to avoid rate limiting, stop when the client is gone
+		select {
+		case <-ctx.Done():
+			return importedRecipes, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
 	}
 
 	return importedRecipes, nil
@@ -369,9 +377,9 @@ func (s *YummyService) ImportMultipleRecipes(limit int) ([]models.Recipe, error)
 
 // FetchRecipesWithIngredientMatch fetches recipes and matches them with user's food storage
 // Optimized version that returns top 5 best matches
-func (s *YummyService) FetchRecipesWithIngredientMatch(limit int, userFoodNames []string) ([]map[string]interface{}, error) {
+func (s *YummyService) FetchRecipesWithIngredientMatch(limit int, userFoods []PantryItem) ([]map[string]interface{}, error) {
 	// If user has no food, fetch random recipes
-	if len(userFoodNames) == 0 {
+	if len(userFoods) == 0 {
 		return s.FetchRecipes(5)
 	}
 
@@ -420,9 +428,9 @@ func (s *YummyService) FetchRecipesWithIngredientMatch(limit int, userFoodNames
 		}
 
 		// Match ingredients with user's food
-		matchedCount, totalCount, matchedIngredients := s.ingredientMatcher.MatchIngredientsWithFoods(
+		matchedCount, totalCount, matchedIngredients, insufficientIngredients := s.ingredientMatcher.MatchIngredientsWithFoods(
 			recipeIngredients,
-			userFoodNames,
+			userFoods,
 		)
 
 		matchPercentage := s.ingredientMatcher.CalculateMatchPercentage(matchedCount, totalCount)
@@ -436,6 +444,8 @@ func (s *YummyService) FetchRecipesWithIngredientMatch(limit int, userFoodNames
 		recipeCopy["matched_ingredients_count"] = matchedCount
 		recipeCopy["total_ingredients_count"] = totalCount
 		recipeCopy["matched_ingredients"] = matchedIngredients
+		// In stock, but not enough of it for the recipe
+		recipeCopy["insufficient_ingredients"] = insufficientIngredients
 		recipeCopy["can_make"] = matchPercentage >= 70 // Can make if 70% ingredients available
 
 		scoredRecipes = append(scoredRecipes, ScoredRecipe{
@@ -0,0 +1,94 @@
+package units
+
+import "strings"
+
+// ingredientHint holds what is needed to convert an ingredient between dimensions
+type ingredientHint struct {
+	names        []string
+	density      float64            // grams per millilitre, 0 when unknown
+	pieceWeights map[string]float64 // grams per counted thing, e.g. "clove": 5
+}
+
+// ingredientHints are rough kitchen averages. More specific names come first,
+// so "bawang putih" is found before "bawang".
+var ingredientHints = []ingredientHint{
+	{names: []string{"bawang putih", "garlic"}, pieceWeights: map[string]float64{"clove": 5, "piece": 40}},
+	{names: []string{"bawang merah", "shallot"}, pieceWeights: map[string]float64{"clove": 7, "piece": 7}},
+	{names: []string{"bawang bombay", "bawang bombai", "onion"}, pieceWeights: map[string]float64{"piece": 150}},
+	{names: []string{"daun bawang", "spring onion", "scallion"}, pieceWeights: map[string]float64{"stalk": 15, "bunch": 100}},
+	{names: []string{"cabai rawit", "cabe rawit", "bird's eye chili"}, pieceWeights: map[string]float64{"piece": 1}},
+	{names: []string{"cabai", "cabe", "chili", "chilli"}, pieceWeights: map[string]float64{"piece": 10}},
+	{names: []string{"terong", "terung", "eggplant"}, pieceWeights: map[string]float64{"piece": 250}},
+	{names: []string{"telur puyuh", "quail egg"}, pieceWeights: map[string]float64{"piece": 10}},
+	{names: []string{"telur", "egg"}, pieceWeights: map[string]float64{"piece": 60, "tray": 1800}},
+	{names: []string{"tomat", "tomato"}, pieceWeights: map[string]float64{"piece": 100}},
+	{names: []string{"kentang", "potato"}, pieceWeights: map[string]float64{"piece": 150}},
+	{names: []string{"wortel", "carrot"}, pieceWeights: map[string]float64{"piece": 100}},
+	{names: []string{"timun", "mentimun", "cucumber"}, pieceWeights: map[string]float64{"piece": 200}},
+	{names: []string{"jeruk nipis", "jeruk limau", "lime"}, pieceWeights: map[string]float64{"piece": 40}},
+	{names: []string{"jeruk", "orange"}, pieceWeights: map[string]float64{"piece": 130}},
+	{names: []string{"apel", "apple"}, pieceWeights: map[string]float64{"piece": 180}},
+	{names: []string{"pisang", "banana"}, pieceWeights: map[string]float64{"piece": 120}},
+	{names: []string{"bayam", "spinach"}, pieceWeights: map[string]float64{"bunch": 250}},
+	{names: []string{"kangkung", "water spinach"}, pieceWeights: map[string]float64{"bunch": 250}},
+	{names: []string{"sawi", "caisim", "pakcoy", "bok choy"}, pieceWeights: map[string]float64{"bunch": 250}},
+	{names: []string{"kemangi", "basil"}, pieceWeights: map[string]float64{"bunch": 50}},
+	{names: []string{"seledri", "celery"}, pieceWeights: map[string]float64{"stalk": 10, "bunch": 50}},
+	{names: []string{"serai", "sereh", "lemongrass"}, pieceWeights: map[string]float64{"stalk": 20}},
+	{names: []string{"jahe", "ginger"}, pieceWeights: map[string]float64{"knob": 15}},
+	{names: []string{"lengkuas", "galangal"}, pieceWeights: map[string]float64{"knob": 20}},
+	{names: []string{"kunyit", "turmeric"}, pieceWeights: map[string]float64{"knob": 10}},
+	{names: []string{"tempe", "tempeh"}, pieceWeights: map[string]float64{"slab": 300, "block": 300, "slice": 25}},
+	{names: []string{"tahu", "tofu"}, pieceWeights: map[string]float64{"piece": 80, "block": 300}},
+	{names: []string{"ayam", "chicken"}, pieceWeights: map[string]float64{"whole": 1000, "piece": 150}},
+	{names: []string{"ikan", "fish"}, pieceWeights: map[string]float64{"whole": 300}},
+	{names: []string{"roti", "bread"}, pieceWeights: map[string]float64{"sheet": 30, "slice": 30, "loaf": 400}},
+	{names: []string{"keju", "cheese"}, density: 1.1, pieceWeights: map[string]float64{"sheet": 20, "slice": 20, "block": 170}},
+
+	{names: []string{"air", "water"}, density: 1},
+	{names: []string{"santan", "coconut milk"}, density: 0.97},
+	{names: []string{"susu kental manis", "condensed milk"}, density: 1.3},
+	{names: []string{"susu", "milk"}, density: 1.03},
+	{names: []string{"yogurt", "yoghurt"}, density: 1.03},
+	{names: []string{"minyak", "oil"}, density: 0.92},
+	{names: []string{"mentega", "margarin", "butter"}, density: 0.96},
+	{names: []string{"madu", "honey"}, density: 1.42},
+	{names: []string{"kecap", "soy sauce"}, density: 1.2},
+	{names: []string{"saus", "sambal", "sauce", "ketchup"}, density: 1.1},
+	{names: []string{"gula", "sugar"}, density: 0.85},
+	{names: []string{"garam", "salt"}, density: 1.2},
+	{names: []string{"tepung", "flour", "maizena"}, density: 0.53},
+	{names: []string{"beras", "rice"}, density: 0.85},
+}
+
+// findHint finds the hint for an ingredient name, the zero hint when there is none
+func findHint(ingredient string) ingredientHint {
+	name := " " + strings.Join(strings.Fields(strings.ToLower(ingredient)), " ")
+	for _, hint := range ingredientHints {
+		for _, n := range hint.names {
+			if strings.Contains(name, " "+n) {
+				return hint
+			}
+		}
+	}
+	return ingredientHint{}
+}
+
+// gramsPer returns how many grams one of the unit weighs for this ingredient
+func (h ingredientHint) gramsPer(unit Unit) (float64, bool) {
+	switch unit.Dimension {
+	case Mass:
+		return unit.Size, true
+	case Volume:
+		if h.density == 0 {
+			return 0, false
+		}
+		return unit.Size * h.density, true
+	default:
+		weight, ok := h.pieceWeights[unit.Counts]
+		if !ok {
+			return 0, false
+		}
+		return unit.Size * weight, true
+	}
+}
@@ -0,0 +1,126 @@
+package units
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Quantity is an amount of an ingredient, e.g. parsed from "2 sdm kecap manis"
+type Quantity struct {
+	Amount float64
+	Unit   string // canonical unit name
+	Name   string // ingredient without the amount, e.g. "kecap manis"
+}
+
+var unicodeFractions = map[rune]float64{'½': 0.5, '¼': 0.25, '¾': 0.75, '⅓': 1.0 / 3, '⅔': 2.0 / 3}
+
+// ParseQuantity splits an ingredient line such as "1 1/2 sdm gula pasir" or "3 siung bawang putih, cincang"
+// into amount, unit and name. A line without a unit counts pieces, e.g. "2 telur".
+// ok is false when the line does not start with an amount, e.g. "garam secukupnya".
+func ParseQuantity(text string) (Quantity, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return Quantity{}, false
+	}
+
+	amount, rest, ok := parseNumber(fields[0])
+	if !ok {
+		return Quantity{}, false
+	}
+	fields = fields[1:]
+	if rest != "" {
+		// Amount and unit written together, e.g. "500gr"
+		fields = append([]string{rest}, fields...)
+	} else if len(fields) > 0 && strings.ContainsAny(fields[0], "/½¼¾⅓⅔") {
+		// Mixed number, e.g. "1 1/2"
+		if fraction, rest, ok := parseNumber(fields[0]); ok && rest == "" {
+			amount += fraction
+			fields = fields[1:]
+		}
+	}
+
+	quantity := Quantity{Amount: amount, Unit: "pcs"}
+	for words := 2; words >= 1; words-- {
+		if len(fields) < words {
+			continue
+		}
+		name := strings.TrimRight(strings.Join(fields[:words], " "), ".,")
+		if unit, ok := Lookup(name); ok {
+			quantity.Unit = unit.Name
+			fields = fields[words:]
+			break
+		}
+	}
+
+	// Drop notes in brackets and preparation after a comma, e.g. "(1 gelas) santan, hangat"
+	name := strings.Join(fields, " ")
+	for {
+		start, end := strings.Index(name, "("), strings.Index(name, ")")
+		if start < 0 || end < start {
+			break
+		}
+		name = name[:start] + name[end+1:]
+	}
+	if i := strings.IndexAny(name, ",("); i >= 0 {
+		name = name[:i]
+	}
+	quantity.Name = strings.Join(strings.Fields(strings.Trim(name, " -.")), " ")
+	return quantity, true
+}
+
+// parseNumber reads the amount at the start of a token and returns what follows it.
+// It accepts "2", "1,5", "1.000", "1/2", "½" and ranges such as "2-3", which count as their lower end.
+func parseNumber(token string) (float64, string, bool) {
+	end := 0
+	for end < len(token) && strings.IndexByte("0123456789.,/-", token[end]) >= 0 {
+		end++
+	}
+	number, rest := token[:end], token[end:]
+	if i := strings.Index(number, "-"); i >= 0 {
+		number = number[:i]
+	}
+
+	value, parsed := 0.0, false
+	if number != "" {
+		if numerator, denominator, isFraction := strings.Cut(number, "/"); isFraction {
+			n, err := strconv.ParseFloat(numerator, 64)
+			d, err2 := strconv.ParseFloat(denominator, 64)
+			if err != nil || err2 != nil || d == 0 {
+				return 0, "", false
+			}
+			value, parsed = n/d, true
+		} else {
+			if isThousands(number) {
+				number = strings.ReplaceAll(number, ".", "")
+			}
+			n, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+			if err != nil {
+				return 0, "", false
+			}
+			value, parsed = n, true
+		}
+	}
+
+	if r, size := utf8.DecodeRuneInString(rest); r != utf8.RuneError {
+		if fraction, ok := unicodeFractions[r]; ok {
+			value, parsed = value+fraction, true
+			rest = rest[size:]
+		}
+	}
+	return value, rest, parsed
+}
+
+// isThousands reports whether dots in a number group thousands, as in Indonesian "1.000"
+func isThousands(number string) bool {
+	groups := strings.Split(number, ".")
+	if len(groups) < 2 || groups[0] == "" || groups[0] == "0" || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
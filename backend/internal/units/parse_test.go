@@ -0,0 +1,115 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   Quantity
+		wantOK bool
+	}{
+		{text: "1.000 g tepung terigu", want: Quantity{1000, "g", "tepung terigu"}, wantOK: true},
+		{text: "1,5 kg daging sapi", want: Quantity{1.5, "kg", "daging sapi"}, wantOK: true},
+		{text: "1 1/2 sdm gula pasir", want: Quantity{1.5, "sdm", "gula pasir"}, wantOK: true},
+		{text: "½ gelas santan", want: Quantity{0.5, "gelas", "santan"}, wantOK: true},
+		{text: "1½ sdt garam", want: Quantity{1.5, "sdt", "garam"}, wantOK: true},
+		{text: "2-3 siung bawang putih", want: Quantity{2, "siung", "bawang putih"}, wantOK: true},
+		{text: "500gr ayam", want: Quantity{500, "g", "ayam"}, wantOK: true},
+		{text: "2 sendok makan kecap manis", want: Quantity{2, "sdm", "kecap manis"}, wantOK: true},
+		{text: "2 telur", want: Quantity{2, "pcs", "telur"}, wantOK: true},
+		{text: "3 siung bawang putih, cincang", want: Quantity{3, "siung", "bawang putih"}, wantOK: true},
+		{text: "200 ml (1 gelas) santan, hangat", want: Quantity{200, "ml", "santan"}, wantOK: true},
+		{text: "1 ikat. kangkung", want: Quantity{1, "ikat", "kangkung"}, wantOK: true},
+
+		{text: "garam secukupnya", wantOK: false},
+		{text: "", wantOK: false},
+		{text: "1/0 sdm gula", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := ParseQuantity(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseQuantity(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(got.Amount-tt.want.Amount) > 1e-9 || got.Unit != tt.want.Unit || got.Name != tt.want.Name {
+				t.Errorf("ParseQuantity(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		token    string
+		want     float64
+		wantRest string
+		wantOK   bool
+	}{
+		{token: "2", want: 2, wantOK: true},
+		{token: "1,5", want: 1.5, wantOK: true},
+		{token: "1.5", want: 1.5, wantOK: true},
+		{token: "1.000", want: 1000, wantOK: true},
+		{token: "1.000.000", want: 1000000, wantOK: true},
+		{token: "0.250", want: 0.25, wantOK: true},
+		{token: "1/2", want: 0.5, wantOK: true},
+		{token: "½", want: 0.5, wantOK: true},
+		{token: "1½", want: 1.5, wantOK: true},
+		{token: "2-3", want: 2, wantOK: true},
+		{token: "500gr", want: 500, wantRest: "gr", wantOK: true},
+		{token: "¼kg", want: 0.25, wantRest: "kg", wantOK: true},
+
+		{token: "", wantOK: false},
+		{token: "garam", wantOK: false},
+		{token: "-", wantOK: false},
+		{token: "1/0", wantOK: false},
+		{token: "1/x", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			got, rest, ok := parseNumber(tt.token)
+			if ok != tt.wantOK {
+				t.Fatalf("parseNumber(%q) ok = %v, want %v", tt.token, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 || rest != tt.wantRest {
+				t.Errorf("parseNumber(%q) = %v, %q, want %v, %q", tt.token, got, rest, tt.want, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestIsThousands(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"1.000", true},
+		{"12.500", true},
+		{"250.000", true},
+		{"1.000.000", true},
+		{"1000", false},
+		{"1.5", false},
+		{"1.50", false},
+		{"1.0000", false},
+		{"0.250", false},
+		{"1234.567", false},
+		{".500", false},
+		{"1.000.00", false},
+	}
+
+	for _, tt := range tests {
+		if got := isThousands(tt.number); got != tt.want {
+			t.Errorf("isThousands(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
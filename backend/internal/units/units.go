@@ -0,0 +1,129 @@
+// Package units converts food quantities between units of measure, including
+// Indonesian kitchen units such as sendok makan, gelas, siung and ikat.
+package units
+
+import (
+	"errors"
+	"strings"
+)
+
+// Dimension is what a unit measures
+type Dimension string
+
+const (
+	Mass   Dimension = "mass"   // in grams
+	Volume Dimension = "volume" // in millilitres
+	Count  Dimension = "count"  // in pieces of something
+)
+
+var (
+	ErrUnknownUnit  = errors.New("unsupported unit, use e.g. g, kg, ml, l, pcs, sdm, sdt, gelas, siung or ikat")
+	ErrIncompatible = errors.New("units cannot be converted for this ingredient")
+)
+
+// Unit is a unit of measure and its size in the base unit of its dimension
+type Unit struct {
+	Name      string // canonical name, stored on foods and cart items
+	Dimension Dimension
+	Size      float64 // grams, millilitres or pieces
+	Counts    string  // what a count unit counts, e.g. "clove"; only the same things convert
+}
+
+// unitDefs lists every unit with the names it is written as, canonical name first
+var unitDefs = []struct {
+	unit    Unit
+	aliases []string
+}{
+	// Mass
+	{Unit{"mg", Mass, 0.001, ""}, []string{"milligram", "milligrams", "miligram"}},
+	{Unit{"g", Mass, 1, ""}, []string{"gr", "gram", "grams", "gramm"}},
+	{Unit{"ons", Mass, 100, ""}, nil},
+	{Unit{"kg", Mass, 1000, ""}, []string{"kilo", "kilogram", "kilograms", "kilos"}},
+	{Unit{"oz", Mass, 28.35, ""}, []string{"ounce", "ounces"}},
+	{Unit{"lb", Mass, 453.6, ""}, []string{"lbs", "pound", "pounds"}},
+
+	// Volume
+	{Unit{"ml", Volume, 1, ""}, []string{"mililiter", "milliliter", "milliliters", "millilitre", "cc"}},
+	{Unit{"l", Volume, 1000, ""}, []string{"liter", "liters", "litre", "litres", "ltr"}},
+	{Unit{"sdt", Volume, 5, ""}, []string{"sendok teh", "tsp", "teaspoon", "teaspoons"}},
+	{Unit{"sdm", Volume, 15, ""}, []string{"sendok makan", "tbsp", "tablespoon", "tablespoons"}},
+	{Unit{"cup", Volume, 240, ""}, []string{"cups", "cangkir"}},
+	{Unit{"gelas", Volume, 250, ""}, []string{"glass", "glasses"}},
+
+	// Count, generic pieces convert to each other
+	{Unit{"pcs", Count, 1, "piece"}, []string{"pc", "piece", "pieces", "buah", "biji", "butir", "item", "items"}},
+	{Unit{"lusin", Count, 12, "piece"}, []string{"dozen"}},
+
+	// Count of specific things
+	{Unit{"siung", Count, 1, "clove"}, []string{"clove", "cloves"}},
+	{Unit{"ikat", Count, 1, "bunch"}, []string{"bunch", "bunches"}},
+	{Unit{"batang", Count, 1, "stalk"}, []string{"stalk", "stalks", "stick", "sticks"}},
+	{Unit{"lembar", Count, 1, "sheet"}, []string{"sheet", "sheets", "leaf", "leaves"}},
+	{Unit{"slice", Count, 1, "slice"}, []string{"slices", "iris", "potong"}},
+	{Unit{"ruas", Count, 1, "knob"}, []string{"knob", "knobs"}},
+	{Unit{"ekor", Count, 1, "whole"}, []string{"whole"}},
+	{Unit{"papan", Count, 1, "slab"}, []string{"slab"}},
+	{Unit{"portion", Count, 1, "portion"}, []string{"portions", "porsi", "serving", "servings"}},
+	{Unit{"pack", Count, 1, "pack"}, []string{"packs", "packet", "packets", "bungkus", "sachet", "sachets"}},
+	{Unit{"box", Count, 1, "box"}, []string{"boxes", "kotak", "dus"}},
+	{Unit{"bottle", Count, 1, "bottle"}, []string{"bottles", "botol"}},
+	{Unit{"can", Count, 1, "can"}, []string{"cans", "kaleng"}},
+	{Unit{"jar", Count, 1, "jar"}, []string{"jars", "toples"}},
+	{Unit{"loaf", Count, 1, "loaf"}, []string{"loaves"}},
+	{Unit{"block", Count, 1, "block"}, []string{"blocks", "balok"}},
+	{Unit{"tray", Count, 1, "tray"}, []string{"trays", "rak"}},
+}
+
+var unitsByName = func() map[string]Unit {
+	byName := make(map[string]Unit)
+	for _, def := range unitDefs {
+		byName[def.unit.Name] = def.unit
+		for _, alias := range def.aliases {
+			byName[alias] = def.unit
+		}
+	}
+	return byName
+}()
+
+// Lookup finds a unit by any of its names, case-insensitively
+func Lookup(name string) (Unit, bool) {
+	unit, ok := unitsByName[strings.Join(strings.Fields(strings.ToLower(name)), " ")]
+	return unit, ok
+}
+
+// Normalize returns the canonical name of a unit, e.g. "sendok makan" is "sdm"
+func Normalize(name string) (string, error) {
+	unit, ok := Lookup(name)
+	if !ok {
+		return "", ErrUnknownUnit
+	}
+	return unit.Name, nil
+}
+
+// Convert converts a quantity between units. Units of the same dimension convert directly,
+// others through the ingredient's density or piece weight, e.g. 3 siung bawang putih is 15 g.
+func Convert(quantity float64, from, to, ingredient string) (float64, error) {
+	fromUnit, ok := Lookup(from)
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+	toUnit, ok := Lookup(to)
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+
+	if fromUnit.Dimension == toUnit.Dimension && fromUnit.Counts == toUnit.Counts {
+		return quantity * fromUnit.Size / toUnit.Size, nil
+	}
+
+	hint := findHint(ingredient)
+	fromGrams, ok := hint.gramsPer(fromUnit)
+	if !ok {
+		return 0, ErrIncompatible
+	}
+	toGrams, ok := hint.gramsPer(toUnit)
+	if !ok {
+		return 0, ErrIncompatible
+	}
+	return quantity * fromGrams / toGrams, nil
+}
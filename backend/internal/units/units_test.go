@@ -0,0 +1,51 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name       string
+		quantity   float64
+		from, to   string
+		ingredient string
+		want       float64
+		wantErr    error
+	}{
+		{name: "mass", quantity: 1.5, from: "kg", to: "g", want: 1500},
+		{name: "aliases", quantity: 2, from: "sendok makan", to: "sendok teh", want: 6},
+		{name: "generic pieces", quantity: 2, from: "lusin", to: "butir", ingredient: "telur", want: 24},
+		{name: "eggs by weight", quantity: 1.2, from: "kg", to: "pcs", ingredient: "telur ayam", want: 20},
+		{name: "cloves to grams", quantity: 3, from: "siung", to: "g", ingredient: "bawang putih", want: 15},
+		{name: "cloves to bulbs", quantity: 2, from: "siung", to: "pcs", ingredient: "bawang putih", want: 0.25},
+		{name: "volume by density", quantity: 2, from: "sdm", to: "g", ingredient: "gula pasir", want: 25.5},
+		{name: "quail eggs are not eggs", quantity: 1, from: "pcs", to: "g", ingredient: "telur puyuh", want: 10},
+
+		{name: "unknown from unit", quantity: 1, from: "bakul", to: "g", wantErr: ErrUnknownUnit},
+		{name: "unknown to unit", quantity: 1, from: "g", to: "bakul", wantErr: ErrUnknownUnit},
+		{name: "no density", quantity: 1, from: "kg", to: "ml", ingredient: "ayam", wantErr: ErrIncompatible},
+		{name: "unknown ingredient", quantity: 1, from: "pcs", to: "g", ingredient: "durian", wantErr: ErrIncompatible},
+		{name: "thing the ingredient isn't counted in", quantity: 1, from: "ikat", to: "g", ingredient: "telur", wantErr: ErrIncompatible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.quantity, tt.from, tt.to, tt.ingredient)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Convert(%v %s to %s) error = %v, want %v", tt.quantity, tt.from, tt.to, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert(%v %s to %s) returned error: %v", tt.quantity, tt.from, tt.to, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Convert(%v %s to %s) = %v, want %v", tt.quantity, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
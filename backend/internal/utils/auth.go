@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,10 +23,28 @@ func ComparePassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
-// GenerateJWT generates a new JWT token
-func GenerateJWT(userID uuid.UUID, secret string, expiration time.Duration) (string, error) {
+// JWTClaims holds the identity carried by an access token
+type JWTClaims struct {
+	UserID    uuid.UUID
+	SessionID uuid.UUID // uuid.Nil for tokens issued before sessions existed
+}
+
+// SessionChecker returns an error when the session behind a token is no longer valid
+type SessionChecker func(sessionID uuid.UUID) error
+
+var sessionChecker SessionChecker
+
+// SetSessionChecker registers the lookup ParseJWT uses to reject revoked sessions
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// GenerateJWT generates a new JWT token bound to a session
+func GenerateJWT(userID, sessionID uuid.UUID, secret string, expiration time.Duration) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID.String(),
+		"sid":     sessionID.String(),
+		"jti":     uuid.New().String(),
 		"exp":     time.Now().Add(expiration).Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -33,18 +55,69 @@ func GenerateJWT(userID uuid.UUID, secret string, expiration time.Duration) (str
 
 // ParseJWT parses and validates JWT token
 func ParseJWT(tokenString, secret string) (uuid.UUID, error) {
+	claims, err := ParseJWTClaims(tokenString, secret)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// ParseJWTClaims parses and validates JWT token, including its session
+func ParseJWTClaims(tokenString, secret string) (*JWTClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userIDStr := claims["user_id"].(string)
-		return uuid.Parse(userIDStr)
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
 	}
 
-	return uuid.Nil, jwt.ErrSignatureInvalid
+	userIDStr, _ := mapClaims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	claims := &JWTClaims{UserID: userID}
+
+	// Tokens without a session ID predate sessions and stay valid until they expire
+	if sid, ok := mapClaims["sid"].(string); ok {
+		sessionID, err := uuid.Parse(sid)
+		if err != nil {
+			return nil, err
+		}
+		if sessionChecker != nil {
+			if err := sessionChecker(sessionID); err != nil {
+				return nil, err
+			}
+		}
+		claims.SessionID = sessionID
+	}
+
+	return claims, nil
+}
+
+// GenerateRefreshToken returns a random opaque token and the hash to store for it
+func GenerateRefreshToken() (token, hash string, err error) {
+	return GenerateSecureToken()
+}
+
+// GenerateSecureToken returns a random URL-safe token and the hash to store for it
+func GenerateSecureToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes an opaque token for storage and lookup
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		min, max int
+		want     []int
+		wantErr  bool
+	}{
+		{name: "wildcard", field: "*", min: 1, max: 5, want: []int{1, 2, 3, 4, 5}},
+		{name: "single value", field: "7", min: 0, max: 23, want: []int{7}},
+		{name: "step over all", field: "*/15", min: 0, max: 59, want: []int{0, 15, 30, 45}},
+		{name: "range", field: "9-12", min: 0, max: 23, want: []int{9, 10, 11, 12}},
+		{name: "range with step", field: "1-10/3", min: 1, max: 31, want: []int{1, 4, 7, 10}},
+		{name: "start with step runs to the end", field: "5/20", min: 0, max: 59, want: []int{5, 25, 45}},
+		{name: "list", field: "1,15,30", min: 1, max: 31, want: []int{1, 15, 30}},
+		{name: "list of ranges and values", field: "1-3,10,20-21", min: 1, max: 31, want: []int{1, 2, 3, 10, 20, 21}},
+		{name: "duplicates in list", field: "2,2,1-2", min: 0, max: 6, want: []int{1, 2}},
+		{name: "7 is Sunday", field: "7", min: 0, max: 6, want: []int{0}},
+		{name: "range up to 7 includes Sunday", field: "5-7", min: 0, max: 6, want: []int{0, 5, 6}},
+
+		{name: "empty", field: "", min: 0, max: 59, wantErr: true},
+		{name: "not a number", field: "abc", min: 0, max: 59, wantErr: true},
+		{name: "below min", field: "0", min: 1, max: 31, wantErr: true},
+		{name: "above max", field: "60", min: 0, max: 59, wantErr: true},
+		{name: "reversed range", field: "10-5", min: 0, max: 59, wantErr: true},
+		{name: "zero step", field: "*/0", min: 0, max: 59, wantErr: true},
+		{name: "negative step", field: "*/-2", min: 0, max: 59, wantErr: true},
+		{name: "missing step", field: "*/", min: 0, max: 59, wantErr: true},
+		{name: "open range", field: "5-", min: 0, max: 59, wantErr: true},
+		{name: "empty list item", field: "1,,2", min: 0, max: 59, wantErr: true},
+		{name: "8 is not a weekday", field: "8", min: 0, max: 6, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := parseCronField(tt.field, tt.min, tt.max)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseCronField(%q) = %v, want error", tt.field, set)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCronField(%q) returned error: %v", tt.field, err)
+			}
+
+			got := make([]int, 0, len(set))
+			for v := range set {
+				got = append(got, v)
+			}
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"61 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"@every",
+		"@every soon",
+		"@every 500ms",
+		"@yearly",
+	}
+
+	for _, spec := range specs {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2024, time.January, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 10, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, time.January, 11, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, time.January, 10, 13, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1,5", time.Date(2024, time.January, 12, 8, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches, the 15th or the next Friday
+		{"0 0 15 * 5", time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC)},
+		{"@every 10m", time.Date(2024, time.January, 10, 10, 10, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) returned error: %v", tt.spec, err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextNeverFires(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseSchedule returned error: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next = %s, want zero time for February 30th", next)
+	}
+}